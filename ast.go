@@ -0,0 +1,1718 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Position ubica un punto del código fuente (línea y columna empiezan en 1,
+// el offset es el índice de byte dentro del código).
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+	Offset int `json:"offset"`
+}
+
+// Range delimita el fragmento de código que cubre un nodo.
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Node es la interfaz común de todos los nodos del AST.
+type Node interface {
+	Kind() string
+	Span() Range
+	Children() []Node
+}
+
+type Statement interface {
+	Node
+	statementNode()
+}
+
+type Expression interface {
+	Node
+	expressionNode()
+}
+
+type baseNode struct {
+	Loc Range
+}
+
+func (n *baseNode) Span() Range {
+	return n.Loc
+}
+
+// ---------------------------------------------------------------------------
+// Sentencias
+// ---------------------------------------------------------------------------
+
+type Program struct {
+	baseNode
+	Body []Statement
+}
+
+type VariableDeclaration struct {
+	baseNode
+	Keyword      string // let, const, var o un tipo estilo C (int, number...)
+	Declarations []*VariableDeclarator
+}
+
+// VariableDeclarator es 'Name: TypeAnnotation = Init'. Si declara varias
+// variables por desestructuración, Name es nil y Pattern las describe.
+type VariableDeclarator struct {
+	baseNode
+	Name           *Identifier
+	Pattern        Pattern // '{ a, b }' o '[a, b]'
+	TypeAnnotation TypeNode
+	Init           Expression
+}
+
+// displayName es el nombre de la variable en los mensajes: su identificador o
+// el patrón que desestructura el valor.
+func (d *VariableDeclarator) displayName() string {
+	if d.Name != nil {
+		return d.Name.Name
+	}
+	return patternString(d.Pattern)
+}
+
+// ForStatement es 'for (Init; Test; Update)'; cualquiera de las tres
+// cláusulas puede faltar.
+type ForStatement struct {
+	baseNode
+	Init   Node // *VariableDeclaration o Expression
+	Test   Expression
+	Update Expression
+	Body   Statement
+}
+
+// ForInStatement es 'for (Left in Right)', que recorre los nombres de las
+// propiedades de un objeto. Left es una *VariableDeclaration con un solo
+// declarador sin valor o el destino de una asignación.
+type ForInStatement struct {
+	baseNode
+	Left  Node
+	Right Expression
+	Body  Statement
+}
+
+// ForOfStatement es 'for (Left of Right)', que recorre los valores de un
+// array o de un string. Left tiene la misma forma que en ForInStatement.
+type ForOfStatement struct {
+	baseNode
+	Left  Node
+	Right Expression
+	Body  Statement
+}
+
+type DoWhileStatement struct {
+	baseNode
+	Body Statement
+	Test Expression
+}
+
+type WhileStatement struct {
+	baseNode
+	Test Expression
+	Body Statement
+}
+
+// IfStatement es 'if (Test) Consequent else Alternate'; Alternate es nil si
+// no hay 'else' y otro IfStatement en un 'else if'.
+type IfStatement struct {
+	baseNode
+	Test       Expression
+	Consequent Statement
+	Alternate  Statement
+}
+
+type SwitchStatement struct {
+	baseNode
+	Discriminant Expression
+	Cases        []*SwitchCase
+}
+
+// SwitchCase es un 'case Test:' o, con Test nil, el 'default:'
+type SwitchCase struct {
+	baseNode
+	Test       Expression
+	Consequent []Statement
+}
+
+type BreakStatement struct {
+	baseNode
+}
+
+type ContinueStatement struct {
+	baseNode
+}
+
+type ReturnStatement struct {
+	baseNode
+	Argument Expression // nil en un 'return' sin valor
+}
+
+// FunctionDeclaration es 'function Name(Params): ReturnType { Body }'
+type FunctionDeclaration struct {
+	baseNode
+	Name       *Identifier
+	TypeParams []*TypeParameter
+	Params     []*Parameter
+	ReturnType TypeNode
+	Body       *BlockStatement
+}
+
+// InterfaceDeclaration es 'interface Name<T> extends A, B { Members }'
+type InterfaceDeclaration struct {
+	baseNode
+	Name       *Identifier
+	TypeParams []*TypeParameter
+	Extends    []*TypeReference
+	Members    []*PropertySignature
+}
+
+// TypeAliasDeclaration es 'type Name<T> = Type'
+type TypeAliasDeclaration struct {
+	baseNode
+	Name       *Identifier
+	TypeParams []*TypeParameter
+	Type       TypeNode
+}
+
+// ClassDeclaration es 'class Name<T> extends SuperClass<U> implements I, J { Members }'
+type ClassDeclaration struct {
+	baseNode
+	Name               *Identifier
+	TypeParams         []*TypeParameter
+	SuperClass         *Identifier // nil si no extiende otra clase
+	SuperTypeArguments []TypeNode
+	Implements         []*TypeReference
+	Members            []ClassMember
+}
+
+// ClassMember es un miembro del cuerpo de una clase: *PropertyDefinition o
+// *MethodDefinition.
+type ClassMember interface {
+	Node
+	classMember()
+}
+
+// PropertyDefinition es un campo '[modificadores] Name?: TypeAnnotation = Value'
+type PropertyDefinition struct {
+	baseNode
+	Name           *Identifier
+	Access         string // "public", "private", "protected" o "" si no se indica
+	Static         bool
+	Readonly       bool
+	Optional       bool
+	TypeAnnotation TypeNode
+	Value          Expression
+}
+
+// MethodDefinition es un método o el constructor de una clase. La función no
+// lleva nombre: el del método está en Name.
+type MethodDefinition struct {
+	baseNode
+	Name     *Identifier
+	Access   string
+	Static   bool
+	Function *FunctionExpression
+}
+
+// isConstructor indica si el método es el constructor de la clase
+func (m *MethodDefinition) isConstructor() bool {
+	return !m.Static && m.Name != nil && m.Name.Name == "constructor"
+}
+
+// EnumDeclaration es '[const] enum Name { Members }'
+type EnumDeclaration struct {
+	baseNode
+	Name    *Identifier
+	Const   bool
+	Members []*EnumMember
+}
+
+// EnumMember es 'Name' o 'Name = Initializer' dentro de un enum
+type EnumMember struct {
+	baseNode
+	Name        *Identifier
+	Initializer Expression // nil si el valor se autoincrementa
+}
+
+// ModuleDeclaration es 'namespace Name { Body }' o 'module Name { Body }'.
+// 'namespace A.B { }' se representa como dos declaraciones anidadas.
+type ModuleDeclaration struct {
+	baseNode
+	Keyword string // "namespace" o "module"
+	Name    *Identifier
+	Body    *BlockStatement
+}
+
+// ExportDeclaration es 'export' delante de una declaración
+type ExportDeclaration struct {
+	baseNode
+	Declaration Statement
+}
+
+type BlockStatement struct {
+	baseNode
+	Body []Statement
+}
+
+type ExpressionStatement struct {
+	baseNode
+	Expression Expression
+}
+
+// Parameter es un parámetro de función: 'Name?: tipo = Default' o '...Name'.
+// En un constructor, 'private x: T' declara además la propiedad 'x'. Un
+// parámetro desestructurado no tiene Name sino Pattern.
+type Parameter struct {
+	baseNode
+	Name           *Identifier
+	Pattern        Pattern
+	TypeAnnotation TypeNode
+	Optional       bool
+	Rest           bool
+	Default        Expression
+	Access         string
+	Readonly       bool
+}
+
+// isProperty indica si el parámetro del constructor declara una propiedad
+func (p *Parameter) isProperty() bool {
+	return p.Access != "" || p.Readonly
+}
+
+// displayName es el nombre del parámetro en los mensajes: su identificador o
+// el patrón que lo desestructura.
+func (p *Parameter) displayName() string {
+	if p.Name != nil {
+		return p.Name.Name
+	}
+	return patternString(p.Pattern)
+}
+
+// ---------------------------------------------------------------------------
+// Expresiones
+// ---------------------------------------------------------------------------
+
+type Identifier struct {
+	baseNode
+	Name string
+}
+
+type NumericLiteral struct {
+	baseNode
+	Raw string
+}
+
+type StringLiteral struct {
+	baseNode
+	Raw string
+}
+
+// TemplateLiteral es un `texto ${expr} texto`: Quasis tiene siempre un
+// tramo de texto más que Expressions y se intercalan empezando por el texto.
+type TemplateLiteral struct {
+	baseNode
+	Quasis      []string
+	Expressions []Expression
+}
+
+type BooleanLiteral struct {
+	baseNode
+	Value bool
+}
+
+type BinaryExpression struct {
+	baseNode
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+type LogicalExpression struct {
+	baseNode
+	Operator string
+	Left     Expression
+	Right    Expression
+}
+
+type UnaryExpression struct {
+	baseNode
+	Operator string
+	Argument Expression
+}
+
+type AssignmentExpression struct {
+	baseNode
+	Operator string
+	Target   Expression
+	Value    Expression
+}
+
+type UpdateExpression struct {
+	baseNode
+	Operator string
+	Prefix   bool
+	Argument Expression
+}
+
+// SequenceExpression es 'a, b, c': evalúa todas y vale la última. Solo se
+// admite en las cláusulas de un for.
+type SequenceExpression struct {
+	baseNode
+	Expressions []Expression
+}
+
+// ConditionalExpression es el operador ternario 'Test ? Consequent : Alternate'
+type ConditionalExpression struct {
+	baseNode
+	Test       Expression
+	Consequent Expression
+	Alternate  Expression
+}
+
+type CallExpression struct {
+	baseNode
+	Callee        Expression
+	TypeArguments []TypeNode // 'f<number>(x)'
+	Arguments     []Expression
+	Optional      bool // 'f?.(x)'
+}
+
+type MemberExpression struct {
+	baseNode
+	Object   Expression
+	Property *Identifier
+	Optional bool // 'a?.b'
+}
+
+// IndexExpression es el acceso por índice 'Object[Index]'
+type IndexExpression struct {
+	baseNode
+	Object   Expression
+	Index    Expression
+	Optional bool // 'a?.[i]'
+}
+
+// FunctionExpression es una función anónima (o con nombre) usada como valor
+type FunctionExpression struct {
+	baseNode
+	Name       *Identifier // opcional
+	TypeParams []*TypeParameter
+	Params     []*Parameter
+	ReturnType TypeNode
+	Body       *BlockStatement
+}
+
+// ArrowFunction es '<T>(Params): ReturnType => Body'; Body es un
+// *BlockStatement o una Expression cuyo valor se devuelve.
+type ArrowFunction struct {
+	baseNode
+	TypeParams []*TypeParameter
+	Params     []*Parameter
+	ReturnType TypeNode
+	Body       Node
+}
+
+// ArrayLiteral es '[a, b, ...resto]'; un hueco, como en '[a, , b]', es nil
+type ArrayLiteral struct {
+	baseNode
+	Elements []Expression
+}
+
+// SpreadElement es '...Argument' dentro de un array, de un objeto o de los
+// argumentos de una llamada.
+type SpreadElement struct {
+	baseNode
+	Argument Expression
+}
+
+// ObjectLiteral es '{ clave: valor, ...otro }'
+type ObjectLiteral struct {
+	baseNode
+	Properties []ObjectMember
+}
+
+// ObjectMember es una entrada de un objeto literal: *Property o *SpreadElement
+type ObjectMember interface {
+	Node
+	objectMember()
+}
+
+// Property es una entrada 'Key: Value' de un objeto literal. Key es un
+// *Identifier, un *StringLiteral o un *NumericLiteral, o cualquier expresión
+// si es calculada ('[k]: v'). En la forma abreviada '{ x }', Value es la
+// referencia a la variable 'x' y Key una copia de su nombre. En un método
+// '{ m() { ... } }', Value es la *FunctionExpression.
+type Property struct {
+	baseNode
+	Key       Expression
+	Value     Expression
+	Computed  bool
+	Shorthand bool
+	Method    bool
+}
+
+// ThisExpression es 'this'
+type ThisExpression struct {
+	baseNode
+}
+
+// SuperExpression es 'super' en 'super(...)' o 'super.metodo()'
+type SuperExpression struct {
+	baseNode
+}
+
+// NewExpression es 'new Callee(Arguments)'
+type NewExpression struct {
+	baseNode
+	Callee        Expression
+	TypeArguments []TypeNode // 'new Caja<number>()'
+	Arguments     []Expression
+}
+
+// BadExpression marca un token que no pudo interpretarse (por ejemplo un
+// número mal formado); conserva el texto original para los diagnósticos.
+type BadExpression struct {
+	baseNode
+	Raw string
+}
+
+// ---------------------------------------------------------------------------
+// Patrones de desestructuración
+// ---------------------------------------------------------------------------
+
+// Pattern es el destino de una declaración que desestructura un valor: un
+// *Identifier, un *ArrayPattern o un *ObjectPattern.
+type Pattern interface {
+	Node
+	patternNode()
+}
+
+// ArrayPattern es '[a, , b = 1, ...resto]'; un hueco es nil
+type ArrayPattern struct {
+	baseNode
+	Elements []*BindingElement
+	Rest     Pattern
+}
+
+// BindingElement es un elemento de un patrón de array: 'Target = Default'
+type BindingElement struct {
+	baseNode
+	Target  Pattern
+	Default Expression
+}
+
+// ObjectPattern es '{ a, b: otro, c = 1, ...resto }'
+type ObjectPattern struct {
+	baseNode
+	Properties []*PatternProperty
+	Rest       *Identifier
+}
+
+// PatternProperty es 'Key: Target = Default' dentro de un patrón de objeto.
+// En la forma abreviada '{ x }', Target es la variable 'x' y Key una copia de
+// su nombre.
+type PatternProperty struct {
+	baseNode
+	Key       Expression
+	Target    Pattern
+	Default   Expression
+	Computed  bool
+	Shorthand bool
+}
+
+// ---------------------------------------------------------------------------
+// Tipos
+// ---------------------------------------------------------------------------
+
+// TypeNode es cualquier anotación de tipo
+type TypeNode interface {
+	Node
+	typeNode()
+}
+
+// TypeReference nombra un tipo: 'number', 'Punto' o 'Array<string>'
+type TypeReference struct {
+	baseNode
+	Name          string
+	TypeArguments []TypeNode
+}
+
+// ArrayType es 'Element[]'
+type ArrayType struct {
+	baseNode
+	Element TypeNode
+}
+
+// UnionType es 'A | B | C'
+type UnionType struct {
+	baseNode
+	Types []TypeNode
+}
+
+// IntersectionType es 'A & B'
+type IntersectionType struct {
+	baseNode
+	Types []TypeNode
+}
+
+// LiteralType es un tipo con un único valor: '"rojo"', '42', '-1' o 'true'
+type LiteralType struct {
+	baseNode
+	Literal Expression
+}
+
+// ObjectType es el tipo objeto literal '{ x: number; y?: string }'
+type ObjectType struct {
+	baseNode
+	Members []*PropertySignature
+}
+
+// PropertySignature es un miembro 'Name?: Type' de una interfaz o de un tipo
+// objeto. Los métodos 'name(params): T' guardan un *FunctionType.
+type PropertySignature struct {
+	baseNode
+	Name           *Identifier
+	Optional       bool
+	Readonly       bool
+	Method         bool
+	TypeAnnotation TypeNode
+}
+
+// FunctionType es '<T>(Params) => ReturnType'
+type FunctionType struct {
+	baseNode
+	TypeParams []*TypeParameter
+	Params     []*Parameter
+	ReturnType TypeNode
+}
+
+// TypeParameter declara un parámetro de tipo 'T extends Constraint = Default'
+// de una función, interfaz, alias o clase genérica.
+type TypeParameter struct {
+	baseNode
+	Name       *Identifier
+	Constraint TypeNode
+	Default    TypeNode
+}
+
+// ---------------------------------------------------------------------------
+// Kind
+// ---------------------------------------------------------------------------
+
+func (n *Program) Kind() string               { return "Program" }
+func (n *VariableDeclaration) Kind() string   { return "VariableDeclaration" }
+func (n *VariableDeclarator) Kind() string    { return "VariableDeclarator" }
+func (n *ForStatement) Kind() string          { return "ForStatement" }
+func (n *ForInStatement) Kind() string        { return "ForInStatement" }
+func (n *ForOfStatement) Kind() string        { return "ForOfStatement" }
+func (n *DoWhileStatement) Kind() string      { return "DoWhileStatement" }
+func (n *WhileStatement) Kind() string        { return "WhileStatement" }
+func (n *IfStatement) Kind() string           { return "IfStatement" }
+func (n *SwitchStatement) Kind() string       { return "SwitchStatement" }
+func (n *SwitchCase) Kind() string            { return "SwitchCase" }
+func (n *BreakStatement) Kind() string        { return "BreakStatement" }
+func (n *ContinueStatement) Kind() string     { return "ContinueStatement" }
+func (n *ReturnStatement) Kind() string       { return "ReturnStatement" }
+func (n *FunctionDeclaration) Kind() string   { return "FunctionDeclaration" }
+func (n *InterfaceDeclaration) Kind() string  { return "InterfaceDeclaration" }
+func (n *TypeAliasDeclaration) Kind() string  { return "TypeAliasDeclaration" }
+func (n *ClassDeclaration) Kind() string      { return "ClassDeclaration" }
+func (n *PropertyDefinition) Kind() string    { return "PropertyDefinition" }
+func (n *MethodDefinition) Kind() string      { return "MethodDefinition" }
+func (n *EnumDeclaration) Kind() string       { return "EnumDeclaration" }
+func (n *EnumMember) Kind() string            { return "EnumMember" }
+func (n *ModuleDeclaration) Kind() string     { return "ModuleDeclaration" }
+func (n *ExportDeclaration) Kind() string     { return "ExportDeclaration" }
+func (n *Parameter) Kind() string             { return "Parameter" }
+func (n *FunctionExpression) Kind() string    { return "FunctionExpression" }
+func (n *ArrowFunction) Kind() string         { return "ArrowFunction" }
+func (n *BlockStatement) Kind() string        { return "BlockStatement" }
+func (n *ExpressionStatement) Kind() string   { return "ExpressionStatement" }
+func (n *Identifier) Kind() string            { return "Identifier" }
+func (n *NumericLiteral) Kind() string        { return "NumericLiteral" }
+func (n *StringLiteral) Kind() string         { return "StringLiteral" }
+func (n *BooleanLiteral) Kind() string        { return "BooleanLiteral" }
+func (n *TemplateLiteral) Kind() string       { return "TemplateLiteral" }
+func (n *BinaryExpression) Kind() string      { return "BinaryExpression" }
+func (n *LogicalExpression) Kind() string     { return "LogicalExpression" }
+func (n *UnaryExpression) Kind() string       { return "UnaryExpression" }
+func (n *AssignmentExpression) Kind() string  { return "AssignmentExpression" }
+func (n *UpdateExpression) Kind() string      { return "UpdateExpression" }
+func (n *SequenceExpression) Kind() string    { return "SequenceExpression" }
+func (n *ConditionalExpression) Kind() string { return "ConditionalExpression" }
+func (n *CallExpression) Kind() string        { return "CallExpression" }
+func (n *MemberExpression) Kind() string      { return "MemberExpression" }
+func (n *IndexExpression) Kind() string       { return "IndexExpression" }
+func (n *ArrayLiteral) Kind() string          { return "ArrayLiteral" }
+func (n *SpreadElement) Kind() string         { return "SpreadElement" }
+func (n *ObjectLiteral) Kind() string         { return "ObjectLiteral" }
+func (n *Property) Kind() string              { return "Property" }
+func (n *ArrayPattern) Kind() string          { return "ArrayPattern" }
+func (n *BindingElement) Kind() string        { return "BindingElement" }
+func (n *ObjectPattern) Kind() string         { return "ObjectPattern" }
+func (n *PatternProperty) Kind() string       { return "PatternProperty" }
+func (n *ThisExpression) Kind() string        { return "ThisExpression" }
+func (n *SuperExpression) Kind() string       { return "SuperExpression" }
+func (n *NewExpression) Kind() string         { return "NewExpression" }
+func (n *BadExpression) Kind() string         { return "BadExpression" }
+func (n *TypeReference) Kind() string         { return "TypeReference" }
+func (n *ArrayType) Kind() string             { return "ArrayType" }
+func (n *UnionType) Kind() string             { return "UnionType" }
+func (n *IntersectionType) Kind() string      { return "IntersectionType" }
+func (n *LiteralType) Kind() string           { return "LiteralType" }
+func (n *ObjectType) Kind() string            { return "ObjectType" }
+func (n *PropertySignature) Kind() string     { return "PropertySignature" }
+func (n *FunctionType) Kind() string          { return "FunctionType" }
+func (n *TypeParameter) Kind() string         { return "TypeParameter" }
+
+// ---------------------------------------------------------------------------
+// Children
+// ---------------------------------------------------------------------------
+
+func (n *Program) Children() []Node {
+	return statementNodes(n.Body)
+}
+
+func (n *VariableDeclaration) Children() []Node {
+	children := make([]Node, 0, len(n.Declarations))
+	for _, d := range n.Declarations {
+		children = append(children, d)
+	}
+	return children
+}
+
+func (n *VariableDeclarator) Children() []Node {
+	children := make([]Node, 0, 3)
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	if n.Pattern != nil {
+		children = append(children, n.Pattern)
+	}
+	if n.TypeAnnotation != nil {
+		children = append(children, n.TypeAnnotation)
+	}
+	if n.Init != nil {
+		children = append(children, n.Init)
+	}
+	return children
+}
+
+func (n *ForStatement) Children() []Node {
+	children := make([]Node, 0, 4)
+	if n.Init != nil {
+		children = append(children, n.Init)
+	}
+	if n.Test != nil {
+		children = append(children, n.Test)
+	}
+	if n.Update != nil {
+		children = append(children, n.Update)
+	}
+	if n.Body != nil {
+		children = append(children, n.Body)
+	}
+	return children
+}
+
+func (n *ForInStatement) Children() []Node {
+	return forEachChildren(n.Left, n.Right, n.Body)
+}
+
+func (n *ForOfStatement) Children() []Node {
+	return forEachChildren(n.Left, n.Right, n.Body)
+}
+
+func forEachChildren(left Node, right Expression, body Statement) []Node {
+	children := make([]Node, 0, 3)
+	if left != nil {
+		children = append(children, left)
+	}
+	children = append(children, expressionNodes(right)...)
+	if body != nil {
+		children = append(children, body)
+	}
+	return children
+}
+
+func (n *DoWhileStatement) Children() []Node {
+	children := make([]Node, 0, 2)
+	if n.Body != nil {
+		children = append(children, n.Body)
+	}
+	if n.Test != nil {
+		children = append(children, n.Test)
+	}
+	return children
+}
+
+func (n *WhileStatement) Children() []Node {
+	children := expressionNodes(n.Test)
+	if n.Body != nil {
+		children = append(children, n.Body)
+	}
+	return children
+}
+
+func (n *IfStatement) Children() []Node {
+	children := expressionNodes(n.Test)
+	if n.Consequent != nil {
+		children = append(children, n.Consequent)
+	}
+	if n.Alternate != nil {
+		children = append(children, n.Alternate)
+	}
+	return children
+}
+
+func (n *SwitchStatement) Children() []Node {
+	children := expressionNodes(n.Discriminant)
+	for _, c := range n.Cases {
+		children = append(children, c)
+	}
+	return children
+}
+
+func (n *SwitchCase) Children() []Node {
+	return append(expressionNodes(n.Test), statementNodes(n.Consequent)...)
+}
+
+func (n *BreakStatement) Children() []Node    { return nil }
+func (n *ContinueStatement) Children() []Node { return nil }
+
+func (n *ReturnStatement) Children() []Node {
+	return expressionNodes(n.Argument)
+}
+
+func (n *FunctionDeclaration) Children() []Node {
+	return functionChildren(n.Name, n.TypeParams, n.Params, n.ReturnType, blockNode(n.Body))
+}
+
+func (n *InterfaceDeclaration) Children() []Node {
+	children := make([]Node, 0, 1+len(n.TypeParams)+len(n.Extends)+len(n.Members))
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	children = appendTypeParameters(children, n.TypeParams)
+	for _, ref := range n.Extends {
+		children = append(children, ref)
+	}
+	for _, member := range n.Members {
+		children = append(children, member)
+	}
+	return children
+}
+
+func (n *TypeAliasDeclaration) Children() []Node {
+	children := make([]Node, 0, 2+len(n.TypeParams))
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	children = appendTypeParameters(children, n.TypeParams)
+	if n.Type != nil {
+		children = append(children, n.Type)
+	}
+	return children
+}
+
+func (n *ClassDeclaration) Children() []Node {
+	children := make([]Node, 0, 2+len(n.TypeParams)+len(n.SuperTypeArguments)+len(n.Implements)+len(n.Members))
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	children = appendTypeParameters(children, n.TypeParams)
+	if n.SuperClass != nil {
+		children = append(children, n.SuperClass)
+	}
+	children = append(children, typeNodes(n.SuperTypeArguments...)...)
+	for _, ref := range n.Implements {
+		children = append(children, ref)
+	}
+	for _, member := range n.Members {
+		children = append(children, member)
+	}
+	return children
+}
+
+func (n *PropertyDefinition) Children() []Node {
+	children := []Node{n.Name}
+	if n.TypeAnnotation != nil {
+		children = append(children, n.TypeAnnotation)
+	}
+	if n.Value != nil {
+		children = append(children, n.Value)
+	}
+	return children
+}
+
+func (n *MethodDefinition) Children() []Node {
+	children := []Node{n.Name}
+	if n.Function != nil {
+		children = append(children, n.Function)
+	}
+	return children
+}
+
+func (n *EnumDeclaration) Children() []Node {
+	children := make([]Node, 0, 1+len(n.Members))
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	for _, member := range n.Members {
+		children = append(children, member)
+	}
+	return children
+}
+
+func (n *EnumMember) Children() []Node {
+	children := []Node{n.Name}
+	if n.Initializer != nil {
+		children = append(children, n.Initializer)
+	}
+	return children
+}
+
+func (n *ModuleDeclaration) Children() []Node {
+	children := make([]Node, 0, 2)
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	if n.Body != nil {
+		children = append(children, n.Body)
+	}
+	return children
+}
+
+func (n *ExportDeclaration) Children() []Node {
+	if n.Declaration == nil {
+		return nil
+	}
+	return []Node{n.Declaration}
+}
+
+func (n *Parameter) Children() []Node {
+	children := make([]Node, 0, 3)
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	if n.Pattern != nil {
+		children = append(children, n.Pattern)
+	}
+	if n.TypeAnnotation != nil {
+		children = append(children, n.TypeAnnotation)
+	}
+	if n.Default != nil {
+		children = append(children, n.Default)
+	}
+	return children
+}
+
+func (n *FunctionExpression) Children() []Node {
+	return functionChildren(n.Name, n.TypeParams, n.Params, n.ReturnType, blockNode(n.Body))
+}
+
+func (n *ArrowFunction) Children() []Node {
+	return functionChildren(nil, n.TypeParams, n.Params, n.ReturnType, n.Body)
+}
+
+func (n *BlockStatement) Children() []Node {
+	return statementNodes(n.Body)
+}
+
+func (n *ExpressionStatement) Children() []Node {
+	if n.Expression == nil {
+		return nil
+	}
+	return []Node{n.Expression}
+}
+
+func (n *Identifier) Children() []Node      { return nil }
+func (n *NumericLiteral) Children() []Node  { return nil }
+func (n *StringLiteral) Children() []Node   { return nil }
+func (n *BooleanLiteral) Children() []Node  { return nil }
+func (n *ThisExpression) Children() []Node  { return nil }
+func (n *SuperExpression) Children() []Node { return nil }
+func (n *BadExpression) Children() []Node   { return nil }
+
+func (n *TypeReference) Children() []Node {
+	return typeNodes(n.TypeArguments...)
+}
+
+func (n *ArrayType) Children() []Node {
+	return typeNodes(n.Element)
+}
+
+func (n *UnionType) Children() []Node {
+	return typeNodes(n.Types...)
+}
+
+func (n *IntersectionType) Children() []Node {
+	return typeNodes(n.Types...)
+}
+
+func (n *LiteralType) Children() []Node {
+	return expressionNodes(n.Literal)
+}
+
+func (n *ObjectType) Children() []Node {
+	children := make([]Node, 0, len(n.Members))
+	for _, member := range n.Members {
+		children = append(children, member)
+	}
+	return children
+}
+
+func (n *PropertySignature) Children() []Node {
+	children := []Node{n.Name}
+	if n.TypeAnnotation != nil {
+		children = append(children, n.TypeAnnotation)
+	}
+	return children
+}
+
+func (n *FunctionType) Children() []Node {
+	return functionChildren(nil, n.TypeParams, n.Params, n.ReturnType, nil)
+}
+
+func (n *TypeParameter) Children() []Node {
+	children := make([]Node, 0, 3)
+	if n.Name != nil {
+		children = append(children, n.Name)
+	}
+	return append(children, typeNodes(n.Constraint, n.Default)...)
+}
+
+func (n *TemplateLiteral) Children() []Node {
+	return expressionNodes(n.Expressions...)
+}
+
+func (n *BinaryExpression) Children() []Node {
+	return expressionNodes(n.Left, n.Right)
+}
+
+func (n *LogicalExpression) Children() []Node {
+	return expressionNodes(n.Left, n.Right)
+}
+
+func (n *UnaryExpression) Children() []Node {
+	return expressionNodes(n.Argument)
+}
+
+func (n *AssignmentExpression) Children() []Node {
+	return expressionNodes(n.Target, n.Value)
+}
+
+func (n *UpdateExpression) Children() []Node {
+	return expressionNodes(n.Argument)
+}
+
+func (n *SequenceExpression) Children() []Node {
+	return expressionNodes(n.Expressions...)
+}
+
+func (n *ConditionalExpression) Children() []Node {
+	return expressionNodes(n.Test, n.Consequent, n.Alternate)
+}
+
+func (n *CallExpression) Children() []Node {
+	children := append(expressionNodes(n.Callee), typeNodes(n.TypeArguments...)...)
+	for _, arg := range n.Arguments {
+		children = append(children, arg)
+	}
+	return children
+}
+
+func (n *MemberExpression) Children() []Node {
+	children := expressionNodes(n.Object)
+	if n.Property != nil {
+		children = append(children, n.Property)
+	}
+	return children
+}
+
+func (n *IndexExpression) Children() []Node {
+	return expressionNodes(n.Object, n.Index)
+}
+
+func (n *ArrayLiteral) Children() []Node {
+	return expressionNodes(n.Elements...)
+}
+
+func (n *SpreadElement) Children() []Node {
+	return expressionNodes(n.Argument)
+}
+
+func (n *ObjectLiteral) Children() []Node {
+	children := make([]Node, 0, len(n.Properties))
+	for _, prop := range n.Properties {
+		children = append(children, prop)
+	}
+	return children
+}
+
+func (n *Property) Children() []Node {
+	if n.Shorthand {
+		return expressionNodes(n.Value)
+	}
+	return expressionNodes(n.Key, n.Value)
+}
+
+func (n *ArrayPattern) Children() []Node {
+	children := make([]Node, 0, len(n.Elements)+1)
+	for _, element := range n.Elements {
+		if element != nil {
+			children = append(children, element)
+		}
+	}
+	if n.Rest != nil {
+		children = append(children, n.Rest)
+	}
+	return children
+}
+
+func (n *BindingElement) Children() []Node {
+	children := make([]Node, 0, 2)
+	if n.Target != nil {
+		children = append(children, n.Target)
+	}
+	return append(children, expressionNodes(n.Default)...)
+}
+
+func (n *ObjectPattern) Children() []Node {
+	children := make([]Node, 0, len(n.Properties)+1)
+	for _, prop := range n.Properties {
+		children = append(children, prop)
+	}
+	if n.Rest != nil {
+		children = append(children, n.Rest)
+	}
+	return children
+}
+
+func (n *PatternProperty) Children() []Node {
+	children := make([]Node, 0, 3)
+	if !n.Shorthand && n.Key != nil {
+		children = append(children, n.Key)
+	}
+	if n.Target != nil {
+		children = append(children, n.Target)
+	}
+	return append(children, expressionNodes(n.Default)...)
+}
+
+func (n *NewExpression) Children() []Node {
+	children := append(expressionNodes(n.Callee), typeNodes(n.TypeArguments...)...)
+	return append(children, expressionNodes(n.Arguments...)...)
+}
+
+func functionChildren(name *Identifier, typeParams []*TypeParameter, params []*Parameter, returnType TypeNode,
+	body Node) []Node {
+	children := make([]Node, 0, len(typeParams)+len(params)+3)
+	if name != nil {
+		children = append(children, name)
+	}
+	children = appendTypeParameters(children, typeParams)
+	for _, param := range params {
+		children = append(children, param)
+	}
+	if returnType != nil {
+		children = append(children, returnType)
+	}
+	if body != nil {
+		children = append(children, body)
+	}
+	return children
+}
+
+// blockNode evita convertir un *BlockStatement nil en un Node no nil
+func blockNode(block *BlockStatement) Node {
+	if block == nil {
+		return nil
+	}
+	return block
+}
+
+func statementNodes(statements []Statement) []Node {
+	children := make([]Node, 0, len(statements))
+	for _, stmt := range statements {
+		children = append(children, stmt)
+	}
+	return children
+}
+
+func expressionNodes(expressions ...Expression) []Node {
+	children := make([]Node, 0, len(expressions))
+	for _, expr := range expressions {
+		if expr != nil {
+			children = append(children, expr)
+		}
+	}
+	return children
+}
+
+func typeNodes(types ...TypeNode) []Node {
+	children := make([]Node, 0, len(types))
+	for _, t := range types {
+		if t != nil {
+			children = append(children, t)
+		}
+	}
+	return children
+}
+
+func appendTypeParameters(children []Node, params []*TypeParameter) []Node {
+	for _, param := range params {
+		children = append(children, param)
+	}
+	return children
+}
+
+// ---------------------------------------------------------------------------
+// Marcadores de categoría
+// ---------------------------------------------------------------------------
+
+func (n *VariableDeclaration) statementNode()  {}
+func (n *ForStatement) statementNode()         {}
+func (n *ForInStatement) statementNode()       {}
+func (n *ForOfStatement) statementNode()       {}
+func (n *DoWhileStatement) statementNode()     {}
+func (n *WhileStatement) statementNode()       {}
+func (n *IfStatement) statementNode()          {}
+func (n *SwitchStatement) statementNode()      {}
+func (n *BreakStatement) statementNode()       {}
+func (n *ContinueStatement) statementNode()    {}
+func (n *ReturnStatement) statementNode()      {}
+func (n *FunctionDeclaration) statementNode()  {}
+func (n *InterfaceDeclaration) statementNode() {}
+func (n *TypeAliasDeclaration) statementNode() {}
+func (n *ClassDeclaration) statementNode()     {}
+func (n *EnumDeclaration) statementNode()      {}
+func (n *ModuleDeclaration) statementNode()    {}
+func (n *ExportDeclaration) statementNode()    {}
+func (n *BlockStatement) statementNode()       {}
+func (n *ExpressionStatement) statementNode()  {}
+
+func (n *Identifier) expressionNode()            {}
+func (n *NumericLiteral) expressionNode()        {}
+func (n *StringLiteral) expressionNode()         {}
+func (n *BooleanLiteral) expressionNode()        {}
+func (n *TemplateLiteral) expressionNode()       {}
+func (n *BinaryExpression) expressionNode()      {}
+func (n *LogicalExpression) expressionNode()     {}
+func (n *UnaryExpression) expressionNode()       {}
+func (n *AssignmentExpression) expressionNode()  {}
+func (n *UpdateExpression) expressionNode()      {}
+func (n *SequenceExpression) expressionNode()    {}
+func (n *ConditionalExpression) expressionNode() {}
+func (n *CallExpression) expressionNode()        {}
+func (n *MemberExpression) expressionNode()      {}
+func (n *IndexExpression) expressionNode()       {}
+func (n *FunctionExpression) expressionNode()    {}
+func (n *ArrowFunction) expressionNode()         {}
+func (n *ArrayLiteral) expressionNode()          {}
+func (n *SpreadElement) expressionNode()         {}
+func (n *ObjectLiteral) expressionNode()         {}
+func (n *ThisExpression) expressionNode()        {}
+func (n *SuperExpression) expressionNode()       {}
+func (n *NewExpression) expressionNode()         {}
+func (n *BadExpression) expressionNode()         {}
+
+func (n *TypeReference) typeNode()    {}
+func (n *ArrayType) typeNode()        {}
+func (n *UnionType) typeNode()        {}
+func (n *IntersectionType) typeNode() {}
+func (n *LiteralType) typeNode()      {}
+func (n *ObjectType) typeNode()       {}
+func (n *FunctionType) typeNode()     {}
+
+func (n *PropertyDefinition) classMember() {}
+func (n *MethodDefinition) classMember()   {}
+
+func (n *Property) objectMember()      {}
+func (n *SpreadElement) objectMember() {}
+
+func (n *Identifier) patternNode()    {}
+func (n *ArrayPattern) patternNode()  {}
+func (n *ObjectPattern) patternNode() {}
+
+// ---------------------------------------------------------------------------
+// Recorrido y utilidades
+// ---------------------------------------------------------------------------
+
+// Inspect recorre el árbol en profundidad llamando a fn con cada nodo; si fn
+// devuelve false no se visitan los hijos de ese nodo.
+func Inspect(node Node, fn func(Node) bool) {
+	if node == nil || !fn(node) {
+		return
+	}
+	for _, child := range node.Children() {
+		Inspect(child, fn)
+	}
+}
+
+// exprString reconstruye una representación textual compacta de la expresión
+// para los mensajes del análisis semántico. Solo añade los paréntesis que
+// exige la precedencia de los operadores.
+func exprString(expr Expression) string {
+	if expr == nil {
+		return ""
+	}
+	var sb strings.Builder
+	writeExpr(&sb, expr, precNone)
+	return sb.String()
+}
+
+func writeExpr(sb *strings.Builder, expr Expression, parentPrec int) {
+	switch e := expr.(type) {
+	case *Identifier:
+		sb.WriteString(e.Name)
+	case *NumericLiteral:
+		sb.WriteString(e.Raw)
+	case *StringLiteral:
+		sb.WriteString(e.Raw)
+	case *BooleanLiteral:
+		sb.WriteString(strconv.FormatBool(e.Value))
+	case *TemplateLiteral:
+		sb.WriteByte('`')
+		for i, quasi := range e.Quasis {
+			sb.WriteString(quasi)
+			if i < len(e.Expressions) {
+				sb.WriteString("${")
+				writeExpr(sb, e.Expressions[i], precNone)
+				sb.WriteByte('}')
+			}
+		}
+		sb.WriteByte('`')
+	case *BadExpression:
+		sb.WriteString(e.Raw)
+	case *BinaryExpression:
+		writeBinary(sb, e.Operator, e.Left, e.Right, parentPrec)
+	case *LogicalExpression:
+		writeBinary(sb, e.Operator, e.Left, e.Right, parentPrec)
+	case *UnaryExpression:
+		sb.WriteString(e.Operator)
+		switch e.Argument.(type) {
+		case *UnaryExpression, *UpdateExpression:
+			// Evitar que '-(-a)' se imprima como '--a'
+			sb.WriteByte('(')
+			writeExpr(sb, e.Argument, precNone)
+			sb.WriteByte(')')
+		default:
+			writeExpr(sb, e.Argument, precUnary)
+		}
+	case *AssignmentExpression:
+		if parentPrec > precNone {
+			sb.WriteByte('(')
+		}
+		writeExpr(sb, e.Target, precNone)
+		sb.WriteString(" " + e.Operator + " ")
+		writeExpr(sb, e.Value, precNone)
+		if parentPrec > precNone {
+			sb.WriteByte(')')
+		}
+	case *UpdateExpression:
+		if e.Prefix {
+			sb.WriteString(e.Operator)
+			writeExpr(sb, e.Argument, precUnary)
+		} else {
+			writeExpr(sb, e.Argument, precUnary)
+			sb.WriteString(e.Operator)
+		}
+	case *SequenceExpression:
+		if parentPrec > precNone {
+			sb.WriteByte('(')
+		}
+		for i, part := range e.Expressions {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeExpr(sb, part, precNone)
+		}
+		if parentPrec > precNone {
+			sb.WriteByte(')')
+		}
+	case *FunctionExpression:
+		sb.WriteString("function ")
+		if e.Name != nil {
+			sb.WriteString(e.Name.Name)
+		}
+		writeParams(sb, e.Params)
+		sb.WriteString(" {...}")
+	case *ArrowFunction:
+		if parentPrec > precNone {
+			sb.WriteByte('(')
+		}
+		writeParams(sb, e.Params)
+		sb.WriteString(" => ")
+		if body, ok := e.Body.(Expression); ok {
+			writeExpr(sb, body, precNone)
+		} else {
+			sb.WriteString("{...}")
+		}
+		if parentPrec > precNone {
+			sb.WriteByte(')')
+		}
+	case *ConditionalExpression:
+		if parentPrec > precNone {
+			sb.WriteByte('(')
+		}
+		writeExpr(sb, e.Test, precLogicalOr)
+		sb.WriteString(" ? ")
+		writeExpr(sb, e.Consequent, precNone)
+		sb.WriteString(" : ")
+		writeExpr(sb, e.Alternate, precNone)
+		if parentPrec > precNone {
+			sb.WriteByte(')')
+		}
+	case *CallExpression:
+		writeExpr(sb, e.Callee, precUnary)
+		if e.Optional {
+			sb.WriteString("?.")
+		}
+		writeTypeArguments(sb, e.TypeArguments)
+		writeArguments(sb, e.Arguments)
+	case *ThisExpression:
+		sb.WriteString("this")
+	case *SuperExpression:
+		sb.WriteString("super")
+	case *NewExpression:
+		sb.WriteString("new ")
+		writeExpr(sb, e.Callee, precUnary)
+		writeTypeArguments(sb, e.TypeArguments)
+		writeArguments(sb, e.Arguments)
+	case *MemberExpression:
+		writeExpr(sb, e.Object, precUnary)
+		if e.Optional {
+			sb.WriteString("?.")
+		} else {
+			sb.WriteByte('.')
+		}
+		if e.Property != nil {
+			sb.WriteString(e.Property.Name)
+		}
+	case *IndexExpression:
+		writeExpr(sb, e.Object, precUnary)
+		if e.Optional {
+			sb.WriteString("?.")
+		}
+		sb.WriteByte('[')
+		writeExpr(sb, e.Index, precNone)
+		sb.WriteByte(']')
+	case *ArrayLiteral:
+		sb.WriteByte('[')
+		for i, element := range e.Elements {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeExpr(sb, element, precNone)
+		}
+		if n := len(e.Elements); n > 0 && e.Elements[n-1] == nil {
+			// '[a, ]' es un array de un elemento: el hueco final necesita su coma
+			sb.WriteByte(',')
+		}
+		sb.WriteByte(']')
+	case *SpreadElement:
+		sb.WriteString("...")
+		writeExpr(sb, e.Argument, precUnary)
+	case *ObjectLiteral:
+		if len(e.Properties) == 0 {
+			sb.WriteString("{}")
+			return
+		}
+		sb.WriteString("{ ")
+		for i, member := range e.Properties {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			prop, ok := member.(*Property)
+			if !ok {
+				writeExpr(sb, member.(*SpreadElement), precNone)
+				continue
+			}
+			writeKey(sb, prop.Key, prop.Computed)
+			if fn, ok := prop.Value.(*FunctionExpression); ok && prop.Method {
+				writeParams(sb, fn.Params)
+				sb.WriteString(" {...}")
+			} else if !prop.Shorthand {
+				sb.WriteString(": ")
+				writeExpr(sb, prop.Value, precNone)
+			}
+		}
+		sb.WriteString(" }")
+	}
+}
+
+// writeKey escribe la clave de una propiedad, entre corchetes si es calculada
+func writeKey(sb *strings.Builder, key Expression, computed bool) {
+	if computed {
+		sb.WriteByte('[')
+		writeExpr(sb, key, precNone)
+		sb.WriteByte(']')
+		return
+	}
+	writeExpr(sb, key, precNone)
+}
+
+// patternString escribe un destino de desestructuración tal como se vería en
+// el código: '{ a, b: [c, d = 1] }'.
+func patternString(pattern Pattern) string {
+	var sb strings.Builder
+	writePattern(&sb, pattern)
+	return sb.String()
+}
+
+func writePattern(sb *strings.Builder, pattern Pattern) {
+	switch n := pattern.(type) {
+	case *Identifier:
+		sb.WriteString(n.Name)
+	case *ArrayPattern:
+		sb.WriteByte('[')
+		for i, element := range n.Elements {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if element == nil {
+				continue
+			}
+			writePattern(sb, element.Target)
+			writeDefault(sb, element.Default)
+		}
+		if n.Rest != nil {
+			if len(n.Elements) > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("...")
+			writePattern(sb, n.Rest)
+		} else if len(n.Elements) > 0 && n.Elements[len(n.Elements)-1] == nil {
+			sb.WriteByte(',')
+		}
+		sb.WriteByte(']')
+	case *ObjectPattern:
+		if len(n.Properties) == 0 && n.Rest == nil {
+			sb.WriteString("{}")
+			return
+		}
+		sb.WriteString("{ ")
+		for i, prop := range n.Properties {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			if prop.Shorthand {
+				writePattern(sb, prop.Target)
+			} else {
+				writeKey(sb, prop.Key, prop.Computed)
+				sb.WriteString(": ")
+				writePattern(sb, prop.Target)
+			}
+			writeDefault(sb, prop.Default)
+		}
+		if n.Rest != nil {
+			if len(n.Properties) > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString("..." + n.Rest.Name)
+		}
+		sb.WriteString(" }")
+	}
+}
+
+func writeDefault(sb *strings.Builder, value Expression) {
+	if value != nil {
+		sb.WriteString(" = ")
+		writeExpr(sb, value, precNone)
+	}
+}
+
+// typeNodeString escribe una anotación de tipo tal como se vería en el código
+func typeNodeString(t TypeNode) string {
+	if t == nil {
+		return ""
+	}
+	var sb strings.Builder
+	writeType(&sb, t)
+	return sb.String()
+}
+
+// typeParametersString escribe '<T extends X>' o nada si no hay parámetros
+func typeParametersString(params []*TypeParameter) string {
+	var sb strings.Builder
+	writeTypeParameters(&sb, params)
+	return sb.String()
+}
+
+func writeType(sb *strings.Builder, t TypeNode) {
+	switch n := t.(type) {
+	case *TypeReference:
+		sb.WriteString(n.Name)
+		writeTypeArguments(sb, n.TypeArguments)
+	case *ArrayType:
+		switch n.Element.(type) {
+		case *UnionType, *IntersectionType, *FunctionType:
+			sb.WriteByte('(')
+			writeType(sb, n.Element)
+			sb.WriteByte(')')
+		default:
+			writeType(sb, n.Element)
+		}
+		sb.WriteString("[]")
+	case *UnionType:
+		writeTypeList(sb, n.Types, " | ")
+	case *IntersectionType:
+		writeTypeList(sb, n.Types, " & ")
+	case *LiteralType:
+		writeExpr(sb, n.Literal, precNone)
+	case *ObjectType:
+		if len(n.Members) == 0 {
+			sb.WriteString("{}")
+			return
+		}
+		sb.WriteString("{ ")
+		for i, member := range n.Members {
+			if i > 0 {
+				sb.WriteString("; ")
+			}
+			if member.Readonly {
+				sb.WriteString("readonly ")
+			}
+			sb.WriteString(member.Name.Name)
+			if member.Optional {
+				sb.WriteByte('?')
+			}
+			sb.WriteString(": ")
+			if member.TypeAnnotation != nil {
+				writeType(sb, member.TypeAnnotation)
+			} else {
+				sb.WriteString("any")
+			}
+		}
+		sb.WriteString(" }")
+	case *FunctionType:
+		writeTypeParameters(sb, n.TypeParams)
+		writeParams(sb, n.Params)
+		sb.WriteString(" => ")
+		if n.ReturnType != nil {
+			writeType(sb, n.ReturnType)
+		} else {
+			sb.WriteString("any")
+		}
+	}
+}
+
+func writeTypeList(sb *strings.Builder, types []TypeNode, separator string) {
+	for i, t := range types {
+		if i > 0 {
+			sb.WriteString(separator)
+		}
+		writeType(sb, t)
+	}
+}
+
+// writeTypeArguments escribe '<A, B>' si hay argumentos de tipo
+func writeTypeArguments(sb *strings.Builder, args []TypeNode) {
+	if len(args) == 0 {
+		return
+	}
+	sb.WriteByte('<')
+	writeTypeList(sb, args, ", ")
+	sb.WriteByte('>')
+}
+
+// writeTypeParameters escribe '<T extends X = Y, U>' si hay parámetros de tipo
+func writeTypeParameters(sb *strings.Builder, params []*TypeParameter) {
+	if len(params) == 0 {
+		return
+	}
+	sb.WriteByte('<')
+	for i, param := range params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(param.Name.Name)
+		if param.Constraint != nil {
+			sb.WriteString(" extends ")
+			writeType(sb, param.Constraint)
+		}
+		if param.Default != nil {
+			sb.WriteString(" = ")
+			writeType(sb, param.Default)
+		}
+	}
+	sb.WriteByte('>')
+}
+
+// writeParams escribe '(a, b = 1, ...c)' omitiendo las anotaciones de tipo
+func writeArguments(sb *strings.Builder, args []Expression) {
+	sb.WriteByte('(')
+	for i, arg := range args {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		writeExpr(sb, arg, precNone)
+	}
+	sb.WriteByte(')')
+}
+
+func writeParams(sb *strings.Builder, params []*Parameter) {
+	sb.WriteByte('(')
+	for i, param := range params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if param.isProperty() {
+			sb.WriteString(memberModifiers(param.Access, false, param.Readonly) + " ")
+		}
+		if param.Rest {
+			sb.WriteString("...")
+		}
+		sb.WriteString(param.displayName())
+		if param.Optional {
+			sb.WriteByte('?')
+		}
+		if param.TypeAnnotation != nil {
+			sb.WriteString(": ")
+			writeType(sb, param.TypeAnnotation)
+		}
+		writeDefault(sb, param.Default)
+	}
+	sb.WriteByte(')')
+}
+
+// memberModifiers escribe los modificadores de un miembro de clase en el
+// orden en que TypeScript los exige: 'private static readonly'.
+func memberModifiers(access string, static, readonly bool) string {
+	modifiers := make([]string, 0, 3)
+	if access != "" {
+		modifiers = append(modifiers, access)
+	}
+	if static {
+		modifiers = append(modifiers, "static")
+	}
+	if readonly {
+		modifiers = append(modifiers, "readonly")
+	}
+	return strings.Join(modifiers, " ")
+}
+
+func writeBinary(sb *strings.Builder, operator string, left, right Expression, parentPrec int) {
+	prec := operatorPrecedence(operator)
+	if prec < parentPrec {
+		sb.WriteByte('(')
+	}
+	leftPrec, rightPrec := prec, prec+1
+	if isRightAssociative(operator) {
+		leftPrec, rightPrec = prec+1, prec
+	}
+	writeExpr(sb, left, leftPrec)
+	sb.WriteString(" " + operator + " ")
+	writeExpr(sb, right, rightPrec)
+	if prec < parentPrec {
+		sb.WriteByte(')')
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"strconv"
+)
+
+// ASTNode es la representación serializable de un nodo del AST que se envía
+// al frontend para dibujar el árbol.
+type ASTNode struct {
+	Kind     string     `json:"kind"`
+	Detail   string     `json:"detail,omitempty"`
+	Start    Position   `json:"start"`
+	End      Position   `json:"end"`
+	Children []*ASTNode `json:"children"`
+}
+
+type ASTResponse struct {
+	AST         *ASTNode     `json:"ast"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// toASTNode convierte recursivamente un nodo del AST a su forma serializable
+func toASTNode(node Node) *ASTNode {
+	span := node.Span()
+	children := node.Children()
+
+	result := &ASTNode{
+		Kind:     node.Kind(),
+		Detail:   nodeDetail(node),
+		Start:    span.Start,
+		End:      span.End,
+		Children: make([]*ASTNode, 0, len(children)),
+	}
+	for _, child := range children {
+		result.Children = append(result.Children, toASTNode(child))
+	}
+	return result
+}
+
+// nodeDetail devuelve el dato relevante del nodo para mostrar junto a su tipo
+// (nombre, operador, valor literal...).
+func nodeDetail(node Node) string {
+	switch n := node.(type) {
+	case *VariableDeclaration:
+		return n.Keyword
+	case *Identifier:
+		return n.Name
+	case *NumericLiteral:
+		return n.Raw
+	case *StringLiteral:
+		return n.Raw
+	case *BooleanLiteral:
+		return strconv.FormatBool(n.Value)
+	case *BadExpression:
+		return n.Raw
+	case *BinaryExpression:
+		return n.Operator
+	case *LogicalExpression:
+		return n.Operator
+	case *UnaryExpression:
+		return n.Operator
+	case *AssignmentExpression:
+		return n.Operator
+	case *UpdateExpression:
+		return n.Operator
+	case *TypeReference:
+		return n.Name
+	case *Parameter:
+		detail := memberModifiers(n.Access, false, n.Readonly)
+		switch {
+		case n.Rest:
+			return detail + "..."
+		case n.Optional:
+			return detail + "?"
+		default:
+			return detail
+		}
+	case *PropertyDefinition:
+		detail := memberModifiers(n.Access, n.Static, n.Readonly)
+		if n.Optional {
+			detail += "?"
+		}
+		return detail
+	case *MethodDefinition:
+		return memberModifiers(n.Access, n.Static, false)
+	case *EnumDeclaration:
+		if n.Const {
+			return "const"
+		}
+		return ""
+	case *ModuleDeclaration:
+		return n.Keyword
+	case *PropertySignature:
+		detail := ""
+		if n.Readonly {
+			detail = "readonly"
+		}
+		if n.Optional {
+			detail += "?"
+		}
+		return detail
+	case *SwitchCase:
+		if n.Test == nil {
+			return "default"
+		}
+		return "case"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,118 @@
+package main
+
+import "sort"
+
+// builtinGlobals son los objetos y funciones globales que el analizador
+// conoce sin que el programa los declare. Sustituye a la antigua lista de
+// palabras reservadas: además de no reportarlos como no declarados, sus firmas
+// permiten comprobar las llamadas.
+var builtinGlobals = map[string]*Type{
+	"console": objectType("Console", map[string]*Type{
+		"log":   variadicFunction(voidType, "data", anyType),
+		"error": variadicFunction(voidType, "data", anyType),
+		"warn":  variadicFunction(voidType, "data", anyType),
+		"info":  variadicFunction(voidType, "data", anyType),
+		"debug": variadicFunction(voidType, "data", anyType),
+	}),
+	"Math": objectType("Math", map[string]*Type{
+		"PI":     numberType,
+		"E":      numberType,
+		"abs":    mathFunction("x"),
+		"ceil":   mathFunction("x"),
+		"floor":  mathFunction("x"),
+		"round":  mathFunction("x"),
+		"trunc":  mathFunction("x"),
+		"sign":   mathFunction("x"),
+		"sqrt":   mathFunction("x"),
+		"cbrt":   mathFunction("x"),
+		"exp":    mathFunction("x"),
+		"log":    mathFunction("x"),
+		"sin":    mathFunction("x"),
+		"cos":    mathFunction("x"),
+		"tan":    mathFunction("x"),
+		"pow":    mathFunction("x", "y"),
+		"atan2":  mathFunction("y", "x"),
+		"max":    variadicFunction(numberType, "values", numberType),
+		"min":    variadicFunction(numberType, "values", numberType),
+		"random": functionOf(numberType, 0),
+	}),
+	"parseInt":   functionOf(numberType, 1, param("string", stringType), param("radix", numberType)),
+	"parseFloat": functionOf(numberType, 1, param("string", stringType)),
+	"isNaN":      functionOf(booleanType, 1, param("number", numberType)),
+	"isFinite":   functionOf(booleanType, 1, param("number", numberType)),
+	"String":     functionOf(stringType, 0, param("value", anyType)),
+	"Number":     functionOf(numberType, 0, param("value", anyType)),
+	"Boolean":    functionOf(booleanType, 0, param("value", anyType)),
+	"NaN":        numberType,
+	"Infinity":   numberType,
+	"undefined":  undefinedType,
+	"null":       nullType,
+}
+
+// lengthMembers son los miembros que el analizador conoce de un string o un
+// array.
+var lengthMembers = &Type{Kind: KindObject, Properties: []*PropertyType{
+	{Name: "length", Type: numberType, Readonly: true},
+}}
+
+// noMembers es el objeto sin miembros conocidos de un número, un booleano o
+// una función.
+var noMembers = &Type{Kind: KindObject}
+
+// apparentMembers devuelve los miembros conocidos de un valor que no es un
+// objeto: lo que se compara al guardarlo en un tipo objeto, de modo que
+// '"abc"' encaja en '{ length: number }' y '5' en '{}'. Devuelve nil para
+// null, undefined y void, que no tienen miembros.
+func apparentMembers(t *Type) *Type {
+	switch t.Kind {
+	case KindString, KindArray:
+		return lengthMembers
+	case KindNumber, KindBigInt, KindBoolean, KindFunction:
+		return noMembers
+	default:
+		return nil
+	}
+}
+
+func param(name string, t *Type) SignatureParam {
+	return SignatureParam{Name: name, Type: t}
+}
+
+// functionOf construye el tipo de una función con 'required' parámetros
+// obligatorios; el resto de params son opcionales.
+func functionOf(returnType *Type, required int, params ...SignatureParam) *Type {
+	return &Type{Kind: KindFunction, Signature: &Signature{Params: params, Required: required, Return: returnType}}
+}
+
+// variadicFunction construye una función que acepta cualquier número de
+// argumentos del mismo tipo, como console.log o Math.max.
+func variadicFunction(returnType *Type, restName string, restType *Type) *Type {
+	rest := param(restName, restType)
+	return &Type{Kind: KindFunction, Signature: &Signature{Rest: &rest, Return: returnType}}
+}
+
+// mathFunction construye una función de Math con parámetros numéricos
+// obligatorios que devuelve un número.
+func mathFunction(names ...string) *Type {
+	params := make([]SignatureParam, len(names))
+	for i, name := range names {
+		params[i] = param(name, numberType)
+	}
+	return functionOf(numberType, len(params), params...)
+}
+
+// objectType construye un objeto global con miembros de solo lectura,
+// ordenados por nombre.
+func objectType(name string, properties map[string]*Type) *Type {
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := &Type{Kind: KindObject, Name: name}
+	for _, name := range names {
+		t.Properties = append(t.Properties, &PropertyType{Name: name, Type: properties[name], Readonly: true})
+	}
+	return t
+}
@@ -0,0 +1,668 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CFG es el grafo de flujo de control de una función o del código de nivel
+// superior del programa. Los bloques 'entry' y 'exit' no contienen código:
+// marcan dónde empieza la ejecución y adónde llegan el final y los 'return'.
+type CFG struct {
+	Name   string        `json:"name"`
+	Kind   string        `json:"kind"` // program, function, method o arrow
+	Start  Position      `json:"start"`
+	End    Position      `json:"end"`
+	Entry  int           `json:"entry"`
+	Exit   int           `json:"exit"`
+	Blocks []*BasicBlock `json:"blocks"`
+	Edges  []*CFGEdge    `json:"edges"`
+	Node   Node          `json:"-"` // *Program o la función
+}
+
+// BasicBlock es una secuencia de sentencias que siempre se ejecutan seguidas.
+// Si el bloque termina en una bifurcación, Condition es la expresión que
+// decide por qué arista se sale.
+type BasicBlock struct {
+	ID         int      `json:"id"`
+	Kind       string   `json:"kind"` // entry, exit o block
+	Line       int      `json:"line,omitempty"`
+	Statements []string `json:"statements"`
+	Condition  string   `json:"condition,omitempty"`
+	Reachable  bool     `json:"reachable"` // se puede llegar desde la entrada
+	Nodes      []Node   `json:"-"`         // sentencias y condición, en orden
+}
+
+// CFGEdge es un posible paso de un bloque a otro. Back marca las aristas que
+// vuelven al principio de un bucle.
+type CFGEdge struct {
+	From  int    `json:"from"`
+	To    int    `json:"to"`
+	Kind  string `json:"kind"`            // next, true, false, case, default, no-match, break, continue o return
+	Label string `json:"label,omitempty"` // valor de un 'case'
+	Back  bool   `json:"back,omitempty"`
+	from  *BasicBlock
+	to    *BasicBlock
+}
+
+type CFGResponse struct {
+	Graphs      []*CFG       `json:"graphs"`
+	Dot         string       `json:"dot"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// BuildControlFlow construye el grafo del programa y el de cada función,
+// método o función flecha que contiene, en orden de aparición.
+func BuildControlFlow(program *Program) []*CFG {
+	graphs := []*CFG{buildCFG("programa", "program", program, program.Body, nil)}
+
+	// Las funciones anónimas toman el nombre de la variable o el método
+	names := make(map[Node]string)
+	Inspect(program, func(n Node) bool {
+		switch n := n.(type) {
+		case *VariableDeclarator:
+			if n.Name != nil && isFunctionNode(n.Init) {
+				names[n.Init] = n.Name.Name
+			}
+		case *ClassDeclaration:
+			for _, member := range n.Members {
+				if method, ok := member.(*MethodDefinition); ok && n.Name != nil && method.Name != nil {
+					names[method.Function] = n.Name.Name + "." + method.Name.Name
+				}
+			}
+		}
+		if isFunctionNode(n) {
+			graphs = append(graphs, functionCFG(n, names[n]))
+		}
+		return true
+	})
+	return graphs
+}
+
+// functionCFG construye el grafo del cuerpo de una función. El de una función
+// flecha con una expresión como cuerpo es un único 'return'.
+func functionCFG(fn Node, name string) *CFG {
+	kind := "function"
+	switch f := fn.(type) {
+	case *FunctionDeclaration:
+		if f.Name != nil {
+			name = f.Name.Name
+		}
+	case *FunctionExpression:
+		if strings.Contains(name, ".") {
+			kind = "method"
+		} else if name == "" && f.Name != nil {
+			name = f.Name.Name
+		}
+	case *ArrowFunction:
+		kind = "arrow"
+	}
+	if name == "" {
+		name = "anónima (línea " + strconv.Itoa(fn.Span().Start.Line) + ")"
+	}
+
+	_, _, _, body := functionParts(fn)
+	switch body := body.(type) {
+	case *BlockStatement:
+		return buildCFG(name, kind, fn, body.Body, nil)
+	case Expression:
+		return buildCFG(name, kind, fn, nil, body)
+	default:
+		return buildCFG(name, kind, fn, nil, nil)
+	}
+}
+
+// cfgTarget es adónde saltan 'break' y 'continue' dentro de un bucle o un
+// switch; el switch no tiene destino para 'continue'.
+type cfgTarget struct {
+	breakTo    *BasicBlock
+	continueTo *BasicBlock
+}
+
+type cfgBuilder struct {
+	graph   *CFG
+	blocks  []*BasicBlock
+	edges   []*CFGEdge
+	entry   *BasicBlock
+	exit    *BasicBlock
+	current *BasicBlock
+	targets []cfgTarget
+}
+
+// buildCFG construye el grafo de una lista de sentencias o, si result no es
+// nil, de una función flecha que devuelve esa expresión.
+func buildCFG(name, kind string, node Node, body []Statement, result Expression) *CFG {
+	span := node.Span()
+	b := &cfgBuilder{graph: &CFG{Name: name, Kind: kind, Start: span.Start, End: span.End, Node: node}}
+	b.entry = b.newBlock("entry")
+	b.exit = b.newBlock("exit")
+	b.current = b.newBlock("block")
+	b.link(b.entry, b.current, "next")
+
+	if result != nil {
+		b.add(result, "return "+exprString(result))
+		b.jump(b.exit, "return")
+	}
+	b.statements(body)
+	b.link(b.current, b.exit, "next")
+
+	b.simplify()
+	b.finish()
+	return b.graph
+}
+
+func (b *cfgBuilder) newBlock(kind string) *BasicBlock {
+	block := &BasicBlock{Kind: kind, Statements: []string{}}
+	b.blocks = append(b.blocks, block)
+	return block
+}
+
+func (b *cfgBuilder) link(from, to *BasicBlock, kind string) *CFGEdge {
+	edge := &CFGEdge{Kind: kind, from: from, to: to}
+	b.edges = append(b.edges, edge)
+	return edge
+}
+
+// add añade una sentencia al bloque actual. Con código incompleto el parser
+// deja huecos (nil) que no se añaden.
+func (b *cfgBuilder) add(node Node, text string) {
+	if node == nil {
+		return
+	}
+	b.current.Nodes = append(b.current.Nodes, node)
+	b.current.Statements = append(b.current.Statements, text)
+}
+
+// branch termina el bloque actual con una condición
+func (b *cfgBuilder) branch(test Expression) *BasicBlock {
+	block := b.current
+	if test != nil {
+		block.Nodes = append(block.Nodes, test)
+	}
+	block.Condition = exprString(test)
+	return block
+}
+
+// jump termina el bloque actual con un salto. Lo que venga después queda en
+// un bloque sin predecesores: código inalcanzable.
+func (b *cfgBuilder) jump(to *BasicBlock, kind string) {
+	b.link(b.current, to, kind)
+	b.current = b.newBlock("block")
+}
+
+// loopHead abre un bloque nuevo para la condición de un bucle, al que vuelve
+// el final del cuerpo.
+func (b *cfgBuilder) loopHead() *BasicBlock {
+	head := b.newBlock("block")
+	b.link(b.current, head, "next")
+	b.current = head
+	return head
+}
+
+func (b *cfgBuilder) statements(list []Statement) {
+	for _, stmt := range list {
+		b.statement(stmt)
+	}
+}
+
+func (b *cfgBuilder) statement(stmt Statement) {
+	switch s := stmt.(type) {
+	case nil:
+	case *BlockStatement:
+		b.statements(s.Body)
+	case *ExportDeclaration:
+		b.statement(s.Declaration)
+	case *ModuleDeclaration:
+		// El cuerpo de un namespace se ejecuta en el momento de declararlo
+		b.add(s, statementLabel(s))
+		if s.Body != nil {
+			b.statements(s.Body.Body)
+		}
+	case *IfStatement:
+		b.ifStatement(s)
+	case *WhileStatement:
+		b.whileStatement(s)
+	case *DoWhileStatement:
+		b.doWhileStatement(s)
+	case *ForStatement:
+		b.forStatement(s)
+	case *ForInStatement:
+		b.forEachStatement(s.Left, "in", s.Right, s.Body)
+	case *ForOfStatement:
+		b.forEachStatement(s.Left, "of", s.Right, s.Body)
+	case *SwitchStatement:
+		b.switchStatement(s)
+	case *BreakStatement:
+		b.add(s, "break")
+		if target := b.target(false); target != nil {
+			b.jump(target, "break")
+		}
+	case *ContinueStatement:
+		b.add(s, "continue")
+		if target := b.target(true); target != nil {
+			b.jump(target, "continue")
+		}
+	case *ReturnStatement:
+		b.add(s, statementLabel(s))
+		b.jump(b.exit, "return")
+	default:
+		b.add(s, statementLabel(s))
+	}
+}
+
+// target busca el destino de un 'break' o un 'continue' en los bucles y
+// switch que lo rodean; nil si está fuera de todos.
+func (b *cfgBuilder) target(isContinue bool) *BasicBlock {
+	for i := len(b.targets) - 1; i >= 0; i-- {
+		if !isContinue {
+			return b.targets[i].breakTo
+		}
+		if b.targets[i].continueTo != nil {
+			return b.targets[i].continueTo
+		}
+	}
+	return nil
+}
+
+// loopBody construye el cuerpo de un bucle con sus destinos de 'break' y
+// 'continue', y enlaza su final con next; back indica si así vuelve a la
+// cabecera.
+func (b *cfgBuilder) loopBody(body Statement, breakTo, continueTo, next *BasicBlock, back bool) {
+	b.targets = append(b.targets, cfgTarget{breakTo: breakTo, continueTo: continueTo})
+	b.statement(body)
+	b.targets = b.targets[:len(b.targets)-1]
+	b.link(b.current, next, "next").Back = back
+}
+
+func (b *cfgBuilder) ifStatement(s *IfStatement) {
+	cond := b.branch(s.Test)
+	after := b.newBlock("block")
+
+	b.current = b.newBlock("block")
+	b.link(cond, b.current, "true")
+	b.statement(s.Consequent)
+	b.link(b.current, after, "next")
+
+	if s.Alternate != nil {
+		b.current = b.newBlock("block")
+		b.link(cond, b.current, "false")
+		b.statement(s.Alternate)
+		b.link(b.current, after, "next")
+	} else {
+		b.link(cond, after, "false")
+	}
+	b.current = after
+}
+
+func (b *cfgBuilder) whileStatement(s *WhileStatement) {
+	head := b.loopHead()
+	after := b.newBlock("block")
+	b.condition(head, s.Test, after)
+	b.loopBody(s.Body, after, head, head, true)
+	b.current = after
+}
+
+func (b *cfgBuilder) doWhileStatement(s *DoWhileStatement) {
+	body := b.loopHead()
+	test := b.newBlock("block")
+	after := b.newBlock("block")
+	b.loopBody(s.Body, after, test, test, false)
+
+	b.current = test
+	if s.Test == nil {
+		// 'do' sin 'while': ya se reporta como error, el cuerpo se ejecuta una vez
+		b.link(test, after, "next")
+	} else {
+		b.branch(s.Test)
+		b.link(test, body, "true").Back = true
+		if !isAlwaysTrue(s.Test) {
+			b.link(test, after, "false")
+		}
+	}
+	b.current = after
+}
+
+// forStatement enlaza 'for (Init; Test; Update)': Init se ejecuta una vez,
+// Test al principio de cada vuelta y Update al final, también tras un
+// 'continue'.
+func (b *cfgBuilder) forStatement(s *ForStatement) {
+	switch init := s.Init.(type) {
+	case nil:
+	case Expression:
+		b.add(init, exprString(init))
+	case Statement:
+		b.add(init, statementLabel(init))
+	}
+	head := b.loopHead()
+	after := b.newBlock("block")
+	b.condition(head, s.Test, after)
+
+	next := head
+	var update *BasicBlock
+	if s.Update != nil {
+		update = b.newBlock("block")
+		next = update
+	}
+	b.loopBody(s.Body, after, next, next, update == nil)
+	if update != nil {
+		update.Nodes = append(update.Nodes, s.Update)
+		update.Statements = append(update.Statements, exprString(s.Update))
+		b.link(update, head, "next").Back = true
+	}
+	b.current = after
+}
+
+// forEachStatement enlaza un 'for...of' o 'for...in': la cabecera toma el
+// siguiente elemento o sale del bucle cuando no quedan.
+func (b *cfgBuilder) forEachStatement(left Node, operator string, right Expression, body Statement) {
+	head := b.loopHead()
+	after := b.newBlock("block")
+	b.branch(right).Condition = forEachLeftString(left) + " " + operator + " " + exprString(right)
+
+	b.current = b.newBlock("block")
+	b.link(head, b.current, "true")
+	b.link(head, after, "false")
+	b.loopBody(body, after, head, head, true)
+	b.current = after
+}
+
+// condition termina la cabecera de un bucle con su condición. Sin condición o
+// con 'true' el bucle solo sale con 'break' o 'return'.
+func (b *cfgBuilder) condition(head *BasicBlock, test Expression, after *BasicBlock) {
+	kind := "next"
+	if test != nil {
+		b.branch(test)
+		kind = "true"
+	}
+	b.current = b.newBlock("block")
+	b.link(head, b.current, kind)
+	if test != nil && !isAlwaysTrue(test) {
+		b.link(head, after, "false")
+	}
+}
+
+// switchStatement enlaza cada 'case' desde el discriminante. Sin 'break' un
+// caso continúa en el siguiente; sin 'default' el switch puede no entrar en
+// ninguno.
+func (b *cfgBuilder) switchStatement(s *SwitchStatement) {
+	head := b.branch(s.Discriminant)
+	after := b.newBlock("block")
+	b.targets = append(b.targets, cfgTarget{breakTo: after})
+
+	var previous *BasicBlock
+	hasDefault := false
+	for _, c := range s.Cases {
+		block := b.newBlock("block")
+		if c.Test == nil {
+			hasDefault = true
+			b.link(head, block, "default")
+		} else {
+			b.link(head, block, "case").Label = exprString(c.Test)
+		}
+		if previous != nil {
+			b.link(previous, block, "next")
+		}
+		b.current = block
+		b.statements(c.Consequent)
+		previous = b.current
+	}
+	if previous != nil {
+		b.link(previous, after, "next")
+	}
+	if !hasDefault {
+		// Sin 'default', si ningún 'case' coincide se sale del switch
+		b.link(head, after, "no-match")
+	}
+
+	b.targets = b.targets[:len(b.targets)-1]
+	b.current = after
+}
+
+// simplify quita los bloques vacíos que el recorrido deja sueltos: los que no
+// tienen predecesores y los que solo pasan al siguiente.
+func (b *cfgBuilder) simplify() {
+	for changed := true; changed; {
+		changed = false
+		for _, block := range b.blocks {
+			if block.Kind != "block" || len(block.Nodes) > 0 {
+				continue
+			}
+			in, out := b.edgesOf(block)
+			switch {
+			case len(in) == 0:
+				b.removeBlock(block)
+				changed = true
+			case len(out) == 1 && out[0].Kind == "next" && out[0].to != block:
+				for _, edge := range in {
+					edge.to = out[0].to
+					edge.Back = edge.Back || out[0].Back
+				}
+				b.removeBlock(block)
+				changed = true
+			}
+			if changed {
+				break
+			}
+		}
+	}
+}
+
+// edgesOf devuelve las aristas que llegan al bloque y las que salen de él
+func (b *cfgBuilder) edgesOf(block *BasicBlock) (in, out []*CFGEdge) {
+	for _, edge := range b.edges {
+		if edge.to == block {
+			in = append(in, edge)
+		}
+		if edge.from == block {
+			out = append(out, edge)
+		}
+	}
+	return in, out
+}
+
+func (b *cfgBuilder) removeBlock(block *BasicBlock) {
+	blocks := b.blocks[:0]
+	for _, other := range b.blocks {
+		if other != block {
+			blocks = append(blocks, other)
+		}
+	}
+	b.blocks = blocks
+
+	edges := b.edges[:0]
+	for _, edge := range b.edges {
+		if edge.from != block && edge.to != block {
+			edges = append(edges, edge)
+		}
+	}
+	b.edges = edges
+}
+
+// finish numera los bloques, marca los alcanzables desde la entrada y pasa
+// las aristas a índices.
+func (b *cfgBuilder) finish() {
+	for i, block := range b.blocks {
+		block.ID = i
+		if len(block.Nodes) > 0 {
+			block.Line = block.Nodes[0].Span().Start.Line
+		}
+	}
+	for _, edge := range b.edges {
+		edge.From, edge.To = edge.from.ID, edge.to.ID
+	}
+
+	pending := []*BasicBlock{b.entry}
+	b.entry.Reachable = true
+	for len(pending) > 0 {
+		block := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		for _, edge := range b.edges {
+			if edge.from == block && !edge.to.Reachable {
+				edge.to.Reachable = true
+				pending = append(pending, edge.to)
+			}
+		}
+	}
+
+	b.graph.Entry, b.graph.Exit = b.entry.ID, b.exit.ID
+	b.graph.Blocks, b.graph.Edges = b.blocks, b.edges
+}
+
+// isAlwaysTrue indica si la condición es el literal 'true'
+func isAlwaysTrue(test Expression) bool {
+	lit, ok := test.(*BooleanLiteral)
+	return ok && lit.Value
+}
+
+// forEachLeftString escribe la variable de un 'for...of' o 'for...in'
+func forEachLeftString(left Node) string {
+	switch left := left.(type) {
+	case *VariableDeclaration:
+		names := make([]string, 0, len(left.Declarations))
+		for _, declarator := range left.Declarations {
+			names = append(names, declarator.displayName())
+		}
+		return left.Keyword + " " + strings.Join(names, ", ")
+	case Expression:
+		return exprString(left)
+	default:
+		return ""
+	}
+}
+
+// statementLabel resume una sentencia en una línea para los bloques del grafo
+func statementLabel(stmt Node) string {
+	switch s := stmt.(type) {
+	case *VariableDeclaration:
+		parts := make([]string, 0, len(s.Declarations))
+		for _, declarator := range s.Declarations {
+			part := declarator.displayName()
+			if declarator.Init != nil {
+				part += " = " + exprString(declarator.Init)
+			}
+			parts = append(parts, part)
+		}
+		return s.Keyword + " " + strings.Join(parts, ", ")
+	case *ExpressionStatement:
+		return exprString(s.Expression)
+	case *ReturnStatement:
+		if s.Argument == nil {
+			return "return"
+		}
+		return "return " + exprString(s.Argument)
+	case *FunctionDeclaration:
+		var sb strings.Builder
+		sb.WriteString("function ")
+		if s.Name != nil {
+			sb.WriteString(s.Name.Name)
+		}
+		writeParams(&sb, s.Params)
+		return sb.String()
+	case *ClassDeclaration:
+		return "class " + identifierName(s.Name)
+	case *InterfaceDeclaration:
+		return "interface " + identifierName(s.Name)
+	case *TypeAliasDeclaration:
+		return "type " + identifierName(s.Name)
+	case *EnumDeclaration:
+		return "enum " + identifierName(s.Name)
+	case *ModuleDeclaration:
+		return s.Keyword + " " + identifierName(s.Name)
+	default:
+		return stmt.Kind()
+	}
+}
+
+func identifierName(id *Identifier) string {
+	if id == nil {
+		return ""
+	}
+	return id.Name
+}
+
+// Dot escribe el grafo en formato Graphviz como un cluster de un digraph
+func (g *CFG) Dot(index int) string {
+	var sb strings.Builder
+	prefix := "g" + strconv.Itoa(index) + "_"
+	sb.WriteString("  subgraph cluster_" + strconv.Itoa(index) + " {\n")
+	label := g.Kind + " " + g.Name
+	if g.Kind == "program" {
+		label = g.Name
+	}
+	sb.WriteString("    label=" + dotQuote(label) + ";\n")
+	for _, block := range g.Blocks {
+		sb.WriteString("    " + prefix + strconv.Itoa(block.ID) + " [" + blockAttributes(block) + "];\n")
+	}
+	for _, edge := range g.Edges {
+		sb.WriteString("    " + prefix + strconv.Itoa(edge.From) + " -> " + prefix + strconv.Itoa(edge.To))
+		var attributes []string
+		switch edge.Kind {
+		case "next":
+		case "case":
+			attributes = append(attributes, "label="+dotQuote("case "+edge.Label))
+		default:
+			attributes = append(attributes, "label="+dotQuote(edge.Kind))
+		}
+		if edge.Back {
+			attributes = append(attributes, "style=bold")
+		}
+		if len(attributes) > 0 {
+			sb.WriteString(" [" + strings.Join(attributes, ", ") + "]")
+		}
+		sb.WriteString(";\n")
+	}
+	sb.WriteString("  }\n")
+	return sb.String()
+}
+
+// blockAttributes da la etiqueta y la forma de un bloque: óvalo para la
+// entrada y la salida, rombo para una condición sola y gris discontinuo para
+// el código inalcanzable.
+func blockAttributes(block *BasicBlock) string {
+	switch block.Kind {
+	case "entry":
+		return "label=\"entrada\", shape=oval"
+	case "exit":
+		return "label=\"salida\", shape=oval"
+	}
+	// '\l' termina cada línea alineada a la izquierda
+	var label strings.Builder
+	for _, line := range block.Statements {
+		label.WriteString(dotEscape(line) + `\l`)
+	}
+	if block.Condition != "" {
+		label.WriteString(dotEscape(block.Condition+" ?") + `\l`)
+	}
+
+	attributes := "label=\"" + label.String() + "\""
+	if len(block.Statements) == 0 && block.Condition != "" {
+		attributes += ", shape=diamond"
+	}
+	if !block.Reachable {
+		attributes += ", style=dashed, color=gray"
+	}
+	return attributes
+}
+
+// dotQuote escribe un string entre comillas para Graphviz
+func dotQuote(text string) string {
+	return `"` + dotEscape(text) + `"`
+}
+
+// dotEscape escapa las comillas y barras de un texto para Graphviz
+func dotEscape(text string) string {
+	text = strings.ReplaceAll(text, `\`, `\\`)
+	return strings.ReplaceAll(text, `"`, `\"`)
+}
+
+// ControlFlowDot escribe todos los grafos en un único digraph de Graphviz
+func ControlFlowDot(graphs []*CFG) string {
+	var sb strings.Builder
+	sb.WriteString("digraph cfg {\n")
+	sb.WriteString("  node [shape=box, fontname=\"monospace\"];\n")
+	for i, graph := range graphs {
+		sb.WriteString(graph.Dot(i))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
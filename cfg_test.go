@@ -0,0 +1,34 @@
+package main
+
+import "testing"
+
+// Con código incompleto el parser deja condiciones y expresiones a nil; el
+// grafo debe construirse igualmente, sin ellas.
+func TestControlFlowIncompleteCode(t *testing.T) {
+	for _, code := range []string{
+		"if (",
+		"for (const x of",
+		"while (",
+		"switch (",
+	} {
+		program, _ := NewParser(NewLexer(code).Tokenize()).Parse()
+		graphs := BuildControlFlow(program)
+		if len(graphs) == 0 {
+			t.Errorf("%q: no se construyó ningún grafo", code)
+		}
+		for _, graph := range graphs {
+			for _, block := range graph.Blocks {
+				for _, node := range block.Nodes {
+					if node == nil {
+						t.Errorf("%q: el bloque %d contiene un nodo nil", code, block.ID)
+					}
+				}
+			}
+		}
+		ControlFlowDot(graphs)
+
+		if result := analyzeSource(code); result.IsValid {
+			t.Errorf("%q: se esperaba un código no válido", code)
+		}
+	}
+}
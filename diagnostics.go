@@ -0,0 +1,228 @@
+package main
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// Phase indica qué etapa del análisis produjo el diagnóstico
+type Phase string
+
+const (
+	PhaseLexical  Phase = "lexical"
+	PhaseSyntax   Phase = "syntax"
+	PhaseSemantic Phase = "semantic"
+)
+
+// Códigos estables de diagnóstico: los clientes filtran por ellos en lugar de
+// interpretar el texto del mensaje.
+const (
+	// Léxicos
+	codeMalformedNumber      = "malformed-number"
+	codeUnterminatedComment  = "unterminated-comment"
+	codeUnterminatedTemplate = "unterminated-template"
+
+	// Sintácticos
+	codeUnexpectedEOF           = "unexpected-eof"
+	codeUnexpectedToken         = "unexpected-token"
+	codeInvalidToken            = "invalid-token"
+	codeExpectedExpression      = "expected-expression"
+	codeExpectedIdentifier      = "expected-identifier"
+	codeExpectedType            = "expected-type"
+	codeMissingOperator         = "missing-operator"
+	codeMissingSemicolon        = "missing-semicolon"
+	codeInvalidAssignmentTarget = "invalid-assignment-target"
+	codeDeclarationNotAllowed   = "declaration-not-allowed"
+	codeJumpOutsideLoop         = "jump-outside-loop"
+	codeReturnOutsideFunction   = "return-outside-function"
+	codeDuplicateConstructor    = "duplicate-constructor"
+	codeMissingInitializer      = "missing-initializer"
+
+	// Semánticos
+	codeUndeclaredVariable     = "undeclared-variable"
+	codeRedeclaredVariable     = "redeclared-variable"
+	codeVariableOutOfScope     = "variable-out-of-scope"
+	codeConstReassignment      = "const-reassignment"
+	codeUnusedVariable         = "unused-variable"
+	codeDoWithoutWhile         = "do-without-while"
+	codeLoopVariableMismatch   = "loop-variable-mismatch"
+	codeLoopConditionNeverTrue = "loop-condition-never-true"
+	codeLoopMissingCondition   = "loop-missing-condition"
+	codeLoopMissingUpdate      = "loop-missing-update"
+	codePossibleInfiniteLoop   = "possible-infinite-loop"
+	codeLoopNeverTerminates    = "loop-never-terminates"
+	codeLoopAnalysis           = "loop-analysis"
+	codeUnreachableCode        = "unreachable-code"
+	codeMissingReturn          = "missing-return"
+	codeUsedBeforeDeclaration  = "used-before-declaration"
+	codeUsedBeforeAssigned     = "used-before-assigned"
+	codeTypeMismatch           = "type-mismatch"
+	codeInvalidOperands        = "invalid-operands"
+	codeUnknownType            = "unknown-type"
+	codeUnknownProperty        = "unknown-property"
+	codeNotCallable            = "not-callable"
+	codeArgumentCount          = "argument-count"
+	codeInaccessibleMember     = "inaccessible-member"
+	codeReadonlyAssignment     = "readonly-assignment"
+	codeMissingMember          = "missing-member"
+	codeMissingSuperCall       = "missing-super-call"
+	codeInvalidSuper           = "invalid-super"
+	codeUnknownEnumMember      = "unknown-enum-member"
+	codeInvalidEnumInitializer = "invalid-enum-initializer"
+	codeConstEnumValue         = "const-enum-value"
+	codeNotExported            = "not-exported"
+	codeTypeArgumentCount      = "type-argument-count"
+	codeTypeConstraint         = "type-constraint"
+
+	// Pipeline no optimizado: sus mensajes de texto no tienen código propio
+	codeUnoptimizedMessage = "unoptimized-message"
+)
+
+// RelatedLocation señala otro punto del código relacionado con el
+// diagnóstico (por ejemplo, la declaración original de una variable).
+type RelatedLocation struct {
+	Message string   `json:"message"`
+	Start   Position `json:"start"`
+	End     Position `json:"end"`
+}
+
+type Diagnostic struct {
+	Code     string            `json:"code"`
+	Severity Severity          `json:"severity"`
+	Phase    Phase             `json:"phase"`
+	Message  string            `json:"message"`
+	Start    Position          `json:"start"`
+	End      Position          `json:"end"`
+	Related  []RelatedLocation `json:"related,omitempty"`
+}
+
+func newDiagnostic(phase Phase, severity Severity, code string, r Range, message string) Diagnostic {
+	return Diagnostic{
+		Code:     code,
+		Severity: severity,
+		Phase:    phase,
+		Message:  message,
+		Start:    r.Start,
+		End:      r.End,
+	}
+}
+
+// withRelated devuelve una copia del diagnóstico con una ubicación relacionada
+func (d Diagnostic) withRelated(r Range, message string) Diagnostic {
+	d.Related = append(d.Related, RelatedLocation{Message: message, Start: r.Start, End: r.End})
+	return d
+}
+
+// String conserva el formato de texto que usaban syntaxErrors y semanticInfo
+// antes de existir los diagnósticos estructurados.
+func (d Diagnostic) String() string {
+	prefix := "⚠️ ADVERTENCIA: "
+	if d.Severity == SeverityError {
+		switch d.Phase {
+		case PhaseLexical:
+			prefix = "❌ ERROR LÉXICO: "
+		case PhaseSyntax:
+			prefix = "❌ ERROR SINTÁCTICO: "
+		default:
+			prefix = "❌ ERROR SEMÁNTICO: "
+		}
+	} else if d.Severity == SeverityInfo {
+		prefix = "ℹ️ "
+	}
+	return prefix + d.Message + " (línea " + strconv.Itoa(d.Start.Line) +
+		", columna " + strconv.Itoa(d.Start.Column) + ")"
+}
+
+// diagnosticsFromMessages convierte los mensajes de texto del pipeline no
+// optimizado en diagnósticos. La fase y la gravedad salen del prefijo
+// ('❌ ERROR LÉXICO: ', '⚠️ ADVERTENCIA: '...); los mensajes sin prefijo son
+// errores de la fase fallback o, si fallback está vacía, información que se
+// descarta. La posición se toma de 'línea N' y 'columna M' cuando aparecen.
+func diagnosticsFromMessages(messages []string, fallback Phase) []Diagnostic {
+	prefixes := []struct {
+		text     string
+		phase    Phase
+		severity Severity
+	}{
+		{"❌ ERROR LÉXICO: ", PhaseLexical, SeverityError},
+		{"❌ ERROR SINTÁCTICO: ", PhaseSyntax, SeverityError},
+		{"❌ ERROR SEMÁNTICO: ", PhaseSemantic, SeverityError},
+		{"⚠️ ADVERTENCIA: ", PhaseSemantic, SeverityWarning},
+		{"⚠️ ", PhaseSemantic, SeverityWarning},
+		{"ERROR: ", fallback, SeverityError},
+	}
+
+	diagnostics := make([]Diagnostic, 0, len(messages))
+	for _, message := range messages {
+		phase, severity := fallback, SeverityError
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(message, prefix.text) {
+				phase, severity = prefix.phase, prefix.severity
+				message = strings.TrimPrefix(message, prefix.text)
+				break
+			}
+		}
+		if phase == "" {
+			continue
+		}
+		position := Position{Line: messageNumber(message, "línea "), Column: messageNumber(message, "columna ")}
+		diagnostics = append(diagnostics, newDiagnostic(phase, severity, codeUnoptimizedMessage,
+			Range{Start: position, End: position}, message))
+	}
+	return diagnostics
+}
+
+// messageNumber devuelve el primer número que sigue a label en el mensaje,
+// o 0 si no hay ninguno ('salto de línea' no cuenta como 'línea N').
+func messageNumber(message, label string) int {
+	for i := strings.Index(message, label); i >= 0; {
+		digits := message[i+len(label):]
+		end := strings.IndexFunc(digits, func(r rune) bool { return r < '0' || r > '9' })
+		if end < 0 {
+			end = len(digits)
+		}
+		if n, err := strconv.Atoi(digits[:end]); err == nil {
+			return n
+		}
+		next := strings.Index(digits, label)
+		if next < 0 {
+			break
+		}
+		i += len(label) + next
+	}
+	return 0
+}
+
+func hasErrors(diagnostics []Diagnostic) bool {
+	for i := range diagnostics {
+		if diagnostics[i].Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// sortDiagnostics ordena por posición en el código manteniendo el orden de
+// emisión entre diagnósticos del mismo punto.
+func sortDiagnostics(diagnostics []Diagnostic) {
+	sort.SliceStable(diagnostics, func(i, j int) bool {
+		return diagnostics[i].Start.Offset < diagnostics[j].Start.Offset
+	})
+}
+
+func diagnosticStrings(diagnostics []Diagnostic) []string {
+	messages := make([]string, 0, len(diagnostics))
+	for i := range diagnostics {
+		messages = append(messages, diagnostics[i].String())
+	}
+	return messages
+}
@@ -7,26 +7,51 @@ import (
 type TokenType string
 
 const (
-	FOR         TokenType = "FOR"
-	DO          TokenType = "DO"
-	WHILE       TokenType = "WHILE"
-	IDENTIFIER  TokenType = "IDENTIFIER"
-	NUMBER      TokenType = "NUMBER"
-	OPERATOR    TokenType = "OPERATOR"
-	LPAREN      TokenType = "LPAREN"
-	RPAREN      TokenType = "RPAREN"
-	LBRACE      TokenType = "LBRACE"
-	RBRACE      TokenType = "RBRACE"
-	SEMICOLON   TokenType = "SEMICOLON"
-	COLON       TokenType = "COLON"
-	STRING      TokenType = "STRING"
-	KEYWORD     TokenType = "KEYWORD"
-	COMPARISON  TokenType = "COMPARISON"
-	INCREMENT   TokenType = "INCREMENT"
-	ASSIGNMENT  TokenType = "ASSIGNMENT"
-	TYPE        TokenType = "TYPE"
-	WHITESPACE  TokenType = "WHITESPACE"
-	UNKNOWN     TokenType = "UNKNOWN"
+	FOR             TokenType = "FOR"
+	DO              TokenType = "DO"
+	WHILE           TokenType = "WHILE"
+	IF              TokenType = "IF"
+	ELSE            TokenType = "ELSE"
+	SWITCH          TokenType = "SWITCH"
+	CASE            TokenType = "CASE"
+	DEFAULT         TokenType = "DEFAULT"
+	BREAK           TokenType = "BREAK"
+	CONTINUE        TokenType = "CONTINUE"
+	RETURN          TokenType = "RETURN"
+	FUNCTION        TokenType = "FUNCTION"
+	IDENTIFIER      TokenType = "IDENTIFIER"
+	NUMBER          TokenType = "NUMBER"
+	OPERATOR        TokenType = "OPERATOR"
+	LPAREN          TokenType = "LPAREN"
+	RPAREN          TokenType = "RPAREN"
+	LBRACE          TokenType = "LBRACE"
+	RBRACE          TokenType = "RBRACE"
+	LBRACKET        TokenType = "LBRACKET"
+	RBRACKET        TokenType = "RBRACKET"
+	SEMICOLON       TokenType = "SEMICOLON"
+	COLON           TokenType = "COLON"
+	COMMA           TokenType = "COMMA"
+	DOT             TokenType = "DOT"
+	QUESTION        TokenType = "QUESTION"
+	QUESTION_DOT    TokenType = "QUESTION_DOT"
+	ARROW           TokenType = "ARROW"
+	ELLIPSIS        TokenType = "ELLIPSIS"
+	STRING          TokenType = "STRING"
+	// Tramos de un template literal: `texto`, `texto${, }texto${ y }texto`
+	TEMPLATE        TokenType = "TEMPLATE"
+	TEMPLATE_HEAD   TokenType = "TEMPLATE_HEAD"
+	TEMPLATE_MIDDLE TokenType = "TEMPLATE_MIDDLE"
+	TEMPLATE_TAIL   TokenType = "TEMPLATE_TAIL"
+	KEYWORD         TokenType = "KEYWORD"
+	COMPARISON      TokenType = "COMPARISON"
+	INCREMENT       TokenType = "INCREMENT"
+	ASSIGNMENT      TokenType = "ASSIGNMENT"
+	LOGICAL         TokenType = "LOGICAL"
+	BOOLEAN         TokenType = "BOOLEAN"
+	TYPE            TokenType = "TYPE"
+	WHITESPACE      TokenType = "WHITESPACE"
+	COMMENT         TokenType = "COMMENT"
+	UNKNOWN         TokenType = "UNKNOWN"
 )
 
 type Token struct {
@@ -38,30 +63,57 @@ type Token struct {
 }
 
 type Lexer struct {
-	input    string
-	position int
-	line     int
-	column   int
+	input        string
+	position     int
+	line         int
+	column       int
+	keepComments bool // emitir los comentarios como tokens COMMENT
+	// templateBraces guarda, por cada '${' abierto, cuántas llaves '{' siguen
+	// abiertas dentro de la expresión; la '}' que lo deja en cero cierra la
+	// sustitución y reanuda el texto del template.
+	templateBraces []int
+	diagnostics    []Diagnostic
 }
 
 // Mapa global estático para máximo rendimiento
 var keywords = map[string]TokenType{
-	"for":     FOR,
-	"do":      DO,
-	"while":   WHILE,
-	"let":     KEYWORD,
-	"const":   KEYWORD,
-	"var":     KEYWORD,
-	"int":     TYPE,
-	"string":  TYPE,
-	"number":  TYPE,
-	"boolean": TYPE,
-	"console": KEYWORD,
+	"for":       FOR,
+	"do":        DO,
+	"while":     WHILE,
+	"if":        IF,
+	"else":      ELSE,
+	"switch":    SWITCH,
+	"case":      CASE,
+	"default":   DEFAULT,
+	"break":     BREAK,
+	"continue":  CONTINUE,
+	"return":    RETURN,
+	"function":  FUNCTION,
+	"let":       KEYWORD,
+	"const":     KEYWORD,
+	"var":       KEYWORD,
+	"interface": KEYWORD,
+	"class":     KEYWORD,
+	"new":       KEYWORD,
+	"this":      KEYWORD,
+	"super":     KEYWORD,
+	"enum":      KEYWORD,
+	"export":    KEYWORD,
+	"int":       TYPE,
+	"string":    TYPE,
+	"number":    TYPE,
+	"boolean":   TYPE,
+	"console":   KEYWORD,
+	"true":      BOOLEAN,
+	"false":     BOOLEAN,
 }
 
-// Arrays estáticos para operadores de múltiples caracteres (máxima eficiencia)
-var twoCharOps = [...]string{"<=", ">=", "==", "!=", "++", "--", "+=", "-="}
-var threeCharOps = [...]string{"===", "!=="}
+// Arrays estáticos para operadores de múltiples caracteres (máxima eficiencia).
+// Se prueban de mayor a menor longitud para quedarse siempre con el más largo.
+var fourCharOps = [...]string{">>>="}
+var threeCharOps = [...]string{"===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=", "..."}
+var twoCharOps = [...]string{"<=", ">=", "==", "!=", "++", "--", "+=", "-=", "*=", "/=", "%=", "&=", "|=",
+	"^=", "&&", "||", "??", "?.", "=>", "**", "<<", ">>"}
 
 func NewLexer(input string) *Lexer {
 	return &Lexer{
@@ -72,6 +124,24 @@ func NewLexer(input string) *Lexer {
 	}
 }
 
+// NewLexerWithComments crea un lexer que conserva los comentarios como tokens
+// COMMENT (trivia) para que el cliente pueda mostrarlos; el parser los descarta.
+func NewLexerWithComments(input string) *Lexer {
+	lexer := NewLexer(input)
+	lexer.keepComments = true
+	return lexer
+}
+
+// Diagnostics devuelve los errores léxicos detectados durante Tokenize
+func (l *Lexer) Diagnostics() []Diagnostic {
+	return l.diagnostics
+}
+
+func (l *Lexer) addDiagnostic(code string, token Token, message string) {
+	r := Range{Start: tokenStart(&token), End: tokenEnd(&token)}
+	l.diagnostics = append(l.diagnostics, newDiagnostic(PhaseLexical, SeverityError, code, r, message))
+}
+
 func (l *Lexer) Tokenize() []Token {
 	// Pre-allocar slice con capacidad estimada para evitar re-allocaciones
 	tokens := make([]Token, 0, len(l.input)/4)
@@ -83,7 +153,7 @@ func (l *Lexer) Tokenize() []Token {
 		switch {
 		case unicode.IsSpace(rune(char)):
 			l.consumeWhitespace()
-		case unicode.IsDigit(rune(char)):
+		case unicode.IsDigit(rune(char)) || l.atLeadingDotNumber():
 			tokens = append(tokens, l.consumeNumber())
 		case unicode.IsLetter(rune(char)):
 			token := l.consumeIdentifier()
@@ -94,8 +164,19 @@ func (l *Lexer) Tokenize() []Token {
 			tokens = append(tokens, token)
 		case char == '"' || char == '\'':
 			tokens = append(tokens, l.consumeString())
+		case char == '`':
+			tokens = append(tokens, l.consumeTemplate())
+		case char == '}' && l.closesSubstitution():
+			tokens = append(tokens, l.consumeTemplate())
+		case l.atCommentStart():
+			comment := l.consumeComment()
+			if l.keepComments {
+				tokens = append(tokens, comment)
+			}
 		default:
-			tokens = append(tokens, l.consumeOperatorOrSymbol())
+			token := l.consumeOperatorOrSymbol()
+			l.trackTemplateBraces(token)
+			tokens = append(tokens, token)
 		}
 	}
 	
@@ -118,42 +199,148 @@ func (l *Lexer) consumeWhitespace() {
 	}
 }
 
-func (l *Lexer) consumeNumber() Token {
+func (l *Lexer) atCommentStart() bool {
+	if l.input[l.position] != '/' || l.position+1 >= len(l.input) {
+		return false
+	}
+	next := l.input[l.position+1]
+	return next == '/' || next == '*'
+}
+
+// consumeComment consume un comentario '// ...' hasta el fin de línea o un
+// comentario '/* ... */' completo, actualizando línea y columna en cada salto.
+func (l *Lexer) consumeComment() Token {
 	start := l.position
+	startLine := l.line
 	startCol := l.column
-	
-	// Optimización: avanzar directamente sin conversiones innecesarias
+	block := l.input[l.position+1] == '*'
+	l.position += 2
+	l.column += 2
+
+	terminated := !block
 	for l.position < len(l.input) {
 		char := l.input[l.position]
-		if !unicode.IsDigit(rune(char)) && char != '.' {
+		if !block && char == '\n' {
+			break
+		}
+		if block && char == '*' && l.position+1 < len(l.input) && l.input[l.position+1] == '/' {
+			l.position += 2
+			l.column += 2
+			terminated = true
 			break
 		}
+		if char == '\n' {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
 		l.position++
-		l.column++
 	}
-	
-	// Verificar si hay letras después (error)
-	if l.position < len(l.input) && unicode.IsLetter(rune(l.input[l.position])) {
-		for l.position < len(l.input) && (unicode.IsLetter(rune(l.input[l.position])) || unicode.IsDigit(rune(l.input[l.position]))) {
+
+	token := Token{
+		Type:     COMMENT,
+		Value:    l.input[start:l.position],
+		Position: start,
+		Line:     startLine,
+		Column:   startCol,
+	}
+	if !terminated {
+		l.addDiagnostic(codeUnterminatedComment, token, "Comentario de bloque sin cerrar: falta '*/'")
+	}
+	return token
+}
+
+// closesSubstitution indica si la '}' actual cierra un '${' de template
+func (l *Lexer) closesSubstitution() bool {
+	depth := len(l.templateBraces)
+	return depth > 0 && l.templateBraces[depth-1] == 0
+}
+
+// trackTemplateBraces cuenta las llaves de objetos y bloques que aparecen
+// dentro de una sustitución para no confundir su '}' con la de cierre.
+func (l *Lexer) trackTemplateBraces(token Token) {
+	depth := len(l.templateBraces)
+	if depth == 0 {
+		return
+	}
+	switch token.Type {
+	case LBRACE:
+		l.templateBraces[depth-1]++
+	case RBRACE:
+		l.templateBraces[depth-1]--
+	}
+}
+
+// consumeTemplate consume un tramo de texto de template literal empezando en
+// '`' (inicio) o en la '}' que cierra una sustitución, hasta la siguiente '`'
+// o '${'. Las expresiones de '${...}' se tokenizan como código normal, por lo
+// que pueden contener otros templates.
+func (l *Lexer) consumeTemplate() Token {
+	start := l.position
+	startLine := l.line
+	startCol := l.column
+	continuation := l.input[l.position] == '}'
+	if continuation {
+		l.templateBraces = l.templateBraces[:len(l.templateBraces)-1]
+	}
+	l.position++
+	l.column++
+
+	for l.position < len(l.input) {
+		char := l.input[l.position]
+		switch {
+		case char == '\\' && l.position+1 < len(l.input):
+			l.advanceTemplateChar()
+			l.advanceTemplateChar()
+			continue
+		case char == '`':
 			l.position++
 			l.column++
+			tokenType := TEMPLATE
+			if continuation {
+				tokenType = TEMPLATE_TAIL
+			}
+			return l.templateToken(tokenType, start, startLine, startCol)
+		case char == '$' && l.position+1 < len(l.input) && l.input[l.position+1] == '{':
+			l.position += 2
+			l.column += 2
+			l.templateBraces = append(l.templateBraces, 0)
+			tokenType := TEMPLATE_HEAD
+			if continuation {
+				tokenType = TEMPLATE_MIDDLE
+			}
+			return l.templateToken(tokenType, start, startLine, startCol)
 		}
-		
-		return Token{
-			Type:     UNKNOWN,
-			Value:    l.input[start:l.position], // Slicing directo - máxima eficiencia
-			Position: start,
-			Line:     l.line,
-			Column:   startCol,
-		}
+		l.advanceTemplateChar()
 	}
-	
+
+	tokenType := TEMPLATE
+	if continuation {
+		tokenType = TEMPLATE_TAIL
+	}
+	token := l.templateToken(tokenType, start, startLine, startCol)
+	l.addDiagnostic(codeUnterminatedTemplate, token, "Template literal sin cerrar: falta '`'")
+	return token
+}
+
+func (l *Lexer) advanceTemplateChar() {
+	if l.input[l.position] == '\n' {
+		l.line++
+		l.column = 1
+	} else {
+		l.column++
+	}
+	l.position++
+}
+
+func (l *Lexer) templateToken(tokenType TokenType, start, line, column int) Token {
 	return Token{
-		Type:     NUMBER,
-		Value:    l.input[start:l.position], // Slicing directo
+		Type:     tokenType,
+		Value:    l.input[start:l.position],
 		Position: start,
-		Line:     l.line,
-		Column:   startCol,
+		Line:     line,
+		Column:   column,
 	}
 }
 
@@ -216,39 +403,23 @@ func (l *Lexer) consumeOperatorOrSymbol() Token {
 	start := l.position
 	startCol := l.column
 	
-	// Optimización: verificar operadores de 3 caracteres primero con array estático
-	if l.position+2 < len(l.input) {
-		threeChar := l.input[l.position : l.position+3]
-		for _, op := range threeCharOps {
-			if threeChar == op {
-				l.position += 3
-				l.column += 3
-				return Token{
-					Type:     COMPARISON,
-					Value:    threeChar,
-					Position: start,
-					Line:     l.line,
-					Column:   startCol,
-				}
-			}
-		}
+	// Verificar operadores de 4, 3 y 2 caracteres con arrays estáticos
+	op := l.matchOperator(fourCharOps[:], 4)
+	if op == "" {
+		op = l.matchOperator(threeCharOps[:], 3)
 	}
-	
-	// Verificar operadores de 2 caracteres con array estático
-	if l.position+1 < len(l.input) {
-		twoChar := l.input[l.position : l.position+2]
-		for _, op := range twoCharOps {
-			if twoChar == op {
-				l.position += 2
-				l.column += 2
-				return Token{
-					Type:     getOperatorType(twoChar),
-					Value:    twoChar,
-					Position: start,
-					Line:     l.line,
-					Column:   startCol,
-				}
-			}
+	if op == "" {
+		op = l.matchOperator(twoCharOps[:], 2)
+	}
+	if op != "" {
+		l.position += len(op)
+		l.column += len(op)
+		return Token{
+			Type:     getOperatorType(op),
+			Value:    op,
+			Position: start,
+			Line:     l.line,
+			Column:   startCol,
 		}
 	}
 	
@@ -267,6 +438,24 @@ func (l *Lexer) consumeOperatorOrSymbol() Token {
 	}
 }
 
+func (l *Lexer) matchOperator(ops []string, size int) string {
+	if l.position+size > len(l.input) {
+		return ""
+	}
+	candidate := l.input[l.position : l.position+size]
+	for _, op := range ops {
+		if candidate != op {
+			continue
+		}
+		// 'a?.5:b' es un ternario con el número '.5', no un encadenamiento opcional
+		if op == "?." && l.position+2 < len(l.input) && unicode.IsDigit(rune(l.input[l.position+2])) {
+			return ""
+		}
+		return op
+	}
+	return ""
+}
+
 // Funciones optimizadas con switch statements
 func getOperatorType(op string) TokenType {
 	switch op {
@@ -274,8 +463,16 @@ func getOperatorType(op string) TokenType {
 		return COMPARISON
 	case "++", "--":
 		return INCREMENT
-	case "+=", "-=", "=":
+	case "=", "+=", "-=", "*=", "/=", "%=", "**=", "&=", "|=", "^=", "<<=", ">>=", ">>>=", "&&=", "||=", "??=":
 		return ASSIGNMENT
+	case "&&", "||", "!", "??":
+		return LOGICAL
+	case "?.":
+		return QUESTION_DOT
+	case "=>":
+		return ARROW
+	case "...":
+		return ELLIPSIS
 	default:
 		return OPERATOR
 	}
@@ -291,17 +488,29 @@ func getSingleCharType(char byte) TokenType {
 		return LBRACE
 	case '}':
 		return RBRACE
+	case '[':
+		return LBRACKET
+	case ']':
+		return RBRACKET
 	case ';':
 		return SEMICOLON
 	case ':':
 		return COLON
+	case ',':
+		return COMMA
+	case '.':
+		return DOT
+	case '?':
+		return QUESTION
 	case '=':
 		return ASSIGNMENT
 	case '<', '>':
 		return COMPARISON
-	case '+', '-', '*', '/':
+	case '+', '-', '*', '/', '%', '&', '|', '^', '~':
 		return OPERATOR
+	case '!':
+		return LOGICAL
 	default:
 		return UNKNOWN
 	}
-}
\ No newline at end of file
+}
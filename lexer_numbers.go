@@ -0,0 +1,201 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Gramática de literales numéricos de TypeScript: decimales ('10', '1.5',
+// '.5', '1e-3'), hexadecimales ('0x1F'), octales ('0o17'), binarios ('0b101'),
+// separadores ('1_000') y sufijo BigInt ('10n').
+
+// consumeNumber consume un literal numérico. Si no es válido devuelve un token
+// UNKNOWN con todo el fragmento mal formado y registra el motivo concreto.
+func (l *Lexer) consumeNumber() Token {
+	start := l.position
+	startCol := l.column
+
+	problem := l.scanNumber()
+	if problem == "" && l.position < len(l.input) && isIdentifierChar(l.input[l.position]) {
+		problem = "tiene letras pegadas sin operador"
+	}
+
+	if problem != "" {
+		// Consumir el resto del fragmento para no generar tokens sueltos
+		for l.position < len(l.input) && (isIdentifierChar(l.input[l.position]) || l.input[l.position] == '.') {
+			l.advanceNumber()
+		}
+		token := Token{
+			Type:     UNKNOWN,
+			Value:    l.input[start:l.position],
+			Position: start,
+			Line:     l.line,
+			Column:   startCol,
+		}
+		l.addDiagnostic(codeMalformedNumber, token, "Número mal formado '"+token.Value+"': "+problem)
+		return token
+	}
+
+	return Token{
+		Type:     NUMBER,
+		Value:    l.input[start:l.position], // Slicing directo
+		Position: start,
+		Line:     l.line,
+		Column:   startCol,
+	}
+}
+
+// atLeadingDotNumber detecta decimales sin parte entera como '.5'
+func (l *Lexer) atLeadingDotNumber() bool {
+	return l.input[l.position] == '.' && unicode.IsDigit(rune(l.peekNumber(1)))
+}
+
+// scanNumber avanza sobre el literal y devuelve una descripción del problema,
+// o "" si el literal es válido.
+func (l *Lexer) scanNumber() string {
+	if l.peekNumber(0) == '0' {
+		if base, name := numberPrefix(l.peekNumber(1)); base != 0 {
+			prefix := l.input[l.position : l.position+2]
+			l.advanceNumber()
+			l.advanceNumber()
+			count, problem := l.scanDigits(func(c byte) bool { return isDigitInBase(c, base) })
+			if problem != "" {
+				return problem
+			}
+			if count == 0 {
+				return "faltan dígitos después de '" + prefix + "'"
+			}
+			if c := l.peekNumber(0); unicode.IsDigit(rune(c)) {
+				return "el dígito '" + string(c) + "' no es válido en un número " + name
+			}
+			if l.peekNumber(0) == 'n' {
+				l.advanceNumber()
+			}
+			return ""
+		}
+		if unicode.IsDigit(rune(l.peekNumber(1))) {
+			return "los números con cero inicial no están permitidos (para octales usa '0o')"
+		}
+	}
+
+	isDecimalDigit := func(c byte) bool { return c >= '0' && c <= '9' }
+	integral := true
+
+	if _, problem := l.scanDigits(isDecimalDigit); problem != "" {
+		return problem
+	}
+	if l.peekNumber(0) == '.' {
+		integral = false
+		l.advanceNumber()
+		if _, problem := l.scanDigits(isDecimalDigit); problem != "" {
+			return problem
+		}
+		if l.peekNumber(0) == '.' && unicode.IsDigit(rune(l.peekNumber(1))) {
+			return "tiene más de un punto decimal"
+		}
+	}
+	if c := l.peekNumber(0); c == 'e' || c == 'E' {
+		integral = false
+		l.advanceNumber()
+		if c := l.peekNumber(0); c == '+' || c == '-' {
+			l.advanceNumber()
+		}
+		count, problem := l.scanDigits(isDecimalDigit)
+		if problem != "" {
+			return problem
+		}
+		if count == 0 {
+			return "el exponente no tiene dígitos"
+		}
+	}
+	if l.peekNumber(0) == 'n' {
+		if !integral {
+			return "un BigInt no puede tener parte decimal ni exponente"
+		}
+		l.advanceNumber()
+	}
+	return ""
+}
+
+// scanDigits consume dígitos válidos y separadores '_'; cada separador debe
+// estar entre dos dígitos.
+func (l *Lexer) scanDigits(valid func(byte) bool) (int, string) {
+	count := 0
+	for l.position < len(l.input) {
+		c := l.input[l.position]
+		if c == '_' {
+			if count == 0 || !valid(l.peekNumber(1)) {
+				return count, "el separador '_' solo puede ir entre dos dígitos"
+			}
+			l.advanceNumber()
+			continue
+		}
+		if !valid(c) {
+			break
+		}
+		count++
+		l.advanceNumber()
+	}
+	return count, ""
+}
+
+func (l *Lexer) peekNumber(offset int) byte {
+	if l.position+offset >= len(l.input) {
+		return 0
+	}
+	return l.input[l.position+offset]
+}
+
+func (l *Lexer) advanceNumber() {
+	l.position++
+	l.column++
+}
+
+func numberPrefix(c byte) (int, string) {
+	switch c {
+	case 'x', 'X':
+		return 16, "hexadecimal"
+	case 'o', 'O':
+		return 8, "octal"
+	case 'b', 'B':
+		return 2, "binario"
+	default:
+		return 0, ""
+	}
+}
+
+func isDigitInBase(c byte, base int) bool {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c-'0') < base
+	case base == 16:
+		return (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+	default:
+		return false
+	}
+}
+
+func isIdentifierChar(c byte) bool {
+	return unicode.IsLetter(rune(c)) || unicode.IsDigit(rune(c)) || c == '_' || c == '$'
+}
+
+// numericValue convierte el texto de un literal ya validado por el lexer en
+// su valor; ok es false si no cabe en un float64 (por ejemplo un hexadecimal
+// enorme).
+func numericValue(raw string) (float64, bool) {
+	clean := strings.TrimSuffix(strings.ReplaceAll(raw, "_", ""), "n")
+	if len(clean) > 1 && clean[0] == '0' {
+		if base, _ := numberPrefix(clean[1]); base != 0 {
+			value, err := strconv.ParseUint(clean[2:], base, 64)
+			return float64(value), err == nil
+		}
+	}
+	value, err := strconv.ParseFloat(clean, 64)
+	return value, err == nil
+}
+
+// isBigIntLiteral indica si el literal lleva el sufijo 'n'
+func isBigIntLiteral(raw string) bool {
+	return strings.HasSuffix(raw, "n")
+}
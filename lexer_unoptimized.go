@@ -1,6 +1,7 @@
 package main
 
 import (
+	"strconv"
 	"unicode"
 )
 
@@ -12,6 +13,7 @@ type LexerUnoptimized struct {
 	position int
 	line     int
 	column   int
+	errors   []string
 }
 
 func NewLexerUnoptimized(input string) *LexerUnoptimized {
@@ -52,6 +54,12 @@ func (l *LexerUnoptimized) TokenizeUnoptimized() []Token {
 			continue
 		}
 		
+		// Comentarios - versión ineficiente
+		if l.isCommentStartUnoptimized() {
+			l.consumeCommentUnoptimized()
+			continue
+		}
+		
 		// Strings - versión ineficiente
 		if l.input[l.position] == '"' || l.input[l.position] == '\'' {
 			token := l.consumeStringUnoptimized()
@@ -67,6 +75,59 @@ func (l *LexerUnoptimized) TokenizeUnoptimized() []Token {
 	return tokens
 }
 
+// ErrorsUnoptimized devuelve los errores léxicos (comentarios sin cerrar)
+func (l *LexerUnoptimized) ErrorsUnoptimized() []string {
+	return l.errors
+}
+
+func (l *LexerUnoptimized) isCommentStartUnoptimized() bool {
+	// Ineficiente: crear substrings para comparar en lugar de mirar los bytes
+	if l.position+1 >= len(l.input) {
+		return false
+	}
+	twoChars := string(l.input[l.position]) + string(l.input[l.position+1])
+	return twoChars == "/"+"/" || twoChars == "/"+"*"
+}
+
+func (l *LexerUnoptimized) consumeCommentUnoptimized() {
+	startLine := l.line
+	startCol := l.column
+	
+	// Ineficiente: acumular el comentario completo aunque se descarta
+	comment := string(l.input[l.position]) + string(l.input[l.position+1])
+	isBlock := comment == "/"+"*"
+	l.position += 2
+	l.column += 2
+	
+	for l.position < len(l.input) {
+		char := string(l.input[l.position])
+		if !isBlock && char == "\n" {
+			return
+		}
+		if isBlock && char == "*" && l.position+1 < len(l.input) && string(l.input[l.position+1]) == "/" {
+			comment = comment + "*" + "/"
+			l.position += 2
+			l.column += 2
+			return
+		}
+		comment = comment + char
+		if char == "\n" {
+			l.line++
+			l.column = 1
+		} else {
+			l.column++
+		}
+		l.position++
+	}
+	
+	if isBlock {
+		// Ineficiente: construir el mensaje con conversiones repetidas
+		message := "❌ ERROR LÉXICO: " + "Comentario de bloque sin cerrar: falta '*/'" +
+			" (línea " + strconv.Itoa(startLine) + ", columna " + strconv.Itoa(startCol) + ")"
+		l.errors = append(l.errors, message)
+	}
+}
+
 // Crear keywords map de forma ineficiente (en cada llamada)
 func (l *LexerUnoptimized) createKeywordsMapInefficiently() map[string]TokenType {
 	// Ineficiente: crear el map cada vez en lugar de usar una variable global
@@ -6,7 +6,6 @@ import (
 	"log"
 	"net/http"
 	"runtime"
-	"strings"
 	"time"
 
 	"github.com/gorilla/handlers"
@@ -18,10 +17,12 @@ type AnalysisRequest struct {
 }
 
 type AnalysisResponse struct {
-	IsValid      bool     `json:"isValid"`
-	Tokens       []Token  `json:"tokens"`
-	SyntaxErrors []string `json:"syntaxErrors"`
-	SemanticInfo []string `json:"semanticInfo"`
+	IsValid      bool         `json:"isValid"`
+	Tokens       []Token      `json:"tokens"`
+	Diagnostics  []Diagnostic `json:"diagnostics"`
+	SyntaxErrors []string     `json:"syntaxErrors"`
+	SemanticInfo []string     `json:"semanticInfo"`
+	Loops        []LoopReport `json:"loops"`
 }
 
 type PerformanceMetrics struct {
@@ -48,6 +49,8 @@ func main() {
 	
 	// Endpoints existentes
 	r.HandleFunc("/analyze", analyzeHandler).Methods("POST")
+	r.HandleFunc("/ast", astHandler).Methods("POST")
+	r.HandleFunc("/cfg", cfgHandler).Methods("POST")
 	
 	// Nuevos endpoints para comparación de rendimiento
 	r.HandleFunc("/analyze-optimized", analyzeOptimizedHandler).Methods("POST")
@@ -56,13 +59,43 @@ func main() {
 	fmt.Println("Servidor iniciado en puerto 8080")
 	fmt.Println("Endpoints disponibles:")
 	fmt.Println("  POST /analyze - Análisis existente")
+	fmt.Println("  POST /ast - Árbol de sintaxis abstracta (AST) en JSON")
+	fmt.Println("  POST /cfg - Grafos de flujo de control en JSON (?format=dot para Graphviz)")
 	fmt.Println("  POST /analyze-optimized - Análisis optimizado con métricas")
 	fmt.Println("  POST /analyze-unoptimized - Análisis NO optimizado con métricas")
 	
 	log.Fatal(http.ListenAndServe(":8080", handlers.CORS(headers, methods, origins)(r)))
 }
 
-// Handler existente sin cambios
+// analyzeSource ejecuta el pipeline optimizado (léxico, sintáctico y
+// semántico) y reúne los diagnósticos de las tres fases ordenados por posición.
+func analyzeSource(code string) AnalysisResponse {
+	// Análisis léxico (los comentarios se devuelven como tokens COMMENT)
+	lexer := NewLexerWithComments(code)
+	tokens := lexer.Tokenize()
+	
+	// Análisis sintáctico
+	parser := NewParser(tokens)
+	program, syntaxDiagnostics := parser.Parse()
+	
+	// Análisis semántico sobre el AST
+	semantic := NewSemantic(program)
+	semanticInfo := semantic.Analyze()
+	
+	syntaxErrors := append(append([]Diagnostic{}, lexer.Diagnostics()...), syntaxDiagnostics...)
+	diagnostics := append(append([]Diagnostic{}, syntaxErrors...), semantic.Diagnostics()...)
+	sortDiagnostics(diagnostics)
+	
+	return AnalysisResponse{
+		IsValid:      !hasErrors(diagnostics),
+		Tokens:       tokens,
+		Diagnostics:  diagnostics,
+		SyntaxErrors: diagnosticStrings(syntaxErrors),
+		SemanticInfo: semanticInfo,
+		Loops:        semantic.Loops(),
+	}
+}
+
 func analyzeHandler(w http.ResponseWriter, r *http.Request) {
 	var req AnalysisRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -70,32 +103,63 @@ func analyzeHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	
-	// Análisis léxico
+	response := analyzeSource(req.Code)
+	
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Handler que devuelve el AST construido por el parser
+func astHandler(w http.ResponseWriter, r *http.Request) {
+	var req AnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
+	}
+	
 	lexer := NewLexer(req.Code)
 	tokens := lexer.Tokenize()
 	
-	// Análisis sintáctico
 	parser := NewParser(tokens)
-	syntaxErrors := parser.Parse()
+	program, syntaxDiagnostics := parser.Parse()
 	
-	// Análisis semántico
-	semantic := NewSemantic(tokens)
-	semanticInfo := semantic.Analyze()
+	response := ASTResponse{
+		AST:         toASTNode(program),
+		Diagnostics: append(append([]Diagnostic{}, lexer.Diagnostics()...), syntaxDiagnostics...),
+	}
 	
-	// Verificar si hay errores semánticos
-	hasSemanticErrors := false
-	for _, info := range semanticInfo {
-		if strings.Contains(info, "❌ ERROR SEMÁNTICO") || strings.Contains(info, "❌ ERROR SINTÁCTICO") || strings.Contains(info, "❌ ERROR LÉXICO") {
-			hasSemanticErrors = true
-			break
-		}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// Handler que devuelve el grafo de flujo de control del programa y de cada
+// función, en JSON o, con '?format=dot', en formato Graphviz
+func cfgHandler(w http.ResponseWriter, r *http.Request) {
+	var req AnalysisRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Error al decodificar JSON", http.StatusBadRequest)
+		return
 	}
 	
-	response := AnalysisResponse{
-		IsValid:      len(syntaxErrors) == 0 && !hasSemanticErrors,
-		Tokens:       tokens,
-		SyntaxErrors: syntaxErrors,
-		SemanticInfo: semanticInfo,
+	lexer := NewLexer(req.Code)
+	tokens := lexer.Tokenize()
+	
+	parser := NewParser(tokens)
+	program, syntaxDiagnostics := parser.Parse()
+	
+	graphs := BuildControlFlow(program)
+	dot := ControlFlowDot(graphs)
+	
+	if r.URL.Query().Get("format") == "dot" {
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		fmt.Fprint(w, dot)
+		return
+	}
+	
+	response := CFGResponse{
+		Graphs:      graphs,
+		Dot:         dot,
+		Diagnostics: append(append([]Diagnostic{}, lexer.Diagnostics()...), syntaxDiagnostics...),
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
@@ -118,17 +182,8 @@ func analyzeOptimizedHandler(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
 	startCPU := time.Now()
 	
-	// Análisis léxico optimizado (código existente)
-	lexer := NewLexer(req.Code)
-	tokens := lexer.Tokenize()
-	
-	// Análisis sintáctico optimizado (código existente)
-	parser := NewParser(tokens)
-	syntaxErrors := parser.Parse()
-	
-	// Análisis semántico optimizado (código existente)
-	semantic := NewSemantic(tokens)
-	semanticInfo := semantic.Analyze()
+	// Pipeline optimizado completo (léxico, sintáctico y semántico)
+	analysis := analyzeSource(req.Code)
 	
 	// Medir métricas después del análisis
 	executionTime := time.Since(startTime)
@@ -137,15 +192,6 @@ func analyzeOptimizedHandler(w http.ResponseWriter, r *http.Request) {
 	runtime.GC()
 	runtime.ReadMemStats(&m2)
 	
-	// Verificar si hay errores semánticos
-	hasSemanticErrors := false
-	for _, info := range semanticInfo {
-		if strings.Contains(info, "❌ ERROR SEMÁNTICO") || strings.Contains(info, "❌ ERROR SINTÁCTICO") || strings.Contains(info, "❌ ERROR LÉXICO") {
-			hasSemanticErrors = true
-			break
-		}
-	}
-	
 	metrics := PerformanceMetrics{
 		ExecutionTime:   executionTime.String(),
 		MemoryUsage:     fmt.Sprintf("%.2f KB", float64(m2.Alloc-m1.Alloc)/1024),
@@ -156,13 +202,8 @@ func analyzeOptimizedHandler(w http.ResponseWriter, r *http.Request) {
 	}
 	
 	response := AnalysisWithMetrics{
-		AnalysisResponse: AnalysisResponse{
-			IsValid:      len(syntaxErrors) == 0 && !hasSemanticErrors,
-			Tokens:       tokens,
-			SyntaxErrors: syntaxErrors,
-			SemanticInfo: semanticInfo,
-		},
-		Metrics: metrics,
+		AnalysisResponse: analysis,
+		Metrics:          metrics,
 	}
 	
 	w.Header().Set("Content-Type", "application/json")
@@ -191,7 +232,7 @@ func analyzeUnoptimizedHandler(w http.ResponseWriter, r *http.Request) {
 	
 	// Análisis sintáctico NO optimizado
 	parserUnoptimized := NewParserUnoptimized(tokens)
-	syntaxErrors := parserUnoptimized.ParseUnoptimized()
+	syntaxErrors := append(lexerUnoptimized.ErrorsUnoptimized(), parserUnoptimized.ParseUnoptimized()...)
 	
 	// Análisis semántico NO optimizado
 	semanticUnoptimized := NewSemanticUnoptimized(tokens)
@@ -204,14 +245,9 @@ func analyzeUnoptimizedHandler(w http.ResponseWriter, r *http.Request) {
 	runtime.GC()
 	runtime.ReadMemStats(&m2)
 	
-	// Verificar si hay errores semánticos
-	hasSemanticErrors := false
-	for _, info := range semanticInfo {
-		if strings.Contains(info, "❌ ERROR SEMÁNTICO") || strings.Contains(info, "❌ ERROR SINTÁCTICO") || strings.Contains(info, "❌ ERROR LÉXICO") {
-			hasSemanticErrors = true
-			break
-		}
-	}
+	// Los mensajes de texto se convierten en diagnósticos para que la respuesta
+	// tenga la misma forma que la de los demás endpoints
+	diagnostics := append(diagnosticsFromMessages(syntaxErrors, PhaseSyntax), diagnosticsFromMessages(semanticInfo, "")...)
 	
 	metrics := PerformanceMetrics{
 		ExecutionTime:   executionTime.String(),
@@ -224,10 +260,12 @@ func analyzeUnoptimizedHandler(w http.ResponseWriter, r *http.Request) {
 	
 	response := AnalysisWithMetrics{
 		AnalysisResponse: AnalysisResponse{
-			IsValid:      len(syntaxErrors) == 0 && !hasSemanticErrors,
+			IsValid:      !hasErrors(diagnostics),
 			Tokens:       tokens,
+			Diagnostics:  diagnostics,
 			SyntaxErrors: syntaxErrors,
 			SemanticInfo: semanticInfo,
+			Loops:        []LoopReport{},
 		},
 		Metrics: metrics,
 	}
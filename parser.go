@@ -1,13 +1,23 @@
 package main
 
-import (
-	"strconv"
-)
-
 type Parser struct {
 	tokens   []Token
 	position int
-	errors   []string
+	errors   []Diagnostic
+
+	// Anidamiento actual, para validar 'break', 'continue', 'return' y 'export'
+	loopDepth     int
+	switchDepth   int
+	functionDepth int
+	blockDepth    int
+
+	// Los parámetros que se analizan son los de un constructor y admiten
+	// modificadores como 'private x: number'
+	parameterProperties bool
+
+	// Se analiza la variable de un 'for...of' o 'for...in', donde 'of' e 'in'
+	// terminan la expresión
+	forEachHead bool
 }
 
 // Pool de strings para reutilizar mensajes de error comunes
@@ -20,42 +30,62 @@ var (
 )
 
 func NewParser(tokens []Token) *Parser {
-	// Pre-filtrar tokens de whitespace una sola vez
+	// Pre-filtrar tokens de whitespace y comentarios una sola vez
 	filteredTokens := make([]Token, 0, len(tokens))
 	for i := range tokens {
-		if tokens[i].Type != WHITESPACE {
+		if tokens[i].Type != WHITESPACE && tokens[i].Type != COMMENT {
 			filteredTokens = append(filteredTokens, tokens[i])
 		}
 	}
-	
+
 	return &Parser{
 		tokens:   filteredTokens,
 		position: 0,
-		errors:   make([]string, 0, 4), // Pre-allocar con capacidad estimada
+		errors:   make([]Diagnostic, 0, 4), // Pre-allocar con capacidad estimada
 	}
 }
 
-func (p *Parser) Parse() []string {
+// Parse construye el AST del programa completo y devuelve además los errores
+// sintácticos encontrados. Ante un error el parser se resincroniza en la
+// siguiente sentencia para seguir reportando problemas.
+func (p *Parser) Parse() (*Program, []Diagnostic) {
+	program := &Program{Body: make([]Statement, 0, 8)}
+
 	for p.position < len(p.tokens) {
-		token := &p.tokens[p.position]
-		
-		switch token.Type {
-		case FOR:
-			p.parseForStatement()
-		case DO:
-			p.parseDoWhileStatement()
-		case KEYWORD, TYPE:
-			p.parseVariableDeclaration()
-		default:
+		start := p.position
+		if stmt := p.parseStatement(); stmt != nil {
+			program.Body = append(program.Body, stmt)
+		}
+		// Garantizar progreso aunque la sentencia no haya consumido tokens
+		if p.position == start {
 			p.position++
 		}
 	}
-	return p.errors
+
+	if len(p.tokens) > 0 {
+		program.Loc = Range{
+			Start: tokenStart(&p.tokens[0]),
+			End:   tokenEnd(&p.tokens[len(p.tokens)-1]),
+		}
+	}
+	return program, p.errors
+}
+
+// addError registra un error sintáctico sobre el token indicado; con token
+// nil el error se ubica al final del código.
+func (p *Parser) addError(code string, token *Token, message string) {
+	var r Range
+	if token != nil {
+		r = Range{Start: tokenStart(token), End: tokenEnd(token)}
+	} else if len(p.tokens) > 0 {
+		end := tokenEnd(&p.tokens[len(p.tokens)-1])
+		r = Range{Start: end, End: end}
+	}
+	p.addErrorRange(code, r, message)
 }
 
-// Función inline optimizada para agregar errores
-func (p *Parser) addError(message string) {
-	p.errors = append(p.errors, message)
+func (p *Parser) addErrorRange(code string, r Range, message string) {
+	p.errors = append(p.errors, newDiagnostic(PhaseSyntax, SeverityError, code, r, message))
 }
 
 // Función optimizada para obtener token actual
@@ -70,307 +100,667 @@ func (p *Parser) currentToken() *Token {
 func (p *Parser) consume(expectedType TokenType) bool {
 	token := p.currentToken()
 	if token == nil {
-		p.addError(errorEOF)
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba "+string(expectedType))
 		return false
 	}
-	
+
 	if token.Type != expectedType {
-		// Usar strconv optimizado para convertir números
-		p.addError("Se esperaba " + string(expectedType) + " pero se encontró " + string(token.Type) + 
-			" '" + token.Value + "' en línea " + strconv.Itoa(token.Line) + 
-			", columna " + strconv.Itoa(token.Column))
+		p.addError(codeUnexpectedToken, token, "Se esperaba "+string(expectedType)+" pero se encontró "+
+			string(token.Type)+" '"+token.Value+"'")
 		return false
 	}
-	
+
 	p.position++
 	return true
 }
 
-func (p *Parser) parseVariableDeclaration() {
-	// Verificar palabra clave/tipo
-	if p.currentToken() != nil && (p.currentToken().Type == KEYWORD || p.currentToken().Type == TYPE) {
-		p.position++
-	}
-	
-	// Nombre de variable
-	if !p.consume(IDENTIFIER) {
-		return
+// check indica si el token actual es del tipo indicado sin consumirlo
+func (p *Parser) check(tokenType TokenType) bool {
+	token := p.currentToken()
+	return token != nil && token.Type == tokenType
+}
+
+// rangeFrom cierra el rango de un nodo en el último token consumido
+func (p *Parser) rangeFrom(start Position) Range {
+	end := start
+	if p.position > 0 {
+		end = tokenEnd(&p.tokens[p.position-1])
 	}
-	
-	// Verificar declaración de tipo TypeScript opcional
-	if p.currentToken() != nil && p.currentToken().Type == COLON {
-		p.position++ // consume ':'
-		if p.currentToken() != nil && (p.currentToken().Type == TYPE || p.currentToken().Type == IDENTIFIER) {
-			p.position++
+	return Range{Start: start, End: end}
+}
+
+func tokenStart(token *Token) Position {
+	return Position{Line: token.Line, Column: token.Column, Offset: token.Position}
+}
+
+// tokenEnd calcula la posición inmediatamente posterior al token, teniendo en
+// cuenta los saltos de línea que pueda contener su valor.
+func tokenEnd(token *Token) Position {
+	end := Position{Line: token.Line, Column: token.Column, Offset: token.Position + len(token.Value)}
+	for i := 0; i < len(token.Value); i++ {
+		if token.Value[i] == '\n' {
+			end.Line++
+			end.Column = 1
 		} else {
-			p.addError("Se esperaba tipo después de ':'")
-			return
+			end.Column++
 		}
 	}
-	
-	// Operador de asignación
-	if !p.consume(ASSIGNMENT) {
-		return
+	return end
+}
+
+func identifierFromToken(token *Token) *Identifier {
+	id := &Identifier{Name: token.Value}
+	id.Loc = Range{Start: tokenStart(token), End: tokenEnd(token)}
+	return id
+}
+
+// isDeclarationToken distingue let/const/var y los tipos estilo C de otras
+// palabras clave como 'console', que inician una expresión.
+func isDeclarationToken(token *Token) bool {
+	if token.Type == TYPE {
+		return true
 	}
-	
-	// Valor
+	return token.Type == KEYWORD && (token.Value == "let" || token.Value == "const" || token.Value == "var")
+}
+
+func (p *Parser) parseStatement() Statement {
 	token := p.currentToken()
 	if token == nil {
-		p.addError(errorValue)
-		return
+		return nil
 	}
-	
+
 	switch token.Type {
-	case NUMBER, IDENTIFIER:
+	case FOR:
+		return p.parseForStatement()
+	case DO:
+		return p.parseDoWhileStatement()
+	case WHILE:
+		return p.parseWhileStatement()
+	case IF:
+		return p.parseIfStatement()
+	case SWITCH:
+		return p.parseSwitchStatement()
+	case BREAK, CONTINUE:
+		return p.parseJumpStatement()
+	case RETURN:
+		return p.parseReturnStatement()
+	case FUNCTION:
+		return p.parseFunctionDeclaration()
+	case ELSE:
+		p.addError(codeUnexpectedToken, token, "'else' sin 'if' correspondiente")
+		p.position++
+		return nil
+	case LBRACE:
+		return p.parseBlock()
+	case SEMICOLON:
 		p.position++
+		return nil
+	case KEYWORD, TYPE:
+		if p.isEnumStart() {
+			return p.parseEnumDeclaration()
+		}
+		if isDeclarationToken(token) {
+			return p.parseVariableDeclaration()
+		}
+		if token.Value == "interface" {
+			return p.parseInterfaceDeclaration()
+		}
+		if token.Value == "class" {
+			return p.parseClassDeclaration()
+		}
+		if token.Value == "export" {
+			return p.parseExportDeclaration()
+		}
+		return p.parseExpressionStatement()
+	case IDENTIFIER:
+		if p.isTypeAliasStart() {
+			return p.parseTypeAliasDeclaration()
+		}
+		if p.isModuleStart() {
+			return p.parseModuleDeclaration()
+		}
+		return p.parseExpressionStatement()
+	case NUMBER, STRING, BOOLEAN, TEMPLATE, TEMPLATE_HEAD, LPAREN, LBRACKET, INCREMENT, OPERATOR, LOGICAL:
+		return p.parseExpressionStatement()
 	case UNKNOWN:
-		p.addError("Número mal formado '" + token.Value + "' en línea " + 
-			strconv.Itoa(token.Line) + ", columna " + strconv.Itoa(token.Column))
+		p.reportInvalidToken(token)
 		p.position++
-		return
+		return nil
 	default:
-		p.addError("Se esperaba número o identificador, se encontró " + string(token.Type))
-		return
+		p.addError(codeUnexpectedToken, token, "Token inesperado "+string(token.Type)+" '"+token.Value+"'")
+		p.position++
+		return nil
 	}
-	
+}
+
+func (p *Parser) parseVariableDeclaration() Statement {
+	keywordToken := p.currentToken()
+	start := tokenStart(keywordToken)
+	p.position++
+
+	// Varios declaradores separados por comas: 'let a = 1, b: string;'
+	decl := &VariableDeclaration{Keyword: keywordToken.Value}
+	var declarator *VariableDeclarator
+	for {
+		declarator = p.parseVariableDeclarator(decl.Keyword)
+		if declarator == nil {
+			break
+		}
+		decl.Declarations = append(decl.Declarations, declarator)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+
 	// Punto y coma opcional
-	if p.currentToken() != nil && p.currentToken().Type == SEMICOLON {
+	if p.check(SEMICOLON) {
 		p.position++
-	} else {
+	} else if declarator != nil && declarator.Init != nil {
 		nextToken := p.currentToken()
-		if nextToken != nil && nextToken.Line == token.Line {
-			p.addError("Se esperaba punto y coma o salto de línea después de la declaración en línea " + 
-				strconv.Itoa(token.Line))
+		if nextToken != nil && nextToken.Type != RBRACE && nextToken.Line == declarator.Loc.End.Line {
+			p.addError(codeMissingSemicolon, nextToken,
+				"Se esperaba punto y coma o salto de línea después de la declaración")
 		}
 	}
-}
 
-func (p *Parser) parseForStatement() {
-	if !p.consume(FOR) { return }
-	if !p.consume(LPAREN) { return }
-	
-	p.parseInitialization()
-	if !p.consume(SEMICOLON) { return }
-	
-	p.parseCondition()
-	if !p.consume(SEMICOLON) { return }
-	
-	p.parseIncrement()
-	if !p.consume(RPAREN) { return }
-	if !p.consume(LBRACE) { return }
-	
-	p.parseStatements()
-	if !p.consume(RBRACE) { return }
+	decl.Loc = p.rangeFrom(start)
+	return decl
 }
 
-func (p *Parser) parseInitialization() {
-	token := p.currentToken()
-	if token == nil {
-		p.addError("Se esperaba declaración de variable en inicialización")
-		return
+// parseVariableDeclarator analiza 'nombre[: tipo] = valor'. En lugar del
+// nombre puede ir un patrón que desestructura el valor: '{ a, b } = objeto'.
+// Una variable que no sea 'const' puede declararse sin valor: 'let x: number'.
+func (p *Parser) parseVariableDeclarator(keyword string) *VariableDeclarator {
+	nameToken := p.currentToken()
+
+	declarator := &VariableDeclarator{}
+	if nameToken != nil && p.isPatternStart() {
+		declarator.Pattern = p.parseBindingTarget("en la declaración")
+	} else if p.consume(IDENTIFIER) {
+		declarator.Name = identifierFromToken(nameToken)
+	} else {
+		return nil
 	}
-	
-	if token.Type == KEYWORD || token.Type == TYPE {
-		p.position++
+	start := tokenStart(nameToken)
+	defer func() { declarator.Loc = p.rangeFrom(start) }()
+
+	// Verificar declaración de tipo TypeScript opcional
+	if p.check(COLON) {
+		p.position++ // consume ':'
+		declarator.TypeAnnotation = p.parseTypeAnnotation()
+		if declarator.TypeAnnotation == nil {
+			return declarator
+		}
 	}
-	
-	if !p.consume(IDENTIFIER) { return }
-	
-	// Tipo TypeScript opcional
-	if p.currentToken() != nil && p.currentToken().Type == COLON {
-		p.position++
-		if p.currentToken() != nil && (p.currentToken().Type == TYPE || p.currentToken().Type == IDENTIFIER) {
-			p.position++
+
+	// Sin '=' la declaración termina aquí; un patrón siempre necesita el valor
+	// que desestructura
+	if declarator.Pattern == nil && !p.check(ASSIGNMENT) && p.atDeclaratorEnd() {
+		if keyword == "const" {
+			p.addErrorRange(codeMissingInitializer, p.rangeFrom(start),
+				"La constante '"+declarator.Name.Name+"' debe recibir un valor en su declaración")
 		}
+		return declarator
 	}
-	
-	if !p.consume(ASSIGNMENT) { return }
-	
-	token = p.currentToken()
-	if token == nil {
-		p.addError("Se esperaba valor en inicialización")
-		return
+
+	// Operador de asignación
+	if !p.consume(ASSIGNMENT) {
+		return declarator
 	}
-	
-	if token.Type == NUMBER || token.Type == IDENTIFIER {
-		p.position++
-	} else {
-		p.addError("Se esperaba número o identificador en inicialización, se encontró " + string(token.Type))
+
+	// Valor
+	if p.currentToken() == nil {
+		p.addError(codeUnexpectedEOF, nil, errorValue)
+		return declarator
 	}
+	declarator.Init = p.parseExpression()
+	return declarator
 }
 
-func (p *Parser) parseCondition() {
-	if !p.consume(IDENTIFIER) { return }
-	if !p.consume(COMPARISON) { return }
-	
+// atDeclaratorEnd indica si el declarador termina antes del token actual: no
+// quedan tokens, viene ';', ',', ')' o '}', o empieza otra línea.
+func (p *Parser) atDeclaratorEnd() bool {
 	token := p.currentToken()
 	if token == nil {
-		p.addError("Se esperaba valor en condición")
-		return
+		return true
 	}
-	
-	if token.Type == NUMBER || token.Type == IDENTIFIER {
-		p.position++
-	} else {
-		p.addError("Se esperaba número o identificador en condición, se encontró " + string(token.Type))
+	switch token.Type {
+	case SEMICOLON, COMMA, RPAREN, RBRACE:
+		return true
 	}
+	return p.position > 0 && token.Line > p.tokens[p.position-1].Line
 }
 
-func (p *Parser) parseIncrement() {
+// parseForStatement analiza 'for (init; test; update)', en el que cualquiera
+// de las tres cláusulas puede faltar, y los recorridos 'for (x of lista)' y
+// 'for (k in objeto)'.
+func (p *Parser) parseForStatement() Statement {
+	start := tokenStart(p.currentToken())
+	stmt := &ForStatement{}
+	defer func() { stmt.Loc = p.rangeFrom(start) }()
+
+	if !p.consume(FOR) { return stmt }
+	if !p.consume(LPAREN) { return stmt }
+
+	if p.isForEachAhead() {
+		return p.parseForEachStatement(start)
+	}
+
+	if !p.check(SEMICOLON) {
+		stmt.Init = p.parseInitialization()
+	}
+	if !p.consume(SEMICOLON) { return stmt }
+
+	if !p.check(SEMICOLON) {
+		stmt.Test = p.parseExpression()
+	}
+	if !p.consume(SEMICOLON) { return stmt }
+
+	if !p.check(RPAREN) {
+		stmt.Update = p.parseSequence()
+	}
+	if !p.consume(RPAREN) { return stmt }
+
+	stmt.Body = p.parseLoopBody("for")
+	return stmt
+}
+
+// parseInitialization analiza la primera cláusula del for: una declaración
+// ('let i = 0, j = n') o una expresión ('i = 0, j = n').
+func (p *Parser) parseInitialization() Node {
 	token := p.currentToken()
 	if token == nil {
-		p.addError("Se esperaba expresión de incremento")
-		return
+		p.addError(codeUnexpectedEOF, nil, "Se esperaba declaración de variable en inicialización")
+		return nil
 	}
-	
-	if token.Type == INCREMENT {
-		p.position++
-		if !p.consume(IDENTIFIER) { return }
-	} else if token.Type == IDENTIFIER {
+
+	if !isDeclarationToken(token) {
+		return p.parseSequence()
+	}
+
+	start := tokenStart(token)
+	p.position++
+	decl := &VariableDeclaration{Keyword: token.Value}
+	for {
+		declarator := p.parseVariableDeclarator(decl.Keyword)
+		if declarator == nil {
+			break
+		}
+		decl.Declarations = append(decl.Declarations, declarator)
+		if !p.check(COMMA) {
+			break
+		}
 		p.position++
-		nextToken := p.currentToken()
-		if nextToken == nil {
-			p.addError("Se esperaba operador de incremento")
-			return
+	}
+	decl.Loc = p.rangeFrom(start)
+	return decl
+}
+
+// isForEachAhead indica, con el token actual tras el '(' del for, si la
+// cabecera es la de un 'for...of' o un 'for...in': antes de cualquier ';'
+// aparece 'of' o 'in' fuera de paréntesis, corchetes y llaves.
+func (p *Parser) isForEachAhead() bool {
+	depth := 0
+	for i := p.position; i < len(p.tokens); i++ {
+		token := &p.tokens[i]
+		switch token.Type {
+		case LPAREN, LBRACKET, LBRACE:
+			depth++
+		case RPAREN, RBRACKET, RBRACE:
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case SEMICOLON:
+			if depth == 0 {
+				return false
+			}
+		case IDENTIFIER:
+			// En 'for (let of = ...' la palabra es el nombre de la variable
+			if depth == 0 && i > p.position && (token.Value == "of" || token.Value == "in") &&
+				!(i == p.position+1 && isDeclarationToken(&p.tokens[p.position])) {
+				return true
+			}
 		}
-		
-		if nextToken.Type == INCREMENT {
-			p.position++
-		} else if nextToken.Type == ASSIGNMENT {
+	}
+	return false
+}
+
+// parseForEachStatement analiza el resto de 'for (x of lista)' o
+// 'for (k in objeto)' a partir del token que sigue a '('.
+func (p *Parser) parseForEachStatement(start Position) Statement {
+	left := p.parseForEachLeft()
+	if !p.checkContextual("of") && !p.checkContextual("in") {
+		p.addError(codeUnexpectedToken, p.currentToken(), "Se esperaba 'of' o 'in' después de la variable del for")
+		for p.currentToken() != nil && !p.checkContextual("of") && !p.checkContextual("in") {
 			p.position++
-			valueToken := p.currentToken()
-			if valueToken == nil {
-				p.addError("Se esperaba valor después del operador de asignación")
-				return
-			}
-			if valueToken.Type == NUMBER || valueToken.Type == IDENTIFIER {
-				p.position++
-			} else {
-				p.addError("Se esperaba número o identificador después del operador de asignación")
+		}
+		if p.currentToken() == nil {
+			stmt := &ForOfStatement{Left: left}
+			stmt.Loc = p.rangeFrom(start)
+			return stmt
+		}
+	}
+	keyword := p.currentToken().Value
+	p.position++
+
+	if decl, ok := left.(*VariableDeclaration); ok && keyword == "in" {
+		for _, declarator := range decl.Declarations {
+			if declarator.Pattern != nil {
+				p.addErrorRange(codeUnexpectedToken, declarator.Pattern.Span(),
+					"La variable de un 'for...in' no puede ser un patrón: recibe el nombre de cada propiedad")
 			}
-		} else {
-			p.addError("Se esperaba operador de incremento o asignación")
 		}
+	}
+
+	right := p.parseExpression()
+	var body Statement
+	if p.consume(RPAREN) {
+		body = p.parseLoopBody("for")
+	}
+
+	if keyword == "in" {
+		stmt := &ForInStatement{Left: left, Right: right, Body: body}
+		stmt.Loc = p.rangeFrom(start)
+		return stmt
+	}
+	stmt := &ForOfStatement{Left: left, Right: right, Body: body}
+	stmt.Loc = p.rangeFrom(start)
+	return stmt
+}
+
+// parseForEachLeft analiza la variable de un 'for...of' o 'for...in': una
+// declaración sin valor inicial ('const x', 'let [a, b]') o un destino ya
+// declarado ('x', 'obj.prop').
+func (p *Parser) parseForEachLeft() Node {
+	p.forEachHead = true
+	defer func() { p.forEachHead = false }()
+
+	token := p.currentToken()
+	start := tokenStart(token)
+	if !isDeclarationToken(token) {
+		target := p.parseConditional()
+		switch target.(type) {
+		case *Identifier, *MemberExpression:
+		default:
+			p.addErrorRange(codeInvalidAssignmentTarget, p.rangeFrom(start),
+				"Destino inválido en la cabecera del for: se esperaba una variable o una propiedad")
+		}
+		return target
+	}
+
+	p.position++
+	decl := &VariableDeclaration{Keyword: token.Value}
+	defer func() { decl.Loc = p.rangeFrom(start) }()
+
+	targetStart := tokenStart(p.currentToken())
+	target := p.parseBindingTarget("en la cabecera del for")
+	if target == nil {
+		return decl
+	}
+	declarator := &VariableDeclarator{}
+	if name, ok := target.(*Identifier); ok {
+		declarator.Name = name
 	} else {
-		p.addError("Se esperaba identificador o operador de incremento")
+		declarator.Pattern = target
+	}
+	decl.Declarations = append(decl.Declarations, declarator)
+
+	// TypeScript no admite ni anotación ni valor inicial: el valor lo da el recorrido
+	if p.check(COLON) {
+		colon := p.position
+		p.position++
+		p.parseTypeAnnotation()
+		p.addErrorRange(codeUnexpectedToken, p.rangeFrom(tokenStart(&p.tokens[colon])),
+			"La variable de un 'for...of' o 'for...in' no puede llevar anotación de tipo")
 	}
+	if token := p.currentToken(); token != nil && token.Type == ASSIGNMENT && token.Value == "=" {
+		assign := p.position
+		p.position++
+		p.parseConditional()
+		p.addErrorRange(codeUnexpectedToken, p.rangeFrom(tokenStart(&p.tokens[assign])),
+			"La variable de un 'for...of' o 'for...in' no puede tener valor inicial")
+	}
+	declarator.Loc = p.rangeFrom(targetStart)
+	return decl
+}
+
+// parseBlock analiza '{ sentencias }'
+func (p *Parser) parseBlock() *BlockStatement {
+	block := &BlockStatement{}
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba bloque '{'")
+		return block
+	}
+	start := tokenStart(token)
+	defer func() { block.Loc = p.rangeFrom(start) }()
+
+	if !p.consume(LBRACE) { return block }
+	p.blockDepth++
+	block.Body = p.parseStatements()
+	p.blockDepth--
+	p.consume(RBRACE)
+	return block
 }
 
-func (p *Parser) parseStatements() {
+func (p *Parser) parseStatements() []Statement {
+	statements := make([]Statement, 0, 4)
 	for p.currentToken() != nil && p.currentToken().Type != RBRACE {
-		token := p.currentToken()
-		
-		if token.Type == IDENTIFIER || token.Type == KEYWORD {
-			p.parseStatement()
-		} else {
+		start := p.position
+		if stmt := p.parseStatement(); stmt != nil {
+			statements = append(statements, stmt)
+		}
+		if p.position == start {
 			p.position++
 		}
 	}
+	return statements
 }
 
-func (p *Parser) parseStatement() {
-	token := p.currentToken()
-	if token == nil { return }
-	
-	if token.Type == IDENTIFIER || token.Type == KEYWORD {
+func (p *Parser) parseExpressionStatement() Statement {
+	start := tokenStart(p.currentToken())
+	stmt := &ExpressionStatement{Expression: p.parseExpression()}
+
+	// Punto y coma opcional
+	if p.check(SEMICOLON) {
+		p.position++
+	}
+
+	stmt.Loc = p.rangeFrom(start)
+	return stmt
+}
+
+func (p *Parser) parseDoWhileStatement() Statement {
+	start := tokenStart(p.currentToken())
+	stmt := &DoWhileStatement{}
+	defer func() { stmt.Loc = p.rangeFrom(start) }()
+
+	if !p.consume(DO) { return stmt }
+
+	stmt.Body = p.parseLoopBody("do")
+
+	// El 'while' que sigue al cuerpo pertenece siempre al do; un 'while'
+	// al inicio de una sentencia es un bucle independiente.
+	if !p.consume(WHILE) { return stmt }
+	if !p.consume(LPAREN) { return stmt }
+
+	stmt.Test = p.parseExpression()
+
+	if !p.consume(RPAREN) { return stmt }
+
+	// Punto y coma opcional
+	if p.check(SEMICOLON) {
 		p.position++
-		
-		// Acceso a propiedades con punto
-		if p.currentToken() != nil && p.currentToken().Value == "." {
+	}
+	return stmt
+}
+
+func (p *Parser) parseWhileStatement() Statement {
+	start := tokenStart(p.currentToken())
+	stmt := &WhileStatement{}
+	defer func() { stmt.Loc = p.rangeFrom(start) }()
+
+	if !p.consume(WHILE) { return stmt }
+	stmt.Test = p.parseCondition()
+	if stmt.Test == nil { return stmt }
+
+	stmt.Body = p.parseLoopBody("while")
+	return stmt
+}
+
+func (p *Parser) parseIfStatement() Statement {
+	start := tokenStart(p.currentToken())
+	stmt := &IfStatement{}
+	defer func() { stmt.Loc = p.rangeFrom(start) }()
+
+	if !p.consume(IF) { return stmt }
+	stmt.Test = p.parseCondition()
+	if stmt.Test == nil { return stmt }
+
+	stmt.Consequent = p.parseBody("if")
+	if p.check(ELSE) {
+		p.position++
+		stmt.Alternate = p.parseBody("else")
+	}
+	return stmt
+}
+
+// parseCondition analiza '( expresión )' de un if, while o switch
+func (p *Parser) parseCondition() Expression {
+	if !p.consume(LPAREN) { return nil }
+	test := p.parseExpression()
+	p.consume(RPAREN)
+	return test
+}
+
+// parseBody analiza el cuerpo de un if, else o bucle: un bloque o una única
+// sentencia. Una declaración 'let'/'const' suelta no se permite ahí.
+func (p *Parser) parseBody(owner string) Statement {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba el cuerpo de '"+owner+"'")
+		return nil
+	}
+
+	body := p.parseStatement()
+	if decl, ok := body.(*VariableDeclaration); ok && isBlockScoped(decl.Keyword) {
+		p.addErrorRange(codeDeclarationNotAllowed, decl.Loc,
+			"Una declaración '"+decl.Keyword+"' solo puede ir dentro de un bloque '{ }' en el cuerpo de '"+owner+"'")
+	}
+	return body
+}
+
+func (p *Parser) parseLoopBody(owner string) Statement {
+	p.loopDepth++
+	defer func() { p.loopDepth-- }()
+	return p.parseBody(owner)
+}
+
+func (p *Parser) parseSwitchStatement() Statement {
+	start := tokenStart(p.currentToken())
+	stmt := &SwitchStatement{}
+	defer func() { stmt.Loc = p.rangeFrom(start) }()
+
+	if !p.consume(SWITCH) { return stmt }
+	stmt.Discriminant = p.parseCondition()
+	if stmt.Discriminant == nil { return stmt }
+	if !p.consume(LBRACE) { return stmt }
+
+	p.switchDepth++
+	defer func() { p.switchDepth-- }()
+
+	seenDefault := false
+	for p.currentToken() != nil && !p.check(RBRACE) {
+		token := p.currentToken()
+		if token.Type != CASE && token.Type != DEFAULT {
+			p.addError(codeUnexpectedToken, token, "Se esperaba 'case' o 'default' dentro del switch, se encontró '"+
+				token.Value+"'")
 			p.position++
-			if p.currentToken() != nil && p.currentToken().Type == IDENTIFIER {
-				p.position++
-			}
+			continue
 		}
-		
-		// Llamada a función
-		if p.currentToken() != nil && p.currentToken().Type == LPAREN {
-			p.position++
-			
-			// Argumentos simplificados
-			for p.currentToken() != nil && p.currentToken().Type != RPAREN {
-				if p.currentToken().Type == UNKNOWN {
-					p.addError("Token inválido '" + p.currentToken().Value + 
-						"' en expresión en línea " + strconv.Itoa(p.currentToken().Line) + 
-						", columna " + strconv.Itoa(p.currentToken().Column))
-				}
-				p.position++
+
+		caseStart := tokenStart(token)
+		p.position++
+		switchCase := &SwitchCase{}
+		if token.Type == CASE {
+			switchCase.Test = p.parseExpression()
+		} else if seenDefault {
+			p.addError(codeUnexpectedToken, token, "El switch ya tiene una cláusula 'default'")
+		}
+		seenDefault = seenDefault || token.Type == DEFAULT
+		p.consume(COLON)
+
+		for p.currentToken() != nil && !p.check(CASE) && !p.check(DEFAULT) && !p.check(RBRACE) {
+			before := p.position
+			if child := p.parseStatement(); child != nil {
+				switchCase.Consequent = append(switchCase.Consequent, child)
 			}
-			
-			if p.currentToken() != nil && p.currentToken().Type == RPAREN {
+			if p.position == before {
 				p.position++
 			}
-		} else if p.currentToken() != nil && p.currentToken().Type == ASSIGNMENT {
-			p.position++
-			p.parseExpression()
-		}
-		
-		// Punto y coma opcional
-		if p.currentToken() != nil && p.currentToken().Type == SEMICOLON {
-			p.position++
 		}
+		switchCase.Loc = p.rangeFrom(caseStart)
+		stmt.Cases = append(stmt.Cases, switchCase)
 	}
+
+	p.consume(RBRACE)
+	return stmt
 }
 
-func (p *Parser) parseExpression() {
-	lastTokenType := UNKNOWN
-	
-	for p.currentToken() != nil {
-		currentToken := p.currentToken()
-		
-		// Verificar si es un token válido en expresión
-		if currentToken.Type != NUMBER && currentToken.Type != IDENTIFIER && 
-		   currentToken.Type != OPERATOR && currentToken.Type != UNKNOWN {
-			break
-		}
-		
-		if currentToken.Type == UNKNOWN {
-			p.addError("Token inválido '" + currentToken.Value + 
-				"' en expresión en línea " + strconv.Itoa(currentToken.Line) + 
-				", columna " + strconv.Itoa(currentToken.Column))
+// parseJumpStatement analiza 'break' y 'continue', comprobando que estén
+// dentro de un bucle (o de un switch, en el caso de 'break').
+func (p *Parser) parseJumpStatement() Statement {
+	token := p.currentToken()
+	p.position++
+
+	var stmt Statement
+	switch token.Type {
+	case BREAK:
+		if p.loopDepth == 0 && p.switchDepth == 0 {
+			p.addError(codeJumpOutsideLoop, token, "'break' solo puede usarse dentro de un bucle o un switch")
 		}
-		
-		// Verificar secuencias inválidas usando switch optimizado
-		switch {
-		case lastTokenType == NUMBER && currentToken.Type == IDENTIFIER:
-			p.addError("Error de sintaxis: número seguido de identificador sin operador en línea " + 
-				strconv.Itoa(currentToken.Line))
-		case lastTokenType == IDENTIFIER && currentToken.Type == NUMBER:
-			p.addError("Error de sintaxis: identificador seguido de número sin operador en línea " + 
-				strconv.Itoa(currentToken.Line))
-		case lastTokenType == NUMBER && currentToken.Type == NUMBER:
-			p.addError("Error de sintaxis: dos números consecutivos sin operador en línea " + 
-				strconv.Itoa(currentToken.Line))
-		case lastTokenType == IDENTIFIER && currentToken.Type == IDENTIFIER:
-			p.addError("Error de sintaxis: dos identificadores consecutivos sin operador en línea " + 
-				strconv.Itoa(currentToken.Line))
+		brk := &BreakStatement{}
+		brk.Loc = Range{Start: tokenStart(token), End: tokenEnd(token)}
+		stmt = brk
+	default:
+		if p.loopDepth == 0 {
+			p.addError(codeJumpOutsideLoop, token, "'continue' solo puede usarse dentro de un bucle")
 		}
-		
-		lastTokenType = currentToken.Type
+		cont := &ContinueStatement{}
+		cont.Loc = Range{Start: tokenStart(token), End: tokenEnd(token)}
+		stmt = cont
+	}
+
+	// Punto y coma opcional
+	if p.check(SEMICOLON) {
 		p.position++
-		
-		// Verificar delimitadores
-		if p.currentToken() != nil {
-			tokenType := p.currentToken().Type
-			if tokenType == SEMICOLON || tokenType == RBRACE || tokenType == RPAREN {
-				break
-			}
-		}
 	}
+	return stmt
 }
 
-func (p *Parser) parseDoWhileStatement() {
-	if !p.consume(DO) { return }
-	if !p.consume(LBRACE) { return }
-	
-	p.parseStatements()
-	
-	if !p.consume(RBRACE) { return }
-	if !p.consume(WHILE) { return }
-	if !p.consume(LPAREN) { return }
-	
-	p.parseCondition()
-	
-	if !p.consume(RPAREN) { return }
-	if !p.consume(SEMICOLON) { return }
-}
\ No newline at end of file
+// parseReturnStatement analiza 'return [valor]'. El valor debe empezar en la
+// misma línea: 'return' seguido de un salto de línea devuelve undefined.
+func (p *Parser) parseReturnStatement() Statement {
+	token := p.currentToken()
+	start := tokenStart(token)
+	p.position++
+
+	if p.functionDepth == 0 {
+		p.addError(codeReturnOutsideFunction, token, "'return' solo puede usarse dentro de una función")
+	}
+
+	stmt := &ReturnStatement{}
+	if next := p.currentToken(); next != nil && next.Line == token.Line &&
+		next.Type != SEMICOLON && next.Type != RBRACE {
+		stmt.Argument = p.parseExpression()
+	}
+
+	// Punto y coma opcional
+	if p.check(SEMICOLON) {
+		p.position++
+	}
+	stmt.Loc = p.rangeFrom(start)
+	return stmt
+}
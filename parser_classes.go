@@ -0,0 +1,199 @@
+package main
+
+// parseClassDeclaration analiza 'class Nombre<T> extends Base<U> implements I, J { ... }'
+func (p *Parser) parseClassDeclaration() Statement {
+	start := tokenStart(p.currentToken())
+	class := &ClassDeclaration{}
+	defer func() { class.Loc = p.rangeFrom(start) }()
+
+	p.position++ // 'class'
+	if !p.check(IDENTIFIER) {
+		p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" como nombre de la clase")
+		return class
+	}
+	class.Name = identifierFromToken(p.currentToken())
+	p.position++
+	class.TypeParams = p.parseTypeParameters()
+
+	if p.checkContextual("extends") {
+		p.position++
+		if p.check(IDENTIFIER) {
+			class.SuperClass = identifierFromToken(p.currentToken())
+			p.position++
+			if p.checkComparison("<") {
+				class.SuperTypeArguments = p.parseTypeArguments(class.SuperClass.Name)
+			}
+		} else {
+			p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" después de 'extends'")
+		}
+	}
+	if p.checkContextual("implements") {
+		p.position++
+		for {
+			if !p.check(IDENTIFIER) {
+				p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" después de 'implements'")
+				break
+			}
+			class.Implements = append(class.Implements, p.parseTypeReference().(*TypeReference))
+			if !p.check(COMMA) {
+				break
+			}
+			p.position++
+		}
+	}
+
+	if !p.consume(LBRACE) {
+		return class
+	}
+	class.Members = p.parseClassMembers()
+	p.consume(RBRACE)
+	return class
+}
+
+// parseClassMembers analiza los miembros del cuerpo de la clase hasta la '}'
+func (p *Parser) parseClassMembers() []ClassMember {
+	members := make([]ClassMember, 0, 4)
+	var constructor *MethodDefinition
+	for p.currentToken() != nil && !p.check(RBRACE) {
+		start := p.position
+		if p.check(SEMICOLON) {
+			p.position++
+			continue
+		}
+
+		member := p.parseClassMember()
+		if method, ok := member.(*MethodDefinition); ok && method.isConstructor() {
+			if constructor != nil {
+				p.addErrorRange(codeDuplicateConstructor, method.Name.Loc, "Una clase solo puede tener un constructor")
+			}
+			constructor = method
+		}
+		if member != nil {
+			members = append(members, member)
+		}
+		// Garantizar progreso tras un miembro mal formado
+		if p.position == start {
+			p.position++
+		}
+	}
+	return members
+}
+
+// parseClassMember analiza un campo '[modificadores] nombre[?][: tipo][= valor]'
+// o un método '[modificadores] nombre<T>(params)[: tipo] { ... }'.
+func (p *Parser) parseClassMember() ClassMember {
+	start := tokenStart(p.currentToken())
+
+	var access string
+	var static, readonly bool
+	for p.isModifier("public", "private", "protected", "static", "readonly") {
+		token := p.currentToken()
+		switch token.Value {
+		case "static":
+			static = true
+		case "readonly":
+			readonly = true
+		default:
+			if access != "" {
+				p.addError(codeUnexpectedToken, token, "El miembro ya tiene el modificador de acceso '"+access+"'")
+			}
+			access = token.Value
+		}
+		p.position++
+	}
+
+	token := p.currentToken()
+	var name *Identifier
+	switch {
+	case token.Type == STRING:
+		name = identifierFromToken(token)
+		name.Name = stringContent(token.Value)
+	case isPropertyName(token):
+		name = identifierFromToken(token)
+	default:
+		p.addError(codeExpectedIdentifier, token, errorIdentifier+" como nombre de un miembro de la clase")
+		return nil
+	}
+	p.position++
+
+	if p.check(LPAREN) || p.checkComparison("<") {
+		method := &MethodDefinition{Name: name, Access: access, Static: static}
+		if readonly {
+			p.addErrorRange(codeUnexpectedToken, name.Loc, "'readonly' solo se aplica a propiedades, no al método '"+
+				name.Name+"'")
+		}
+		method.Function = p.parseMethod(method.isConstructor())
+		method.Loc = p.rangeFrom(start)
+		return method
+	}
+
+	field := &PropertyDefinition{Name: name, Access: access, Static: static, Readonly: readonly}
+	if p.check(QUESTION) {
+		field.Optional = true
+		p.position++
+	}
+	if p.check(COLON) {
+		p.position++
+		field.TypeAnnotation = p.parseTypeAnnotation()
+	}
+	if token := p.currentToken(); token != nil && token.Type == ASSIGNMENT && token.Value == "=" {
+		p.position++
+		field.Value = p.parseAssignment()
+	}
+	field.Loc = p.rangeFrom(start)
+
+	// Punto y coma opcional
+	if p.check(SEMICOLON) {
+		p.position++
+	} else if next := p.currentToken(); next != nil && next.Type != RBRACE && next.Line == field.Loc.End.Line {
+		p.addError(codeMissingSemicolon, next, "Se esperaba punto y coma o salto de línea después de la propiedad '"+
+			name.Name+"'")
+	}
+	return field
+}
+
+// parseMethod analiza los parámetros, el tipo de retorno y el cuerpo de un
+// método. Solo los parámetros del constructor pueden declarar propiedades.
+func (p *Parser) parseMethod(constructor bool) *FunctionExpression {
+	start := tokenStart(p.currentToken())
+	fn := &FunctionExpression{TypeParams: p.parseTypeParameters()}
+	if constructor && fn.TypeParams != nil {
+		p.addErrorRange(codeUnexpectedToken, fn.TypeParams[0].Loc, "El constructor no puede declarar parámetros de tipo")
+	}
+
+	p.parameterProperties = constructor
+	fn.Params, fn.ReturnType = p.parseSignature()
+	if constructor && fn.ReturnType != nil {
+		p.addErrorRange(codeUnexpectedToken, fn.ReturnType.Span(), "El constructor no puede declarar un tipo de retorno")
+	}
+	fn.Body = p.parseFunctionBody()
+
+	fn.Loc = p.rangeFrom(start)
+	return fn
+}
+
+// isModifier indica si el token actual es uno de los modificadores dados y va
+// seguido del nombre al que se aplica. Si no, el propio modificador es el
+// nombre, como en 'static: number' o 'private()'.
+func (p *Parser) isModifier(modifiers ...string) bool {
+	token := p.currentToken()
+	if token == nil || token.Type != IDENTIFIER || p.position+1 >= len(p.tokens) {
+		return false
+	}
+	if next := &p.tokens[p.position+1]; next.Type != STRING && !isPropertyName(next) {
+		return false
+	}
+	for _, modifier := range modifiers {
+		if token.Value == modifier {
+			return true
+		}
+	}
+	return false
+}
+
+// checkContextual indica si el token actual es la palabra clave contextual
+// dada: 'extends' o 'implements' son identificadores fuera de su posición.
+func (p *Parser) checkContextual(word string) bool {
+	token := p.currentToken()
+	return token != nil && token.Type == IDENTIFIER && token.Value == word
+}
@@ -0,0 +1,649 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Niveles de precedencia de los operadores binarios (mayor número = une más
+// fuerte). Cero indica que el token no es un operador binario.
+const (
+	precNone      = iota
+	precLogicalOr // '||' y '??'
+	precLogicalAnd
+	precBitwiseOr
+	precBitwiseXor
+	precBitwiseAnd
+	precEquality
+	precRelational
+	precShift
+	precAdditive
+	precMultiplicative
+	precExponent
+	precUnary // operandos de unarios, llamadas y accesos a propiedad
+)
+
+// operatorPrecedence devuelve la precedencia de un operador binario; la usan
+// tanto el parser como exprString para decidir dónde hacen falta paréntesis.
+func operatorPrecedence(operator string) int {
+	switch operator {
+	case "||", "??":
+		return precLogicalOr
+	case "&&":
+		return precLogicalAnd
+	case "|":
+		return precBitwiseOr
+	case "^":
+		return precBitwiseXor
+	case "&":
+		return precBitwiseAnd
+	case "==", "!=", "===", "!==":
+		return precEquality
+	case "<", ">", "<=", ">=":
+		return precRelational
+	case "<<", ">>", ">>>":
+		return precShift
+	case "+", "-":
+		return precAdditive
+	case "*", "/", "%":
+		return precMultiplicative
+	case "**":
+		return precExponent
+	default:
+		return precNone
+	}
+}
+
+// isRightAssociative indica los operadores binarios que agrupan por la
+// derecha: '2 ** 3 ** 2' equivale a '2 ** (3 ** 2)'.
+func isRightAssociative(operator string) bool {
+	return operator == "**"
+}
+
+func binaryPrecedence(token *Token) int {
+	switch token.Type {
+	case OPERATOR, COMPARISON, LOGICAL:
+		return operatorPrecedence(token.Value)
+	default:
+		return precNone
+	}
+}
+
+// parseExpression es el punto de entrada del parser de expresiones por
+// precedencia (Pratt): asignación > || > && > igualdad > relacional >
+// aditivo > multiplicativo > unario > llamadas y accesos > primario.
+func (p *Parser) parseExpression() Expression {
+	return p.parseAssignment()
+}
+
+// parseSequence analiza 'a, b, c' en las cláusulas de un for. Con una sola
+// expresión devuelve esa expresión.
+func (p *Parser) parseSequence() Expression {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorValue)
+		return nil
+	}
+	start := tokenStart(token)
+	first := p.parseExpression()
+	if first == nil || !p.check(COMMA) {
+		return first
+	}
+	seq := &SequenceExpression{Expressions: []Expression{first}}
+	for p.check(COMMA) {
+		p.position++
+		next := p.parseExpression()
+		if next == nil {
+			break
+		}
+		seq.Expressions = append(seq.Expressions, next)
+	}
+	seq.Loc = p.rangeFrom(start)
+	return seq
+}
+
+// parseAssignment trata '=' y las asignaciones compuestas ('+=', '**=',
+// '??='...) como operadores asociativos por la derecha: 'a = b = 1' equivale a
+// 'a = (b = 1)'.
+func (p *Parser) parseAssignment() Expression {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorValue)
+		return nil
+	}
+	start := tokenStart(token)
+
+	if p.isArrowAhead() {
+		return p.parseArrowFunction()
+	}
+
+	left := p.parseConditional()
+	if !p.check(ASSIGNMENT) {
+		return left
+	}
+
+	operatorToken := p.currentToken()
+	p.position++
+	switch left.(type) {
+	case *Identifier, *MemberExpression:
+	default:
+		p.addErrorRange(codeInvalidAssignmentTarget, p.rangeFrom(start),
+			"Destino de asignación inválido antes de '"+operatorToken.Value+"'")
+	}
+
+	assign := &AssignmentExpression{Operator: operatorToken.Value, Target: left, Value: p.parseAssignment()}
+	assign.Loc = p.rangeFrom(start)
+	return assign
+}
+
+// parseConditional analiza el operador ternario 'test ? a : b', que tiene
+// menos precedencia que cualquier operador binario.
+func (p *Parser) parseConditional() Expression {
+	start := tokenStart(p.currentToken())
+	test := p.parseBinary(precLogicalOr)
+	if !p.check(QUESTION) {
+		return test
+	}
+	p.position++
+
+	conditional := &ConditionalExpression{Test: test, Consequent: p.parseAssignment()}
+	if p.consume(COLON) {
+		conditional.Alternate = p.parseAssignment()
+	}
+	conditional.Loc = p.rangeFrom(start)
+	return conditional
+}
+
+// parseBinary aplica precedence climbing: consume operadores cuya precedencia
+// sea al menos minPrec y analiza el lado derecho con un nivel más, lo que da
+// asociatividad por la izquierda.
+func (p *Parser) parseBinary(minPrec int) Expression {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorValue)
+		return nil
+	}
+	start := tokenStart(token)
+	left := p.parseUnary()
+
+	for {
+		operatorToken := p.currentToken()
+		if operatorToken == nil {
+			return left
+		}
+
+		prec := binaryPrecedence(operatorToken)
+		if prec == precNone {
+			if p.reportMissingOperator(operatorToken) {
+				p.parseUnary()
+				continue
+			}
+			return left
+		}
+		if prec < minPrec {
+			return left
+		}
+
+		p.position++
+		if p.currentToken() == nil {
+			p.addError(codeUnexpectedEOF, nil, errorValue+" después del operador '"+operatorToken.Value+"'")
+			return left
+		}
+		nextMinPrec := prec + 1
+		if isRightAssociative(operatorToken.Value) {
+			nextMinPrec = prec
+		}
+		right := p.parseBinary(nextMinPrec)
+
+		if operatorToken.Type == LOGICAL {
+			logical := &LogicalExpression{Operator: operatorToken.Value, Left: left, Right: right}
+			logical.Loc = p.rangeFrom(start)
+			left = logical
+		} else {
+			binary := &BinaryExpression{Operator: operatorToken.Value, Left: left, Right: right}
+			binary.Loc = p.rangeFrom(start)
+			left = binary
+		}
+	}
+}
+
+// reportMissingOperator detecta dos operandos seguidos en la misma línea
+// ('5 a', 'x 3') y lo reporta; un salto de línea termina la expresión porque
+// el punto y coma es opcional.
+func (p *Parser) reportMissingOperator(current *Token) bool {
+	if p.position == 0 {
+		return false
+	}
+	previous := &p.tokens[p.position-1]
+	if current.Line != previous.Line {
+		return false
+	}
+
+	switch current.Type {
+	case NUMBER, IDENTIFIER, STRING, BOOLEAN:
+	default:
+		return false
+	}
+	if p.forEachHead && current.Type == IDENTIFIER && (current.Value == "of" || current.Value == "in") {
+		return false
+	}
+
+	var message string
+	switch {
+	case previous.Type == NUMBER && current.Type == IDENTIFIER:
+		message = "Número '" + previous.Value + "' seguido de identificador '" + current.Value + "' sin operador"
+	case previous.Type == IDENTIFIER && current.Type == NUMBER:
+		message = "Identificador '" + previous.Value + "' seguido de número '" + current.Value + "' sin operador"
+	case previous.Type == NUMBER && current.Type == NUMBER:
+		message = "Dos números consecutivos '" + previous.Value + "' '" + current.Value + "' sin operador"
+	case previous.Type == IDENTIFIER && current.Type == IDENTIFIER:
+		message = "Dos identificadores consecutivos '" + previous.Value + "' '" + current.Value + "' sin operador"
+	default:
+		message = "Se esperaba un operador entre '" + previous.Value + "' y '" + current.Value + "'"
+	}
+	p.addErrorRange(codeMissingOperator, Range{Start: tokenStart(previous), End: tokenEnd(current)}, message)
+	return true
+}
+
+// parseUnary analiza los operadores prefijos '!', '-', '+', '~', '++' y '--'
+func (p *Parser) parseUnary() Expression {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorValue)
+		return nil
+	}
+	start := tokenStart(token)
+
+	switch {
+	case token.Type == LOGICAL && token.Value == "!",
+		token.Type == OPERATOR && (token.Value == "-" || token.Value == "+" || token.Value == "~"):
+		p.position++
+		unary := &UnaryExpression{Operator: token.Value, Argument: p.parseUnary()}
+		unary.Loc = p.rangeFrom(start)
+		return unary
+	case token.Type == INCREMENT:
+		p.position++
+		update := &UpdateExpression{Operator: token.Value, Prefix: true, Argument: p.parseUnary()}
+		update.Loc = p.rangeFrom(start)
+		return update
+	}
+
+	return p.parsePostfix()
+}
+
+// parsePostfix analiza un operando con sus accesos a propiedad, índices,
+// llamadas e incrementos sufijos: 'console.log(x)', 'a?.b', 'v[i]', 'i++'.
+func (p *Parser) parsePostfix() Expression {
+	token := p.currentToken()
+	start := tokenStart(token)
+	expr := p.parsePrimary()
+	if expr == nil {
+		return nil
+	}
+
+	for p.currentToken() != nil {
+		next := p.currentToken()
+		switch {
+		case next.Type == DOT || next.Type == QUESTION_DOT:
+			p.position++
+			optional := next.Type == QUESTION_DOT
+			switch {
+			case optional && p.check(LBRACKET):
+				// Índice opcional: 'a?.[i]'
+				expr = p.parseIndex(expr, start, true)
+			case optional && p.check(LPAREN):
+				// Llamada opcional: 'f?.(x)'
+				expr = p.parseCall(expr, start, true)
+			default:
+				member := &MemberExpression{Object: expr, Optional: optional}
+				if isPropertyName(p.currentToken()) {
+					member.Property = identifierFromToken(p.currentToken())
+					p.position++
+				} else {
+					p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" después de '"+next.Value+"'")
+				}
+				member.Loc = p.rangeFrom(start)
+				expr = member
+			}
+		case next.Type == LBRACKET:
+			expr = p.parseIndex(expr, start, false)
+		case next.Type == LPAREN:
+			expr = p.parseCall(expr, start, false)
+		case next.Type == COMPARISON && next.Value == "<" && p.isTypeArgumentsAhead():
+			// Argumentos de tipo explícitos: 'f<number>(x)'
+			typeArgs := p.parseTypeArguments(exprString(expr))
+			call := p.parseCall(expr, start, false).(*CallExpression)
+			call.TypeArguments = typeArgs
+			expr = call
+		case next.Type == INCREMENT && next.Line == p.tokens[p.position-1].Line:
+			p.position++
+			update := &UpdateExpression{Operator: next.Value, Argument: expr}
+			update.Loc = p.rangeFrom(start)
+			return update
+		default:
+			return expr
+		}
+	}
+	return expr
+}
+
+// isPropertyName acepta identificadores y palabras clave como nombre de
+// propiedad tras '.': 'obj.default', 'x.return'.
+func isPropertyName(token *Token) bool {
+	if token == nil {
+		return false
+	}
+	if token.Type == IDENTIFIER {
+		return true
+	}
+	_, isKeyword := keywords[token.Value]
+	return isKeyword
+}
+
+// parseIndex analiza el acceso por índice 'objeto[expr]' con el token actual en '['
+func (p *Parser) parseIndex(object Expression, start Position, optional bool) Expression {
+	p.position++
+	index := &IndexExpression{Object: object, Index: p.parseExpression(), Optional: optional}
+	p.consume(RBRACKET)
+	index.Loc = p.rangeFrom(start)
+	return index
+}
+
+// parseCall analiza los argumentos de una llamada con el token actual en '('
+func (p *Parser) parseCall(callee Expression, start Position, optional bool) Expression {
+	p.position++
+	call := &CallExpression{Callee: callee, Arguments: p.parseArguments(), Optional: optional}
+	p.consume(RPAREN)
+	call.Loc = p.rangeFrom(start)
+	return call
+}
+
+func (p *Parser) parsePrimary() Expression {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorValue)
+		return nil
+	}
+	start := tokenStart(token)
+
+	switch token.Type {
+	case KEYWORD:
+		switch token.Value {
+		case "this":
+			p.position++
+			this := &ThisExpression{}
+			this.Loc = p.rangeFrom(start)
+			return this
+		case "super":
+			p.position++
+			super := &SuperExpression{}
+			super.Loc = p.rangeFrom(start)
+			return super
+		case "new":
+			return p.parseNewExpression()
+		}
+		p.position++
+		return identifierFromToken(token)
+	case IDENTIFIER:
+		p.position++
+		return identifierFromToken(token)
+	case NUMBER:
+		p.position++
+		lit := &NumericLiteral{Raw: token.Value}
+		lit.Loc = p.rangeFrom(start)
+		return lit
+	case STRING:
+		p.position++
+		lit := &StringLiteral{Raw: token.Value}
+		lit.Loc = p.rangeFrom(start)
+		return lit
+	case BOOLEAN:
+		p.position++
+		lit := &BooleanLiteral{Value: token.Value == "true"}
+		lit.Loc = p.rangeFrom(start)
+		return lit
+	case TEMPLATE, TEMPLATE_HEAD:
+		return p.parseTemplateLiteral()
+	case FUNCTION:
+		return p.parseFunctionExpression()
+	case LBRACE:
+		return p.parseObjectLiteral()
+	case LBRACKET:
+		return p.parseArrayLiteral()
+	case LPAREN:
+		p.position++
+		expr := p.parseExpression()
+		p.consume(RPAREN)
+		return expr
+	case UNKNOWN:
+		p.reportInvalidToken(token)
+		return p.badExpression(token)
+	default:
+		p.addError(codeExpectedExpression, token, errorValue+", se encontró "+string(token.Type)+" '"+token.Value+"'")
+		return nil
+	}
+}
+
+// parseNewExpression analiza 'new Clase<T>(args)'. La clase puede nombrarse
+// con accesos a propiedad ('new geo.Punto()') y los paréntesis son opcionales.
+func (p *Parser) parseNewExpression() Expression {
+	start := tokenStart(p.currentToken())
+	p.position++ // 'new'
+
+	expr := &NewExpression{Callee: p.parsePrimary(), Arguments: []Expression{}}
+	for expr.Callee != nil && p.check(DOT) {
+		p.position++
+		member := &MemberExpression{Object: expr.Callee}
+		if isPropertyName(p.currentToken()) {
+			member.Property = identifierFromToken(p.currentToken())
+			p.position++
+		} else {
+			p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" después de '.'")
+		}
+		member.Loc = p.rangeFrom(start)
+		expr.Callee = member
+	}
+	if expr.Callee != nil && p.checkComparison("<") {
+		expr.TypeArguments = p.parseTypeArguments(exprString(expr.Callee))
+	}
+	if p.check(LPAREN) {
+		p.position++
+		expr.Arguments = p.parseArguments()
+		p.consume(RPAREN)
+	}
+
+	expr.Loc = p.rangeFrom(start)
+	return expr
+}
+
+// parseArrayLiteral analiza '[a, b, ...resto]'. Una coma sin valor delante
+// deja un hueco; la coma final no añade ninguno.
+func (p *Parser) parseArrayLiteral() Expression {
+	start := tokenStart(p.currentToken())
+	p.position++ // '['
+
+	array := &ArrayLiteral{Elements: make([]Expression, 0, 4)}
+	for p.currentToken() != nil && !p.check(RBRACKET) {
+		if p.check(COMMA) {
+			array.Elements = append(array.Elements, nil)
+			p.position++
+			continue
+		}
+		element := p.parseElement()
+		if element == nil {
+			break
+		}
+		array.Elements = append(array.Elements, element)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+	p.consume(RBRACKET)
+
+	array.Loc = p.rangeFrom(start)
+	return array
+}
+
+// parseElement analiza un elemento de un array o un argumento de una
+// llamada, que puede ser '...valor'.
+func (p *Parser) parseElement() Expression {
+	if !p.check(ELLIPSIS) {
+		return p.parseAssignment()
+	}
+	return p.parseSpread()
+}
+
+// parseSpread analiza '...valor' con el token actual en '...'
+func (p *Parser) parseSpread() *SpreadElement {
+	start := tokenStart(p.currentToken())
+	p.position++
+	spread := &SpreadElement{Argument: p.parseAssignment()}
+	spread.Loc = p.rangeFrom(start)
+	return spread
+}
+
+// parseObjectLiteral analiza '{ clave: valor, ... }'; admite una coma final
+func (p *Parser) parseObjectLiteral() Expression {
+	start := tokenStart(p.currentToken())
+	p.position++ // '{'
+
+	obj := &ObjectLiteral{Properties: make([]ObjectMember, 0, 4)}
+	for p.currentToken() != nil && !p.check(RBRACE) {
+		var member ObjectMember
+		if p.check(ELLIPSIS) {
+			member = p.parseSpread()
+		} else if prop := p.parseProperty(); prop != nil {
+			member = prop
+		} else {
+			break
+		}
+		obj.Properties = append(obj.Properties, member)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+	p.consume(RBRACE)
+
+	obj.Loc = p.rangeFrom(start)
+	return obj
+}
+
+// parseProperty analiza una entrada 'clave: valor'. La clave puede ser un
+// nombre, una cadena, un número o una expresión calculada '[expr]'; un nombre
+// solo, '{ x }', equivale a '{ x: x }', y un nombre seguido de parámetros es
+// un método abreviado, '{ m() { ... } }'.
+func (p *Parser) parseProperty() *Property {
+	token := p.currentToken()
+	start := tokenStart(token)
+
+	prop := &Property{}
+	key, computed := p.parsePropertyKey()
+	if key == nil {
+		return nil
+	}
+	prop.Key, prop.Computed = key, computed
+
+	if p.check(LPAREN) || p.checkComparison("<") {
+		// Método abreviado: '{ m(x) { ... } }'
+		prop.Method = true
+		prop.Value = p.parseMethod(false)
+	} else if name, ok := key.(*Identifier); ok && !computed && token.Type == IDENTIFIER && !p.check(COLON) {
+		prop.Shorthand = true
+		prop.Value = name
+		copied := *name
+		prop.Key = &copied
+	} else if p.consume(COLON) {
+		prop.Value = p.parseAssignment()
+	}
+	prop.Loc = p.rangeFrom(start)
+	return prop
+}
+
+// parsePropertyKey analiza la clave de un objeto literal o de un patrón de
+// objeto; computed indica que iba entre corchetes.
+func (p *Parser) parsePropertyKey() (key Expression, computed bool) {
+	token := p.currentToken()
+	switch {
+	case token.Type == STRING || token.Type == NUMBER:
+		return p.parsePrimary(), false
+	case token.Type == LBRACKET:
+		p.position++
+		key = p.parseAssignment()
+		p.consume(RBRACKET)
+		return key, true
+	case isPropertyName(token):
+		p.position++
+		return identifierFromToken(token), false
+	default:
+		p.addError(codeExpectedIdentifier, token, errorIdentifier+" como nombre de propiedad")
+		return nil, false
+	}
+}
+
+// parseTemplateLiteral une los tramos TEMPLATE_HEAD / TEMPLATE_MIDDLE /
+// TEMPLATE_TAIL que produce el lexer con las expresiones de cada '${...}'.
+func (p *Parser) parseTemplateLiteral() Expression {
+	token := p.currentToken()
+	start := tokenStart(token)
+	p.position++
+
+	template := &TemplateLiteral{Quasis: []string{templateText(token)}}
+	for token.Type == TEMPLATE_HEAD || token.Type == TEMPLATE_MIDDLE {
+		template.Expressions = append(template.Expressions, p.parseExpression())
+
+		token = p.currentToken()
+		if token == nil || (token.Type != TEMPLATE_MIDDLE && token.Type != TEMPLATE_TAIL) {
+			p.addError(codeUnexpectedToken, token, "Se esperaba '}' para cerrar la expresión '${' del template")
+			template.Quasis = append(template.Quasis, "")
+			break
+		}
+		p.position++
+		template.Quasis = append(template.Quasis, templateText(token))
+	}
+
+	template.Loc = p.rangeFrom(start)
+	return template
+}
+
+// templateText quita los delimitadores ('`', '${', '}') de un tramo de template
+func templateText(token *Token) string {
+	text := token.Value[1:]
+	if token.Type == TEMPLATE_HEAD || token.Type == TEMPLATE_MIDDLE {
+		return strings.TrimSuffix(text, "${")
+	}
+	return strings.TrimSuffix(text, "`")
+}
+
+// parseArguments analiza la lista 'a, b, ...c' de una llamada; admite una
+// coma final antes de ')'. Si falta la ')' la reporta quien llama.
+func (p *Parser) parseArguments() []Expression {
+	args := make([]Expression, 0, 2)
+	for p.currentToken() != nil && !p.check(RPAREN) {
+		if arg := p.parseElement(); arg != nil {
+			args = append(args, arg)
+		}
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+	return args
+}
+
+// reportInvalidToken señala un token UNKNOWN. Los números mal formados ya los
+// reporta el lexer, así que aquí no se duplican.
+func (p *Parser) reportInvalidToken(token *Token) {
+	if len(token.Value) > 0 && (unicode.IsDigit(rune(token.Value[0])) || token.Value[0] == '.') {
+		return
+	}
+	p.addError(codeInvalidToken, token, "Token inválido '"+token.Value+"'")
+}
+
+func (p *Parser) badExpression(token *Token) Expression {
+	p.position++
+	bad := &BadExpression{Raw: token.Value}
+	bad.Loc = Range{Start: tokenStart(token), End: tokenEnd(token)}
+	return bad
+}
@@ -0,0 +1,237 @@
+package main
+
+// parseFunctionDeclaration analiza 'function nombre<T>(params): tipo { ... }'
+func (p *Parser) parseFunctionDeclaration() Statement {
+	start := tokenStart(p.currentToken())
+	fn := &FunctionDeclaration{}
+	defer func() { fn.Loc = p.rangeFrom(start) }()
+
+	p.position++ // 'function'
+	if p.check(IDENTIFIER) {
+		fn.Name = identifierFromToken(p.currentToken())
+		p.position++
+	} else {
+		p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" como nombre de la función")
+	}
+
+	fn.TypeParams = p.parseTypeParameters()
+	fn.Params, fn.ReturnType = p.parseSignature()
+	fn.Body = p.parseFunctionBody()
+	return fn
+}
+
+// parseFunctionExpression analiza 'function [nombre]<T>(params) { ... }'
+// usada como valor.
+func (p *Parser) parseFunctionExpression() Expression {
+	start := tokenStart(p.currentToken())
+	fn := &FunctionExpression{}
+
+	p.position++ // 'function'
+	if p.check(IDENTIFIER) {
+		fn.Name = identifierFromToken(p.currentToken())
+		p.position++
+	}
+
+	fn.TypeParams = p.parseTypeParameters()
+	fn.Params, fn.ReturnType = p.parseSignature()
+	fn.Body = p.parseFunctionBody()
+	fn.Loc = p.rangeFrom(start)
+	return fn
+}
+
+// isArrowAhead mira hacia delante sin consumir para saber si empieza una arrow
+// function: 'x =>', '(...) =>', '(...): tipo =>' o '<T>(...) =>'.
+func (p *Parser) isArrowAhead() bool {
+	token := p.currentToken()
+	if token == nil {
+		return false
+	}
+	if token.Type == IDENTIFIER {
+		return p.tokenTypeAt(p.position+1) == ARROW
+	}
+	if token.Type == COMPARISON && token.Value == "<" {
+		end := p.typeParametersEnd(p.position)
+		return end >= 0 && p.tokenTypeAt(end) == LPAREN && p.isParenthesizedArrowAt(end)
+	}
+	if token.Type != LPAREN {
+		return false
+	}
+	return p.isParenthesizedArrowAt(p.position)
+}
+
+// isParenthesizedArrowAt indica si el '(' de la posición start abre los
+// parámetros de una arrow function.
+func (p *Parser) isParenthesizedArrowAt(start int) bool {
+	depth := 0
+	for i := start; i < len(p.tokens); i++ {
+		switch p.tokens[i].Type {
+		case LPAREN:
+			depth++
+		case RPAREN:
+			depth--
+			if depth == 0 {
+				next := p.tokenTypeAt(i + 1)
+				return next == ARROW || (next == COLON && p.tokenTypeAt(i+3) == ARROW)
+			}
+		}
+	}
+	return false
+}
+
+func (p *Parser) tokenTypeAt(index int) TokenType {
+	if index >= len(p.tokens) {
+		return ""
+	}
+	return p.tokens[index].Type
+}
+
+// parseArrowFunction analiza 'x => expr' y '<T>(params): tipo => cuerpo'. Si
+// el cuerpo no es un bloque, el valor de la expresión es el que se devuelve.
+func (p *Parser) parseArrowFunction() Expression {
+	start := tokenStart(p.currentToken())
+	arrow := &ArrowFunction{TypeParams: p.parseTypeParameters()}
+
+	if arrow.TypeParams == nil && p.check(IDENTIFIER) {
+		param := &Parameter{Name: identifierFromToken(p.currentToken())}
+		param.Loc = param.Name.Loc
+		arrow.Params = []*Parameter{param}
+		p.position++
+	} else {
+		arrow.Params, arrow.ReturnType = p.parseSignature()
+	}
+
+	if p.consume(ARROW) {
+		if p.check(LBRACE) {
+			arrow.Body = p.parseFunctionBody()
+		} else if body := p.parseAssignment(); body != nil {
+			arrow.Body = body
+		}
+	}
+
+	arrow.Loc = p.rangeFrom(start)
+	return arrow
+}
+
+// parseSignature analiza '(params)' seguido de la anotación ': tipo' opcional
+// del valor de retorno.
+func (p *Parser) parseSignature() ([]*Parameter, TypeNode) {
+	params := p.parseParameters()
+	var returnType TypeNode
+	if p.check(COLON) {
+		p.position++
+		returnType = p.parseTypeAnnotation()
+	}
+	return params, returnType
+}
+
+func (p *Parser) parseParameters() []*Parameter {
+	params := make([]*Parameter, 0, 2)
+	// Los modificadores valen solo en esta lista, no en las funciones que
+	// aparezcan en los valores por defecto
+	properties := p.parameterProperties
+	p.parameterProperties = false
+	if !p.consume(LPAREN) {
+		return params
+	}
+
+	for p.currentToken() != nil && !p.check(RPAREN) {
+		param := p.parseParameter(properties)
+		if param == nil {
+			break
+		}
+		if len(params) > 0 && params[len(params)-1].Rest {
+			p.addErrorRange(codeUnexpectedToken, params[len(params)-1].Loc,
+				"El parámetro rest '..."+params[len(params)-1].displayName()+"' debe ser el último")
+		}
+		params = append(params, param)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+
+	p.consume(RPAREN)
+	return params
+}
+
+// parseParameter analiza '[...]nombre[?][: tipo][= valor]', donde el nombre
+// puede ser un patrón de desestructuración. Con properties admite además los
+// modificadores de un parámetro del constructor.
+func (p *Parser) parseParameter(properties bool) *Parameter {
+	start := tokenStart(p.currentToken())
+	param := &Parameter{}
+
+	for p.isModifier("public", "private", "protected", "readonly") {
+		token := p.currentToken()
+		if !properties {
+			p.addError(codeUnexpectedToken, token, "El modificador '"+token.Value+
+				"' solo se permite en los parámetros del constructor")
+		}
+		if token.Value == "readonly" {
+			param.Readonly = true
+		} else {
+			param.Access = token.Value
+		}
+		p.position++
+	}
+	if p.check(ELLIPSIS) {
+		param.Rest = true
+		p.position++
+	}
+	switch {
+	case p.check(IDENTIFIER):
+		param.Name = identifierFromToken(p.currentToken())
+		p.position++
+	case p.isPatternStart():
+		param.Pattern = p.parseBindingTarget("como parámetro")
+		if param.Pattern == nil {
+			return nil
+		}
+	default:
+		p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" como nombre de parámetro")
+		return nil
+	}
+
+	if p.check(QUESTION) {
+		param.Optional = true
+		p.position++
+	}
+	if p.check(COLON) {
+		p.position++
+		param.TypeAnnotation = p.parseTypeAnnotation()
+	}
+	if token := p.currentToken(); token != nil && token.Type == ASSIGNMENT && token.Value == "=" {
+		p.position++
+		param.Default = p.parseAssignment()
+	}
+
+	param.Loc = p.rangeFrom(start)
+	switch {
+	case param.isProperty() && param.Pattern != nil:
+		p.addErrorRange(codeUnexpectedToken, param.Loc,
+			"Un parámetro desestructurado no puede declarar una propiedad con modificadores")
+		param.Access, param.Readonly = "", false
+	case param.Rest && (param.Optional || param.Default != nil):
+		p.addErrorRange(codeUnexpectedToken, param.Loc,
+			"El parámetro rest '..."+param.displayName()+"' no puede ser opcional ni tener valor por defecto")
+	case param.Optional && param.Default != nil:
+		p.addErrorRange(codeUnexpectedToken, param.Loc,
+			"El parámetro '"+param.displayName()+"' no puede ser opcional y tener valor por defecto a la vez")
+	}
+	return param
+}
+
+// parseFunctionBody analiza el bloque de una función. Dentro de ella 'return'
+// es válido y los bucles o switch exteriores dejan de contar para 'break' y
+// 'continue'.
+func (p *Parser) parseFunctionBody() *BlockStatement {
+	loopDepth, switchDepth := p.loopDepth, p.switchDepth
+	p.loopDepth, p.switchDepth = 0, 0
+	p.functionDepth++
+	defer func() {
+		p.loopDepth, p.switchDepth = loopDepth, switchDepth
+		p.functionDepth--
+	}()
+
+	return p.parseBlock()
+}
@@ -0,0 +1,182 @@
+package main
+
+import "strings"
+
+// parseTypeParameters analiza '<T, U extends X = Y>' tras el nombre de una
+// función, interfaz, alias, clase o método. Sin '<' devuelve nil.
+func (p *Parser) parseTypeParameters() []*TypeParameter {
+	if !p.checkComparison("<") {
+		return nil
+	}
+	open := p.currentToken()
+	p.position++
+
+	params := make([]*TypeParameter, 0, 2)
+	var optional *TypeParameter
+	for p.currentToken() != nil && !isClosingAngle(p.currentToken()) {
+		if !p.check(IDENTIFIER) {
+			p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" como nombre del parámetro de tipo")
+			break
+		}
+		token := p.currentToken()
+		param := &TypeParameter{Name: identifierFromToken(token)}
+		p.position++
+
+		if p.checkContextual("extends") {
+			p.position++
+			param.Constraint = p.parseTypeAfter("extends")
+		}
+		if token := p.currentToken(); token != nil && token.Type == ASSIGNMENT && token.Value == "=" {
+			p.position++
+			param.Default = p.parseTypeAfter("=")
+		}
+		param.Loc = p.rangeFrom(tokenStart(token))
+
+		switch {
+		case param.Default != nil:
+			optional = param
+		case optional != nil:
+			p.addErrorRange(codeUnexpectedToken, param.Loc, "El parámetro de tipo '"+param.Name.Name+
+				"' no tiene valor por defecto y no puede ir después de '"+optional.Name.Name+"', que sí lo tiene")
+		}
+		params = append(params, param)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+
+	if len(params) == 0 && p.currentToken() != nil && isClosingAngle(p.currentToken()) {
+		p.addError(codeUnexpectedToken, open, "La lista de parámetros de tipo no puede estar vacía")
+	}
+	p.closeAngle("los parámetros de tipo")
+	return params
+}
+
+// parseTypeAfter analiza el tipo que sigue a 'extends' o '=' en un parámetro
+// de tipo.
+func (p *Parser) parseTypeAfter(word string) TypeNode {
+	if !p.isTypeStart() {
+		p.addError(codeExpectedType, p.currentToken(), "Se esperaba un tipo después de '"+word+"'")
+		return nil
+	}
+	return p.parseType()
+}
+
+// parseTypeArguments analiza '<A, B>' con el token actual en '<'. owner
+// nombra el tipo, la función o la clase que los recibe.
+func (p *Parser) parseTypeArguments(owner string) []TypeNode {
+	p.position++ // '<'
+	args := make([]TypeNode, 0, 2)
+	for p.currentToken() != nil && !isClosingAngle(p.currentToken()) {
+		arg := p.parseType()
+		if arg == nil {
+			break
+		}
+		args = append(args, arg)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+	p.closeAngle("los argumentos de tipo de '" + owner + "'")
+	return args
+}
+
+// isClosingAngle indica si el token empieza por el '>' que cierra una lista de
+// tipos. El lexer une los '>' consecutivos, como en 'Array<Array<number>>', y
+// también el '=' que sigue en 'let x: Array<number>= y'.
+func isClosingAngle(token *Token) bool {
+	switch token.Type {
+	case COMPARISON, OPERATOR, ASSIGNMENT:
+		return strings.HasPrefix(token.Value, ">")
+	default:
+		return false
+	}
+}
+
+// closeAngle consume el '>' que cierra una lista de tipos. Si el token lleva
+// más caracteres ('>>', '>=', '>>='), consume solo el primero y deja el resto
+// como token actual para quien lo espere a continuación.
+func (p *Parser) closeAngle(context string) bool {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba '>' para cerrar "+context)
+		return false
+	}
+	if !isClosingAngle(token) {
+		p.addError(codeUnexpectedToken, token, "Se esperaba '>' para cerrar "+context)
+		return false
+	}
+	if token.Value == ">" {
+		p.position++
+		return true
+	}
+	rest := token.Value[1:]
+	p.tokens[p.position] = Token{
+		Type:     getOperatorType(rest),
+		Value:    rest,
+		Position: token.Position + 1,
+		Line:     token.Line,
+		Column:   token.Column + 1,
+	}
+	return true
+}
+
+// typeParametersEnd busca sin consumir el '>' que cierra la lista de tipos que
+// abre el '<' de la posición start. Devuelve la posición del token siguiente,
+// o -1 si lo que sigue no puede ser una lista de tipos, como en 'a < b'.
+func (p *Parser) typeParametersEnd(start int) int {
+	angles, nesting := 0, 0
+	for i := start; i < len(p.tokens); i++ {
+		token := &p.tokens[i]
+		switch token.Type {
+		case COMPARISON:
+			switch token.Value {
+			case "<":
+				angles++
+			case ">":
+				angles--
+			default:
+				return -1
+			}
+		case OPERATOR:
+			switch token.Value {
+			case ">>":
+				angles -= 2
+			case ">>>":
+				angles -= 3
+			case "|", "&":
+			default:
+				return -1
+			}
+		case LPAREN, LBRACKET, LBRACE:
+			nesting++
+		case RPAREN, RBRACKET, RBRACE:
+			// Un tipo no cierra paréntesis que se abrieron antes del '<'
+			if nesting == 0 {
+				return -1
+			}
+			nesting--
+		case IDENTIFIER, TYPE, KEYWORD, STRING, NUMBER, BOOLEAN, COMMA, DOT, COLON, SEMICOLON, QUESTION, ARROW,
+			ELLIPSIS:
+		default:
+			return -1
+		}
+		if angles < 0 {
+			return -1
+		}
+		if angles == 0 {
+			return i + 1
+		}
+	}
+	return -1
+}
+
+// isTypeArgumentsAhead distingue los argumentos de tipo de una llamada,
+// 'f<number>(x)', de una comparación: el '>' que los cierra debe ir seguido
+// de '('.
+func (p *Parser) isTypeArgumentsAhead() bool {
+	end := p.typeParametersEnd(p.position)
+	return end >= 0 && p.tokenTypeAt(end) == LPAREN
+}
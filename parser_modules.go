@@ -0,0 +1,198 @@
+package main
+
+// parseEnumDeclaration analiza '[const] enum Nombre { A, B = 2, C = "c" }'
+func (p *Parser) parseEnumDeclaration() Statement {
+	start := tokenStart(p.currentToken())
+	decl := &EnumDeclaration{}
+	defer func() { decl.Loc = p.rangeFrom(start) }()
+
+	if p.currentToken().Value == "const" {
+		decl.Const = true
+		p.position++
+	}
+	p.position++ // 'enum'
+	if !p.check(IDENTIFIER) {
+		p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" como nombre del enum")
+		return decl
+	}
+	decl.Name = identifierFromToken(p.currentToken())
+	p.position++
+
+	if !p.consume(LBRACE) {
+		return decl
+	}
+	for p.currentToken() != nil && !p.check(RBRACE) {
+		member := p.parseEnumMember()
+		if member == nil {
+			break
+		}
+		decl.Members = append(decl.Members, member)
+		if !p.check(COMMA) {
+			if token := p.currentToken(); token != nil && token.Type != RBRACE {
+				p.addError(codeUnexpectedToken, token, "Se esperaba ',' o '}' después del miembro '"+
+					member.Name.Name+"' del enum")
+			}
+			break
+		}
+		p.position++
+	}
+	p.consume(RBRACE)
+	return decl
+}
+
+// parseEnumMember analiza 'Nombre' o 'Nombre = valor'. El nombre puede ir
+// entre comillas, pero no puede ser un número.
+func (p *Parser) parseEnumMember() *EnumMember {
+	token := p.currentToken()
+	start := tokenStart(token)
+	member := &EnumMember{}
+	switch {
+	case token.Type == STRING:
+		member.Name = identifierFromToken(token)
+		member.Name.Name = stringContent(token.Value)
+	case isPropertyName(token):
+		member.Name = identifierFromToken(token)
+	default:
+		p.addError(codeExpectedIdentifier, token, errorIdentifier+" como nombre de un miembro del enum")
+		return nil
+	}
+	p.position++
+
+	if token := p.currentToken(); token != nil && token.Type == ASSIGNMENT && token.Value == "=" {
+		p.position++
+		member.Initializer = p.parseAssignment()
+	}
+	member.Loc = p.rangeFrom(start)
+	return member
+}
+
+// parseModuleDeclaration analiza 'namespace A.B { ... }' (o 'module'). Cada
+// nombre del camino es un namespace exportado dentro del anterior.
+func (p *Parser) parseModuleDeclaration() Statement {
+	token := p.currentToken()
+	if !p.atModuleLevel() {
+		p.addError(codeDeclarationNotAllowed, token, "Un "+token.Value+
+			" solo se puede declarar en el nivel superior del programa o de otro namespace")
+	}
+	p.position++
+
+	starts := []Position{tokenStart(token)}
+	chain := []*ModuleDeclaration{{Keyword: token.Value, Name: identifierFromToken(p.currentToken())}}
+	p.position++
+	for p.check(DOT) {
+		p.position++
+		if !p.check(IDENTIFIER) {
+			p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" después de '.' en el nombre del "+
+				token.Value)
+			break
+		}
+		starts = append(starts, tokenStart(p.currentToken()))
+		chain = append(chain, &ModuleDeclaration{Keyword: token.Value, Name: identifierFromToken(p.currentToken())})
+		p.position++
+	}
+
+	body := p.parseModuleBody()
+	for i := len(chain) - 1; i >= 0; i-- {
+		decl := chain[i]
+		if i == len(chain)-1 {
+			decl.Body = body
+		} else {
+			export := &ExportDeclaration{Declaration: chain[i+1]}
+			export.Loc = chain[i+1].Loc
+			decl.Body = &BlockStatement{Body: []Statement{export}}
+			decl.Body.Loc = export.Loc
+		}
+		decl.Loc = p.rangeFrom(starts[i])
+	}
+	return chain[0]
+}
+
+// parseModuleBody analiza el bloque de un namespace. Su interior vuelve a ser
+// nivel de módulo: admite 'export' y otros namespaces.
+func (p *Parser) parseModuleBody() *BlockStatement {
+	block := &BlockStatement{}
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba el cuerpo '{' del namespace")
+		return block
+	}
+	start := tokenStart(token)
+	defer func() { block.Loc = p.rangeFrom(start) }()
+
+	if !p.consume(LBRACE) {
+		return block
+	}
+	blockDepth := p.blockDepth
+	p.blockDepth = 0
+	block.Body = p.parseStatements()
+	p.blockDepth = blockDepth
+	p.consume(RBRACE)
+	return block
+}
+
+// parseExportDeclaration analiza 'export' seguido de una declaración
+func (p *Parser) parseExportDeclaration() Statement {
+	token := p.currentToken()
+	start := tokenStart(token)
+	if !p.atModuleLevel() {
+		p.addError(codeDeclarationNotAllowed, token,
+			"'export' solo se permite en el nivel superior del programa o de un namespace")
+	}
+	p.position++
+
+	if !p.isDeclarationStart() {
+		p.addError(codeUnexpectedToken, p.currentToken(), "Se esperaba una declaración después de 'export'")
+		return nil
+	}
+	export := &ExportDeclaration{Declaration: p.parseStatement()}
+	export.Loc = p.rangeFrom(start)
+	return export
+}
+
+// atModuleLevel indica si la sentencia actual está en el nivel superior del
+// programa o de un namespace, fuera de cualquier bloque.
+func (p *Parser) atModuleLevel() bool {
+	return p.blockDepth == 0 && p.loopDepth == 0 && p.switchDepth == 0
+}
+
+// isDeclarationStart indica si el token actual empieza una declaración que
+// se puede exportar.
+func (p *Parser) isDeclarationStart() bool {
+	token := p.currentToken()
+	switch {
+	case token == nil:
+		return false
+	case token.Type == FUNCTION:
+		return true
+	case token.Type == KEYWORD:
+		switch token.Value {
+		case "let", "const", "var", "class", "interface", "enum":
+			return true
+		}
+		return false
+	default:
+		return p.isTypeAliasStart() || p.isModuleStart()
+	}
+}
+
+// isEnumStart reconoce 'enum' y 'const enum'
+func (p *Parser) isEnumStart() bool {
+	token := p.currentToken()
+	if token == nil || token.Type != KEYWORD {
+		return false
+	}
+	if token.Value == "const" {
+		return p.position+1 < len(p.tokens) && p.tokens[p.position+1].Value == "enum"
+	}
+	return token.Value == "enum"
+}
+
+// isModuleStart distingue 'namespace Nombre' o 'module Nombre' de un uso de
+// esas palabras como nombre de variable.
+func (p *Parser) isModuleStart() bool {
+	token := p.currentToken()
+	if token == nil || token.Type != IDENTIFIER || (token.Value != "namespace" && token.Value != "module") {
+		return false
+	}
+	return p.tokenTypeAt(p.position+1) == IDENTIFIER && p.tokens[p.position+1].Line == token.Line
+}
@@ -0,0 +1,170 @@
+package main
+
+// isPatternStart indica si el token actual abre un patrón de desestructuración
+func (p *Parser) isPatternStart() bool {
+	return p.check(LBRACE) || p.check(LBRACKET)
+}
+
+// parseBindingTarget analiza el destino de una declaración: un nombre o un
+// patrón '{ ... }' o '[ ... ]', que pueden anidarse.
+func (p *Parser) parseBindingTarget(context string) Pattern {
+	token := p.currentToken()
+	switch {
+	case token == nil:
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": "+errorIdentifier+" "+context)
+		return nil
+	case token.Type == IDENTIFIER:
+		p.position++
+		return identifierFromToken(token)
+	case token.Type == LBRACKET:
+		return p.parseArrayPattern()
+	case token.Type == LBRACE:
+		return p.parseObjectPattern()
+	default:
+		p.addError(codeExpectedIdentifier, token, errorIdentifier+" o patrón de desestructuración "+context)
+		return nil
+	}
+}
+
+// parseArrayPattern analiza '[a, , b = 1, ...resto]'
+func (p *Parser) parseArrayPattern() Pattern {
+	start := tokenStart(p.currentToken())
+	p.position++ // '['
+
+	pattern := &ArrayPattern{Elements: make([]*BindingElement, 0, 2)}
+	for p.currentToken() != nil && !p.check(RBRACKET) {
+		if p.check(COMMA) {
+			pattern.Elements = append(pattern.Elements, nil)
+			p.position++
+			continue
+		}
+		if p.check(ELLIPSIS) {
+			p.position++
+			pattern.Rest = p.parseBindingTarget("después de '...'")
+			p.checkRestIsLast(pattern.Rest, RBRACKET)
+			break
+		}
+		element := p.parseBindingElement()
+		if element == nil {
+			break
+		}
+		pattern.Elements = append(pattern.Elements, element)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+	p.consume(RBRACKET)
+
+	pattern.Loc = p.rangeFrom(start)
+	return pattern
+}
+
+// parseBindingElement analiza 'destino = valor' dentro de un patrón de array
+func (p *Parser) parseBindingElement() *BindingElement {
+	start := tokenStart(p.currentToken())
+	target := p.parseBindingTarget("en el patrón de array")
+	if target == nil {
+		return nil
+	}
+	element := &BindingElement{Target: target, Default: p.parsePatternDefault()}
+	element.Loc = p.rangeFrom(start)
+	return element
+}
+
+// parseObjectPattern analiza '{ a, b: otro, c = 1, ...resto }'
+func (p *Parser) parseObjectPattern() Pattern {
+	start := tokenStart(p.currentToken())
+	p.position++ // '{'
+
+	pattern := &ObjectPattern{Properties: make([]*PatternProperty, 0, 2)}
+	for p.currentToken() != nil && !p.check(RBRACE) {
+		if p.check(ELLIPSIS) {
+			p.position++
+			rest := p.parseBindingTarget("después de '...'")
+			if id, ok := rest.(*Identifier); ok {
+				pattern.Rest = id
+			} else if rest != nil {
+				p.addErrorRange(codeUnexpectedToken, rest.Span(),
+					"El resto de un patrón de objeto debe ser un nombre, no otro patrón")
+			}
+			p.checkRestIsLast(rest, RBRACE)
+			break
+		}
+		prop := p.parsePatternProperty()
+		if prop == nil {
+			break
+		}
+		pattern.Properties = append(pattern.Properties, prop)
+		if !p.check(COMMA) {
+			break
+		}
+		p.position++
+	}
+	p.consume(RBRACE)
+
+	pattern.Loc = p.rangeFrom(start)
+	return pattern
+}
+
+// parsePatternProperty analiza 'clave: destino = valor' o la forma abreviada
+// 'nombre = valor', que declara una variable con el nombre de la propiedad.
+func (p *Parser) parsePatternProperty() *PatternProperty {
+	token := p.currentToken()
+	start := tokenStart(token)
+
+	key, computed := p.parsePropertyKey()
+	if key == nil {
+		return nil
+	}
+	prop := &PatternProperty{Key: key, Computed: computed}
+
+	if p.check(COLON) {
+		p.position++
+		prop.Target = p.parseBindingTarget("después de '" + exprString(key) + ":'")
+	} else if name, ok := key.(*Identifier); ok && !computed && token.Type == IDENTIFIER {
+		prop.Shorthand = true
+		prop.Target = name
+		copied := *name
+		prop.Key = &copied
+	} else {
+		p.addError(codeUnexpectedToken, p.currentToken(), "Se esperaba ':' y un destino después de la clave '"+
+			exprString(key)+"' del patrón")
+	}
+	prop.Default = p.parsePatternDefault()
+	prop.Loc = p.rangeFrom(start)
+	return prop
+}
+
+// parsePatternDefault analiza el '= valor' opcional de un elemento de un patrón
+func (p *Parser) parsePatternDefault() Expression {
+	token := p.currentToken()
+	if token == nil || token.Type != ASSIGNMENT || token.Value != "=" {
+		return nil
+	}
+	p.position++
+	return p.parseAssignment()
+}
+
+// checkRestIsLast reporta lo que siga al '...resto' de un patrón antes de su
+// cierre; el resto recoge todo lo demás y no admite valor por defecto.
+func (p *Parser) checkRestIsLast(rest Pattern, closing TokenType) {
+	token := p.currentToken()
+	if rest == nil || token == nil || token.Type == closing {
+		return
+	}
+	p.addError(codeUnexpectedToken, token, "El elemento rest '..."+patternString(rest)+
+		"' debe ser el último del patrón y no puede tener valor por defecto")
+	// Saltar hasta el cierre para no encadenar errores
+	for depth := 0; p.currentToken() != nil; p.position++ {
+		switch p.currentToken().Type {
+		case LBRACKET, LBRACE, LPAREN:
+			depth++
+		case RBRACKET, RBRACE, RPAREN:
+			if depth == 0 {
+				return
+			}
+			depth--
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+// El código a medio escribir no debe tumbar el análisis: el parser reporta
+// el final inesperado y devuelve lo que haya podido construir.
+func TestIncompleteForHeader(t *testing.T) {
+	for _, code := range []string{
+		"for(let i=0;i<3;",
+		"for(;;",
+		"for(let i=0, j=1;i<j;i++,",
+	} {
+		result := analyzeSource(code)
+		if result.IsValid {
+			t.Errorf("%q: se esperaba un código no válido", code)
+		}
+		if !hasDiagnostic(result.Diagnostics, codeUnexpectedEOF) {
+			t.Errorf("%q: falta el diagnóstico %q en %v", code, codeUnexpectedEOF, result.Diagnostics)
+		}
+	}
+}
+
+func TestObjectMethodShorthand(t *testing.T) {
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name:    "método abreviado",
+			code:    "let o = { m() { return 1; }, k: 2 }; o.m();",
+			notWant: []string{"not-callable", "return-outside-function", "unreachable-code", "unexpected-token"},
+		},
+		{
+			name:    "método abreviado genérico",
+			code:    "let o = { id<T>(x: T): T { return x; } }; o.id(1);",
+			notWant: []string{"not-callable", "return-outside-function", "unexpected-token"},
+		},
+		{
+			name: "tipo devuelto por un método abreviado",
+			code: "let o = { m() { return 1; } }; let r: string = o.m(); r;",
+			want: []string{"type-mismatch@1:48"},
+		},
+	})
+}
+
+func hasDiagnostic(diagnostics []Diagnostic, code string) bool {
+	for _, d := range diagnostics {
+		if d.Code == code {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package main
+
+// parseTypeAnnotation analiza el tipo que sigue a ':' en una declaración, un
+// parámetro o el retorno de una función.
+func (p *Parser) parseTypeAnnotation() TypeNode {
+	if !p.isTypeStart() {
+		p.addError(codeExpectedType, p.currentToken(), "Se esperaba tipo después de ':'")
+		return nil
+	}
+	return p.parseType()
+}
+
+// isTypeStart indica si el token actual puede empezar un tipo
+func (p *Parser) isTypeStart() bool {
+	token := p.currentToken()
+	if token == nil {
+		return false
+	}
+	switch token.Type {
+	case TYPE, IDENTIFIER, STRING, NUMBER, BOOLEAN, LBRACE, LPAREN:
+		return true
+	case COMPARISON:
+		return token.Value == "<"
+	case OPERATOR:
+		return token.Value == "|" || token.Value == "&" || token.Value == "-"
+	default:
+		return false
+	}
+}
+
+// parseType analiza un tipo completo: una unión de intersecciones de tipos
+// simples. Admite un '|' inicial, habitual al partir uniones en varias líneas.
+func (p *Parser) parseType() TypeNode {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba un tipo")
+		return nil
+	}
+	start := tokenStart(token)
+	if p.checkOperator("|") {
+		p.position++
+	}
+
+	first := p.parseIntersectionType()
+	if first == nil || !p.checkOperator("|") {
+		return first
+	}
+	union := &UnionType{Types: []TypeNode{first}}
+	for p.checkOperator("|") {
+		p.position++
+		t := p.parseIntersectionType()
+		if t == nil {
+			break
+		}
+		union.Types = append(union.Types, t)
+	}
+	union.Loc = p.rangeFrom(start)
+	return union
+}
+
+func (p *Parser) parseIntersectionType() TypeNode {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba un tipo")
+		return nil
+	}
+	start := tokenStart(token)
+	first := p.parseArrayType()
+	if first == nil || !p.checkOperator("&") {
+		return first
+	}
+	intersection := &IntersectionType{Types: []TypeNode{first}}
+	for p.checkOperator("&") {
+		p.position++
+		t := p.parseArrayType()
+		if t == nil {
+			break
+		}
+		intersection.Types = append(intersection.Types, t)
+	}
+	intersection.Loc = p.rangeFrom(start)
+	return intersection
+}
+
+// parseArrayType analiza un tipo simple seguido de cualquier número de '[]'
+func (p *Parser) parseArrayType() TypeNode {
+	token := p.currentToken()
+	if token == nil {
+		p.addError(codeUnexpectedEOF, nil, errorEOF+": se esperaba un tipo")
+		return nil
+	}
+	start := tokenStart(token)
+	t := p.parsePrimaryType()
+	for t != nil && p.check(LBRACKET) && p.tokenTypeAt(p.position+1) == RBRACKET {
+		p.position += 2
+		array := &ArrayType{Element: t}
+		array.Loc = p.rangeFrom(start)
+		t = array
+	}
+	return t
+}
+
+func (p *Parser) parsePrimaryType() TypeNode {
+	token := p.currentToken()
+	start := tokenStart(token)
+
+	switch {
+	case token.Type == TYPE || token.Type == IDENTIFIER:
+		return p.parseTypeReference()
+	case token.Type == STRING || token.Type == NUMBER || token.Type == BOOLEAN:
+		literal := &LiteralType{Literal: p.parsePrimary()}
+		literal.Loc = p.rangeFrom(start)
+		return literal
+	case token.Type == OPERATOR && token.Value == "-" && p.tokenTypeAt(p.position+1) == NUMBER:
+		p.position++
+		negative := &UnaryExpression{Operator: "-", Argument: p.parsePrimary()}
+		negative.Loc = p.rangeFrom(start)
+		literal := &LiteralType{Literal: negative}
+		literal.Loc = negative.Loc
+		return literal
+	case token.Type == LBRACE:
+		return p.parseObjectType()
+	case token.Type == LPAREN && p.isArrowAhead(), token.Type == COMPARISON && token.Value == "<":
+		return p.parseFunctionType()
+	case token.Type == LPAREN:
+		p.position++
+		t := p.parseType()
+		p.consume(RPAREN)
+		return t
+	default:
+		p.addError(codeExpectedType, token, "Se esperaba un tipo, se encontró "+string(token.Type)+" '"+token.Value+"'")
+		return nil
+	}
+}
+
+// parseTypeReference analiza un nombre de tipo con sus argumentos opcionales:
+// 'Punto', 'Array<number>'.
+func (p *Parser) parseTypeReference() TypeNode {
+	token := p.currentToken()
+	start := tokenStart(token)
+	p.position++
+	ref := &TypeReference{Name: token.Value}
+	// Nombre calificado por namespaces o enums: 'Geo.Punto', 'Color.Rojo'
+	for p.check(DOT) && p.tokenTypeAt(p.position+1) == IDENTIFIER {
+		ref.Name += "." + p.tokens[p.position+1].Value
+		p.position += 2
+	}
+
+	if p.checkComparison("<") {
+		ref.TypeArguments = p.parseTypeArguments(ref.Name)
+	}
+
+	ref.Loc = p.rangeFrom(start)
+	return ref
+}
+
+// parseFunctionType analiza '<T>(params) => tipo'
+func (p *Parser) parseFunctionType() TypeNode {
+	start := tokenStart(p.currentToken())
+	fn := &FunctionType{TypeParams: p.parseTypeParameters()}
+	fn.Params = p.parseParameters()
+	if p.consume(ARROW) {
+		fn.ReturnType = p.parseType()
+	}
+	fn.Loc = p.rangeFrom(start)
+	return fn
+}
+
+// parseObjectType analiza '{ miembros }' en una anotación de tipo
+func (p *Parser) parseObjectType() TypeNode {
+	start := tokenStart(p.currentToken())
+	p.position++ // '{'
+	obj := &ObjectType{Members: p.parseTypeMembers()}
+	p.consume(RBRACE)
+	obj.Loc = p.rangeFrom(start)
+	return obj
+}
+
+// parseTypeMembers analiza los miembros de una interfaz o de un tipo objeto
+// hasta la '}'. Se separan con ';', ',' o un salto de línea.
+func (p *Parser) parseTypeMembers() []*PropertySignature {
+	members := make([]*PropertySignature, 0, 4)
+	for p.currentToken() != nil && !p.check(RBRACE) {
+		start := p.position
+		if member := p.parsePropertySignature(); member != nil {
+			members = append(members, member)
+		}
+		if p.check(SEMICOLON) || p.check(COMMA) {
+			p.position++
+		}
+		// Garantizar progreso tras un miembro mal formado
+		if p.position == start {
+			p.position++
+		}
+	}
+	return members
+}
+
+// parsePropertySignature analiza '[readonly] nombre[?]: tipo' o el método
+// 'nombre[?]<T>(params): tipo'.
+func (p *Parser) parsePropertySignature() *PropertySignature {
+	start := tokenStart(p.currentToken())
+	member := &PropertySignature{}
+
+	// 'readonly' es modificador solo si le sigue el nombre de la propiedad
+	if p.currentToken().Value == "readonly" && p.position+1 < len(p.tokens) &&
+		isPropertyName(&p.tokens[p.position+1]) {
+		member.Readonly = true
+		p.position++
+	}
+
+	token := p.currentToken()
+	switch {
+	case token.Type == STRING:
+		member.Name = identifierFromToken(token)
+		member.Name.Name = stringContent(token.Value)
+	case isPropertyName(token):
+		member.Name = identifierFromToken(token)
+	default:
+		p.addError(codeExpectedIdentifier, token, errorIdentifier+" como nombre de propiedad")
+		return nil
+	}
+	p.position++
+
+	if p.check(QUESTION) {
+		member.Optional = true
+		p.position++
+	}
+
+	switch {
+	case p.check(LPAREN), p.checkComparison("<"):
+		method := &FunctionType{TypeParams: p.parseTypeParameters()}
+		method.Params = p.parseParameters()
+		if p.check(COLON) {
+			p.position++
+			method.ReturnType = p.parseTypeAnnotation()
+		}
+		method.Loc = p.rangeFrom(start)
+		member.Method = true
+		member.TypeAnnotation = method
+	case p.check(COLON):
+		p.position++
+		member.TypeAnnotation = p.parseTypeAnnotation()
+	default:
+		p.addError(codeExpectedType, p.currentToken(), "Se esperaba ':' y el tipo de la propiedad '"+
+			member.Name.Name+"'")
+	}
+
+	member.Loc = p.rangeFrom(start)
+	return member
+}
+
+// parseInterfaceDeclaration analiza 'interface Nombre<T> extends A, B { ... }'
+func (p *Parser) parseInterfaceDeclaration() Statement {
+	start := tokenStart(p.currentToken())
+	decl := &InterfaceDeclaration{}
+	defer func() { decl.Loc = p.rangeFrom(start) }()
+
+	p.position++ // 'interface'
+	if !p.check(IDENTIFIER) {
+		p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" como nombre de la interfaz")
+		return decl
+	}
+	decl.Name = identifierFromToken(p.currentToken())
+	p.position++
+	decl.TypeParams = p.parseTypeParameters()
+
+	if p.checkContextual("extends") {
+		p.position++
+		for {
+			if !p.check(IDENTIFIER) {
+				p.addError(codeExpectedIdentifier, p.currentToken(), errorIdentifier+" después de 'extends'")
+				break
+			}
+			decl.Extends = append(decl.Extends, p.parseTypeReference().(*TypeReference))
+			if !p.check(COMMA) {
+				break
+			}
+			p.position++
+		}
+	}
+
+	if !p.consume(LBRACE) {
+		return decl
+	}
+	decl.Members = p.parseTypeMembers()
+	p.consume(RBRACE)
+	return decl
+}
+
+// parseTypeAliasDeclaration analiza 'type Nombre<T> = tipo'
+func (p *Parser) parseTypeAliasDeclaration() Statement {
+	start := tokenStart(p.currentToken())
+	decl := &TypeAliasDeclaration{}
+	defer func() { decl.Loc = p.rangeFrom(start) }()
+
+	p.position++ // 'type'
+	decl.Name = identifierFromToken(p.currentToken())
+	p.position++
+	decl.TypeParams = p.parseTypeParameters()
+
+	if token := p.currentToken(); token == nil || token.Type != ASSIGNMENT || token.Value != "=" {
+		p.addError(codeUnexpectedToken, token, "Se esperaba '=' después del nombre del tipo '"+decl.Name.Name+"'")
+		return decl
+	}
+	p.position++
+	decl.Type = p.parseType()
+
+	if p.check(SEMICOLON) {
+		p.position++
+	}
+	return decl
+}
+
+// isTypeAliasStart distingue 'type Nombre = ...' de un uso de 'type' como
+// nombre de variable.
+func (p *Parser) isTypeAliasStart() bool {
+	token := p.currentToken()
+	return token != nil && token.Type == IDENTIFIER && token.Value == "type" &&
+		p.tokenTypeAt(p.position+1) == IDENTIFIER
+}
+
+func (p *Parser) checkOperator(value string) bool {
+	token := p.currentToken()
+	return token != nil && token.Type == OPERATOR && token.Value == value
+}
+
+func (p *Parser) checkComparison(value string) bool {
+	token := p.currentToken()
+	return token != nil && token.Type == COMPARISON && token.Value == value
+}
@@ -0,0 +1,741 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+type ScopeKind string
+
+const (
+	ScopeGlobal   ScopeKind = "global"
+	ScopeFunction ScopeKind = "function"
+	ScopeBlock    ScopeKind = "block"
+	ScopeForInit  ScopeKind = "for-init"
+	ScopeEnum     ScopeKind = "enum"
+	ScopeModule   ScopeKind = "namespace"
+)
+
+// Scope es un nodo del árbol de ámbitos léxicos. Cada ámbito guarda sus
+// propias variables; la búsqueda sube por los padres hasta el global.
+type Scope struct {
+	Kind      ScopeKind
+	Node      Node
+	Parent    *Scope
+	Children  []*Scope
+	variables map[string]*VariableInfo
+	types     map[string]*TypeSymbol // tipos con nombre, en su propio espacio de nombres
+}
+
+// TypeSymbol es un tipo con nombre declarado por el programa. Las interfaces
+// con el mismo nombre se fusionan, así que puede tener varias declaraciones.
+type TypeSymbol struct {
+	Name     string
+	Decls    []Node // *InterfaceDeclaration, *TypeAliasDeclaration, *ClassDeclaration, *EnumDeclaration o *TypeParameter
+	Scope    *Scope
+	Type     *Type // se calcula al comprobar los tipos
+	Exported bool
+}
+
+func NewScope(kind ScopeKind, node Node, parent *Scope) *Scope {
+	scope := &Scope{
+		Kind:      kind,
+		Node:      node,
+		Parent:    parent,
+		variables: make(map[string]*VariableInfo, 4),
+	}
+	if parent != nil {
+		parent.Children = append(parent.Children, scope)
+	}
+	return scope
+}
+
+// LookupLocal busca el nombre solo en este ámbito
+func (sc *Scope) LookupLocal(name string) *VariableInfo {
+	return sc.variables[name]
+}
+
+// Lookup busca el nombre en este ámbito y en sus ancestros
+func (sc *Scope) Lookup(name string) *VariableInfo {
+	for scope := sc; scope != nil; scope = scope.Parent {
+		if info, ok := scope.variables[name]; ok {
+			return info
+		}
+	}
+	return nil
+}
+
+// FunctionScope devuelve el ámbito de función, namespace o global más
+// cercano, que es donde se elevan las declaraciones 'var'.
+func (sc *Scope) FunctionScope() *Scope {
+	scope := sc
+	for scope.Kind != ScopeFunction && scope.Kind != ScopeModule && scope.Kind != ScopeGlobal && scope.Parent != nil {
+		scope = scope.Parent
+	}
+	return scope
+}
+
+func (sc *Scope) declare(info *VariableInfo) {
+	info.Scope = sc
+	sc.variables[info.Name] = info
+}
+
+// LookupType busca un tipo con nombre en este ámbito y en sus ancestros
+func (sc *Scope) LookupType(name string) *TypeSymbol {
+	for scope := sc; scope != nil; scope = scope.Parent {
+		if sym, ok := scope.types[name]; ok {
+			return sym
+		}
+	}
+	return nil
+}
+
+func (sc *Scope) declareType(sym *TypeSymbol) {
+	if sc.types == nil {
+		sc.types = make(map[string]*TypeSymbol, 2)
+	}
+	sym.Scope = sc
+	sc.types[sym.Name] = sym
+}
+
+// Palabras con las que se registran en la tabla de símbolos las funciones,
+// los parámetros, las clases, los enums y los namespaces, que no tienen
+// 'let'/'const'/'var'.
+const (
+	keywordFunction   = "function"
+	keywordParam      = "param"
+	keywordClass      = "class"
+	keywordEnum       = "enum"
+	keywordEnumMember = "member"
+	keywordNamespace  = "namespace"
+)
+
+// isBlockScoped indica si la declaración vive en el bloque ('let', 'const' y
+// los tipos estilo C) o se eleva a la función ('var').
+func isBlockScoped(keyword string) bool {
+	return keyword != "var"
+}
+
+// ---------------------------------------------------------------------------
+// Construcción del árbol de ámbitos y resolución de nombres
+// ---------------------------------------------------------------------------
+
+// bindScopes construye el árbol de ámbitos, declara cada variable en el
+// ámbito que le corresponde y resuelve cada identificador usado contra él.
+func (s *Semantic) bindScopes() {
+	s.global = NewScope(ScopeGlobal, s.program, nil)
+	s.hoistVarDeclarations(s.program, s.global)
+	s.bindStatements(s.program.Body, s.global)
+
+	sort.SliceStable(s.symbols, func(i, j int) bool {
+		return s.symbols[i].Loc.Start.Offset < s.symbols[j].Loc.Start.Offset
+	})
+}
+
+// hoistVarDeclarations declara todas las 'var' del cuerpo en el ámbito de
+// función antes de recorrerlo, igual que hace JavaScript. Las funciones y los
+// namespaces anidados elevan las suyas a su propio ámbito.
+func (s *Semantic) hoistVarDeclarations(body Node, functionScope *Scope) {
+	Inspect(body, func(n Node) bool {
+		if _, ok := n.(*ModuleDeclaration); ok || isFunctionNode(n) {
+			return false
+		}
+		decl, ok := n.(*VariableDeclaration)
+		if !ok || isBlockScoped(decl.Keyword) {
+			return true
+		}
+		for _, declarator := range decl.Declarations {
+			for _, name := range declaratorNames(declarator) {
+				if existing := functionScope.LookupLocal(name.Name); existing != nil {
+					// 'var' repetida: es la misma variable
+					s.varRepeats[declarator] = existing
+					continue
+				}
+				s.declareVariable(decl, declarator, name, functionScope)
+			}
+		}
+		return true
+	})
+}
+
+// bindStatements declara primero los 'let'/'const' del bloque, que son
+// visibles en todo el bloque, y después recorre cada sentencia.
+func (s *Semantic) bindStatements(statements []Statement, scope *Scope) {
+	s.declareLexical(statements, scope)
+	for _, stmt := range statements {
+		s.bindStatement(stmt, scope)
+	}
+}
+
+// declareLexical declara los 'let'/'const' y las funciones que aparecen
+// directamente en la lista de sentencias (no los de bloques anidados). Las
+// funciones quedan así disponibles desde el inicio del bloque.
+func (s *Semantic) declareLexical(statements []Statement, scope *Scope) {
+	for _, stmt := range statements {
+		if export, ok := stmt.(*ExportDeclaration); ok {
+			stmt = export.Declaration
+		}
+		switch decl := stmt.(type) {
+		case *VariableDeclaration:
+			if isBlockScoped(decl.Keyword) {
+				s.declareBlockScoped(decl, scope)
+			}
+		case *FunctionDeclaration:
+			if decl.Name != nil {
+				s.declareUnique(s.newSymbol(decl.Name, keywordFunction, typeFunction, decl), scope)
+			}
+		case *ClassDeclaration:
+			// Una clase es a la vez un valor (el constructor) y un tipo (sus
+			// instancias); si el nombre ya existe se reporta una sola vez
+			if decl.Name == nil {
+				continue
+			}
+			redeclared := scope.LookupLocal(decl.Name.Name) != nil
+			s.declareUnique(s.newSymbol(decl.Name, keywordClass, typeClass, decl), scope)
+			if !redeclared {
+				s.declareType(decl.Name, decl, scope)
+			}
+		case *EnumDeclaration:
+			// Como una clase, un enum es un valor y un tipo
+			if decl.Name == nil {
+				continue
+			}
+			redeclared := scope.LookupLocal(decl.Name.Name) != nil
+			s.declareUnique(s.newSymbol(decl.Name, keywordEnum, typeEnum, decl), scope)
+			if !redeclared {
+				s.declareType(decl.Name, decl, scope)
+			}
+		case *ModuleDeclaration:
+			s.declareUnique(s.newSymbol(decl.Name, keywordNamespace, typeModule, decl), scope)
+		case *InterfaceDeclaration:
+			s.declareType(decl.Name, decl, scope)
+		case *TypeAliasDeclaration:
+			s.declareType(decl.Name, decl, scope)
+		}
+	}
+}
+
+// declareType registra una interfaz, un alias, una clase o un enum. Dos
+// interfaces con el mismo nombre se fusionan; cualquier otra repetición es un
+// error.
+func (s *Semantic) declareType(name *Identifier, decl Node, scope *Scope) {
+	if name == nil {
+		return
+	}
+	if existing := scope.types[name.Name]; existing != nil {
+		_, isInterface := decl.(*InterfaceDeclaration)
+		_, wasInterface := existing.Decls[0].(*InterfaceDeclaration)
+		if isInterface && wasInterface {
+			existing.Decls = append(existing.Decls, decl)
+			return
+		}
+		s.report(newDiagnostic(PhaseSemantic, SeverityError, codeRedeclaredVariable, name.Loc,
+			"Tipo '"+name.Name+"' ya fue declarado en este ámbito").
+			withRelated(existing.Decls[0].Span(), "Declaración original de '"+name.Name+"'"))
+		return
+	}
+
+	sym := &TypeSymbol{Name: name.Name, Decls: []Node{decl}}
+	scope.declareType(sym)
+	s.typeSymbols = append(s.typeSymbols, sym)
+}
+
+// declareTypeParameters registra los parámetros de tipo de una declaración
+// genérica en el ámbito donde son visibles y enlaza sus restricciones y
+// valores por defecto, que pueden nombrar a cualquiera de ellos.
+func (s *Semantic) declareTypeParameters(params []*TypeParameter, scope *Scope) {
+	for _, param := range params {
+		if existing := scope.types[param.Name.Name]; existing != nil {
+			s.report(newDiagnostic(PhaseSemantic, SeverityError, codeRedeclaredVariable, param.Name.Loc,
+				"El parámetro de tipo '"+param.Name.Name+"' está repetido").
+				withRelated(existing.Decls[0].Span(), "Declaración original de '"+param.Name.Name+"'"))
+			continue
+		}
+		scope.declareType(&TypeSymbol{Name: param.Name.Name, Decls: []Node{param}})
+	}
+	for _, param := range params {
+		s.bindType(param.Constraint, scope)
+		s.bindType(param.Default, scope)
+	}
+}
+
+// genericScope abre el ámbito de los parámetros de tipo de una interfaz, un
+// alias, una clase o un tipo función genéricos. Sin parámetros de tipo
+// devuelve el mismo ámbito.
+func (s *Semantic) genericScope(node Node, params []*TypeParameter, scope *Scope) *Scope {
+	if len(params) == 0 {
+		return scope
+	}
+	inner := NewScope(ScopeBlock, node, scope)
+	s.declareTypeParameters(params, inner)
+	return inner
+}
+
+// bindType enlaza cada nombre de tipo de la anotación con la interfaz o el
+// alias visible desde el ámbito. Los nombres predefinidos ('number', 'Array')
+// se resuelven al comprobar los tipos, igual que los calificados ('Geo.Punto'),
+// cuyo namespace puede no haberse recorrido todavía.
+func (s *Semantic) bindType(t TypeNode, scope *Scope) {
+	if t == nil {
+		return
+	}
+	Inspect(t, func(n Node) bool {
+		if fn, ok := n.(*FunctionType); ok && len(fn.TypeParams) > 0 {
+			inner := s.genericScope(fn, fn.TypeParams, scope)
+			for _, param := range fn.Params {
+				s.bindType(param.TypeAnnotation, inner)
+			}
+			s.bindType(fn.ReturnType, inner)
+			return false
+		}
+		ref, ok := n.(*TypeReference)
+		if !ok {
+			return true
+		}
+		if sym := scope.LookupType(ref.Name); sym != nil {
+			s.typeTargets[ref] = sym
+		} else if dot := strings.IndexByte(ref.Name, '.'); dot > 0 {
+			s.typeScopes[ref] = scope
+			if info := scope.Lookup(ref.Name[:dot]); info != nil {
+				info.References++
+			}
+		}
+		return true
+	})
+}
+
+func (s *Semantic) bindStatement(stmt Statement, scope *Scope) {
+	switch n := stmt.(type) {
+	case *VariableDeclaration:
+		if !isBlockScoped(n.Keyword) {
+			s.checkVarConflicts(n, scope)
+		}
+		for _, declarator := range n.Declarations {
+			s.bindType(declarator.TypeAnnotation, scope)
+			s.bindExpression(declarator.Init, scope)
+			s.bindPattern(declarator.Pattern, scope)
+		}
+	case *BlockStatement:
+		s.bindStatements(n.Body, NewScope(ScopeBlock, n, scope))
+	case *ForStatement:
+		forScope := NewScope(ScopeForInit, n, scope)
+		switch init := n.Init.(type) {
+		case *VariableDeclaration:
+			if isBlockScoped(init.Keyword) {
+				s.declareBlockScoped(init, forScope)
+			}
+			s.bindStatement(init, forScope)
+		case Expression:
+			s.bindExpression(init, forScope)
+		}
+		s.bindExpression(n.Test, forScope)
+		s.bindExpression(n.Update, forScope)
+		if n.Body != nil {
+			s.bindStatement(n.Body, forScope)
+		}
+	case *ForInStatement:
+		s.bindForEach(n, n.Left, n.Right, n.Body, scope)
+	case *ForOfStatement:
+		s.bindForEach(n, n.Left, n.Right, n.Body, scope)
+	case *DoWhileStatement:
+		if n.Body != nil {
+			s.bindStatement(n.Body, scope)
+		}
+		s.bindExpression(n.Test, scope)
+	case *WhileStatement:
+		s.bindExpression(n.Test, scope)
+		if n.Body != nil {
+			s.bindStatement(n.Body, scope)
+		}
+	case *IfStatement:
+		s.bindExpression(n.Test, scope)
+		if n.Consequent != nil {
+			s.bindStatement(n.Consequent, scope)
+		}
+		if n.Alternate != nil {
+			s.bindStatement(n.Alternate, scope)
+		}
+	case *SwitchStatement:
+		s.bindExpression(n.Discriminant, scope)
+		// Todos los 'case' comparten un único bloque de ámbito
+		caseScope := NewScope(ScopeBlock, n, scope)
+		for _, c := range n.Cases {
+			s.declareLexical(c.Consequent, caseScope)
+		}
+		for _, c := range n.Cases {
+			s.bindExpression(c.Test, caseScope)
+			for _, stmt := range c.Consequent {
+				s.bindStatement(stmt, caseScope)
+			}
+		}
+	case *ReturnStatement:
+		s.bindExpression(n.Argument, scope)
+	case *FunctionDeclaration:
+		s.bindFunction(n, scope)
+	case *InterfaceDeclaration:
+		inner := s.genericScope(n, n.TypeParams, scope)
+		for _, ref := range n.Extends {
+			s.bindType(ref, inner)
+		}
+		for _, member := range n.Members {
+			s.bindType(member.TypeAnnotation, inner)
+		}
+	case *TypeAliasDeclaration:
+		s.bindType(n.Type, s.genericScope(n, n.TypeParams, scope))
+	case *ClassDeclaration:
+		s.bindClass(n, scope)
+	case *EnumDeclaration:
+		s.bindEnum(n, scope)
+	case *ModuleDeclaration:
+		s.bindModule(n, scope)
+	case *ExportDeclaration:
+		s.markExported(n.Declaration, scope)
+		s.bindStatement(n.Declaration, scope)
+	case *ExpressionStatement:
+		s.bindExpression(n.Expression, scope)
+	}
+}
+
+// bindExpression resuelve los identificadores de la expresión y crea el
+// ámbito de cada función anidada en ella (callbacks, arrow functions...).
+func (s *Semantic) bindExpression(expr Expression, scope *Scope) {
+	if expr == nil {
+		return
+	}
+	forEachReference(expr, func(id *Identifier) {
+		s.resolve(id, scope)
+	})
+	Inspect(expr, func(n Node) bool {
+		if isFunctionNode(n) {
+			s.bindFunction(n, scope)
+			return false
+		}
+		return true
+	})
+}
+
+// bindFunction crea el ámbito de la función con sus parámetros y recorre el
+// cuerpo. Los parámetros comparten ámbito con las declaraciones del cuerpo,
+// así que 'function f(x) { let x }' es una redeclaración. Los parámetros de
+// tipo también viven en ese ámbito y las anotaciones se resuelven desde él.
+func (s *Semantic) bindFunction(fn Node, scope *Scope) {
+	name, params, returnType, body := functionParts(fn)
+	functionScope := NewScope(ScopeFunction, fn, scope)
+	s.declareTypeParameters(functionTypeParams(fn), functionScope)
+	s.bindType(returnType, functionScope)
+
+	// El nombre de una función usada como valor solo es visible dentro de ella
+	if _, ok := fn.(*FunctionExpression); ok && name != nil {
+		functionScope.declare(s.newSymbol(name, keywordFunction, typeFunction, fn))
+	}
+	for _, param := range params {
+		s.bindType(param.TypeAnnotation, functionScope)
+		s.bindExpression(param.Default, functionScope)
+		s.bindPattern(param.Pattern, functionScope)
+		for _, name := range parameterNames(param) {
+			s.declareUnique(s.newSymbol(name, keywordParam, s.paramType(param), param), functionScope)
+		}
+	}
+
+	switch b := body.(type) {
+	case *BlockStatement:
+		s.hoistVarDeclarations(b, functionScope)
+		s.bindStatements(b.Body, functionScope)
+	case Expression:
+		s.bindExpression(b, functionScope)
+	}
+}
+
+// bindClass resuelve la clase base, las interfaces implementadas y los
+// miembros. Cada método tiene su propio ámbito de función, dentro del de los
+// parámetros de tipo de la clase.
+func (s *Semantic) bindClass(class *ClassDeclaration, scope *Scope) {
+	if class.SuperClass != nil {
+		s.resolve(class.SuperClass, scope)
+	}
+	inner := s.genericScope(class, class.TypeParams, scope)
+	for _, arg := range class.SuperTypeArguments {
+		s.bindType(arg, inner)
+	}
+	for _, ref := range class.Implements {
+		s.bindType(ref, inner)
+	}
+	for _, member := range class.Members {
+		switch m := member.(type) {
+		case *PropertyDefinition:
+			s.bindType(m.TypeAnnotation, inner)
+			s.bindExpression(m.Value, inner)
+		case *MethodDefinition:
+			if m.Function != nil {
+				s.bindFunction(m.Function, inner)
+			}
+		}
+	}
+}
+
+// bindEnum declara los miembros en un ámbito propio, donde el valor de cada
+// uno puede usar los anteriores por su nombre.
+func (s *Semantic) bindEnum(enum *EnumDeclaration, scope *Scope) {
+	if enum.Name == nil {
+		return
+	}
+	enumScope := NewScope(ScopeEnum, enum, scope)
+	for _, member := range enum.Members {
+		if existing := enumScope.LookupLocal(member.Name.Name); existing != nil {
+			s.report(newDiagnostic(PhaseSemantic, SeverityError, codeRedeclaredVariable, member.Name.Loc,
+				"El miembro '"+member.Name.Name+"' ya existe en el enum '"+enum.Name.Name+"'").
+				withRelated(existing.Loc, "Declaración original de '"+member.Name.Name+"'"))
+			continue
+		}
+		info := s.newSymbol(member.Name, keywordEnumMember, enum.Name.Name, member)
+		enumScope.declare(info)
+		s.symbols = append(s.symbols, info)
+	}
+	for _, member := range enum.Members {
+		s.bindExpression(member.Initializer, enumScope)
+	}
+}
+
+// bindModule recorre el cuerpo del namespace en su propio ámbito, que es
+// también donde se elevan sus 'var'.
+func (s *Semantic) bindModule(module *ModuleDeclaration, scope *Scope) {
+	if module.Body == nil {
+		return
+	}
+	moduleScope := NewScope(ScopeModule, module, scope)
+	s.namespaces[module] = &namespaceTypes{scope: moduleScope}
+	s.hoistVarDeclarations(module.Body, moduleScope)
+	s.bindStatements(module.Body.Body, moduleScope)
+}
+
+// markExported marca como exportados los valores y tipos que declara la
+// sentencia, que ya están registrados en el ámbito.
+func (s *Semantic) markExported(decl Statement, scope *Scope) {
+	values, types := declaredNames(decl)
+	for _, name := range values {
+		if info := scope.LookupLocal(name.Name); info != nil {
+			info.Exported = true
+		}
+	}
+	for _, name := range types {
+		if sym := scope.types[name.Name]; sym != nil {
+			sym.Exported = true
+		}
+	}
+}
+
+func (s *Semantic) resolve(id *Identifier, scope *Scope) {
+	if info := scope.Lookup(id.Name); info != nil {
+		info.References++
+		s.references[id] = info
+		return
+	}
+	s.unresolved = append(s.unresolved, id)
+}
+
+// declareBlockScoped declara las variables de un 'let'/'const' en el ámbito
+// del bloque, reportando las que ya existían en ese mismo ámbito.
+func (s *Semantic) declareBlockScoped(decl *VariableDeclaration, scope *Scope) {
+	for _, declarator := range decl.Declarations {
+		for _, name := range declaratorNames(declarator) {
+			if s.isRedeclaration(name.Name, name.Loc, scope) {
+				continue
+			}
+			s.declareVariable(decl, declarator, name, scope)
+		}
+	}
+}
+
+// declareUnique declara el símbolo salvo que el nombre ya exista en el ámbito
+func (s *Semantic) declareUnique(info *VariableInfo, scope *Scope) {
+	if s.isRedeclaration(info.Name, info.Loc, scope) {
+		return
+	}
+	scope.declare(info)
+	s.symbols = append(s.symbols, info)
+}
+
+func (s *Semantic) isRedeclaration(name string, loc Range, scope *Scope) bool {
+	existing := scope.LookupLocal(name)
+	if existing == nil {
+		return false
+	}
+	// Las 'var' se declaran antes que los 'let' del mismo ámbito: el error va
+	// en la declaración que aparece después en el código
+	original := existing.Loc
+	if loc.Start.Offset < original.Start.Offset {
+		loc, original = original, loc
+	}
+	s.report(newDiagnostic(PhaseSemantic, SeverityError, codeRedeclaredVariable, loc,
+		"Variable '"+name+"' ya fue declarada en este ámbito").
+		withRelated(original, "Declaración original de '"+name+"'"))
+	return true
+}
+
+// checkVarConflicts detecta una 'var' que choca con un 'let'/'const' del mismo
+// nombre en algún bloque entre la declaración y su ámbito de función.
+func (s *Semantic) checkVarConflicts(decl *VariableDeclaration, scope *Scope) {
+	functionScope := scope.FunctionScope()
+	for _, declarator := range decl.Declarations {
+		for _, name := range declaratorNames(declarator) {
+			for current := scope; current != nil; current = current.Parent {
+				existing := current.LookupLocal(name.Name)
+				// Una 'var' puede repetir el nombre de un parámetro: es la misma variable
+				if existing != nil && isBlockScoped(existing.Keyword) && existing.Keyword != keywordParam {
+					loc, original, keyword := name.Loc, existing.Loc, existing.Keyword
+					if loc.Start.Offset < original.Start.Offset {
+						loc, original, keyword = original, loc, decl.Keyword
+					}
+					s.report(newDiagnostic(PhaseSemantic, SeverityError, codeRedeclaredVariable, loc,
+						"Variable '"+name.Name+"' ya fue declarada con '"+keyword+"' en este ámbito").
+						withRelated(original, "Declaración original de '"+name.Name+"'"))
+					break
+				}
+				if current == functionScope {
+					break
+				}
+			}
+		}
+	}
+}
+
+// declareVariable declara una de las variables del declarador: su nombre o
+// uno de los nombres de su patrón, que no tienen anotación propia.
+func (s *Semantic) declareVariable(decl *VariableDeclaration, declarator *VariableDeclarator, name *Identifier,
+	scope *Scope) {
+	varType := s.inferType(decl.Keyword)
+	if declarator.TypeAnnotation != nil && declarator.Pattern == nil {
+		varType = typeNodeString(declarator.TypeAnnotation)
+	}
+
+	info := &VariableInfo{
+		Name:         name.Name,
+		Keyword:      decl.Keyword,
+		Type:         varType,
+		InitialValue: exprString(declarator.Init),
+		Line:         decl.Loc.Start.Line,
+		Column:       decl.Loc.Start.Column,
+		Loc:          name.Loc,
+		Node:         declarator,
+		Declarator:   declarator,
+	}
+	scope.declare(info)
+	s.symbols = append(s.symbols, info)
+}
+
+// newSymbol crea la entrada de una función o un parámetro; node es el nodo
+// que lo declara.
+func (s *Semantic) newSymbol(name *Identifier, keyword, varType string, node Node) *VariableInfo {
+	start := node.Span().Start
+	return &VariableInfo{
+		Name:    name.Name,
+		Keyword: keyword,
+		Type:    varType,
+		Line:    start.Line,
+		Column:  start.Column,
+		Loc:     name.Loc,
+		Node:    node,
+	}
+}
+
+func (s *Semantic) paramType(param *Parameter) string {
+	if param.TypeAnnotation != nil && param.Pattern == nil {
+		return typeNodeString(param.TypeAnnotation)
+	}
+	return typeAny
+}
+
+// bindForEach resuelve un 'for...of' o 'for...in'. La variable declarada en la
+// cabecera recibe en cada vuelta un elemento de right; se recuerda su bucle
+// para deducir de él su tipo.
+func (s *Semantic) bindForEach(loop, left Node, right Expression, body Statement, scope *Scope) {
+	forScope := NewScope(ScopeForInit, loop, scope)
+	switch left := left.(type) {
+	case *VariableDeclaration:
+		if isBlockScoped(left.Keyword) {
+			s.declareBlockScoped(left, forScope)
+		}
+		s.bindStatement(left, forScope)
+		for _, declarator := range left.Declarations {
+			s.forEachHeads[declarator] = loop
+		}
+	case Expression:
+		s.bindExpression(left, forScope)
+	}
+	s.bindExpression(right, forScope)
+	if body != nil {
+		s.bindStatement(body, forScope)
+	}
+}
+
+// isFunctionNode indica si el nodo abre un ámbito de función
+func isFunctionNode(n Node) bool {
+	switch n.(type) {
+	case *FunctionDeclaration, *FunctionExpression, *ArrowFunction:
+		return true
+	default:
+		return false
+	}
+}
+
+// functionParts da acceso uniforme a las partes de cualquier tipo de función
+func functionParts(fn Node) (*Identifier, []*Parameter, TypeNode, Node) {
+	switch f := fn.(type) {
+	case *FunctionDeclaration:
+		return f.Name, f.Params, f.ReturnType, blockNode(f.Body)
+	case *FunctionExpression:
+		return f.Name, f.Params, f.ReturnType, blockNode(f.Body)
+	case *ArrowFunction:
+		return nil, f.Params, f.ReturnType, f.Body
+	default:
+		return nil, nil, nil, nil
+	}
+}
+
+// functionTypeParams devuelve los parámetros de tipo de una función genérica
+func functionTypeParams(fn Node) []*TypeParameter {
+	switch f := fn.(type) {
+	case *FunctionDeclaration:
+		return f.TypeParams
+	case *FunctionExpression:
+		return f.TypeParams
+	case *ArrowFunction:
+		return f.TypeParams
+	default:
+		return nil
+	}
+}
+
+// declaredNames devuelve los nombres que declara la sentencia como valores y
+// como tipos. Una clase o un enum declaran el mismo nombre en ambos.
+func declaredNames(decl Statement) (values, types []*Identifier) {
+	switch d := decl.(type) {
+	case *VariableDeclaration:
+		for _, declarator := range d.Declarations {
+			values = append(values, declaratorNames(declarator)...)
+		}
+	case *FunctionDeclaration:
+		values = append(values, d.Name)
+	case *ModuleDeclaration:
+		values = append(values, d.Name)
+	case *ClassDeclaration:
+		values, types = []*Identifier{d.Name}, []*Identifier{d.Name}
+	case *EnumDeclaration:
+		values, types = []*Identifier{d.Name}, []*Identifier{d.Name}
+	case *InterfaceDeclaration:
+		types = append(types, d.Name)
+	case *TypeAliasDeclaration:
+		types = append(types, d.Name)
+	}
+	// Las declaraciones incompletas pueden no tener nombre
+	valid := func(names []*Identifier) []*Identifier {
+		result := names[:0]
+		for _, name := range names {
+			if name != nil {
+				result = append(result, name)
+			}
+		}
+		return result
+	}
+	return valid(values), valid(types)
+}
@@ -2,48 +2,96 @@ package main
 
 import (
 	"strconv"
-	"unicode"
 )
 
 type Semantic struct {
-	tokens      []Token
-	variables   map[string]VariableInfo
-	information []string
+	program      *Program
+	global       *Scope
+	symbols      []*VariableInfo               // todas las variables, en orden de declaración
+	references   map[*Identifier]*VariableInfo // uso -> variable a la que se resolvió
+	unresolved   []*Identifier
+	types        map[Expression]*Type // tipo inferido de cada expresión
+	symbolTypes  map[*VariableInfo]*Type
+	typeNodes    map[TypeNode]*Type
+	typeSymbols  []*TypeSymbol
+	typeTargets  map[*TypeReference]*TypeSymbol // interfaz o alias al que apunta cada nombre de tipo
+	signatures   map[Node]*Type                 // tipo de cada función, con su firma
+	returnTypes  []*Type                        // tipo de retorno anotado de cada función en curso (nil si no tiene)
+	classes      map[*ClassDeclaration]*classTypes
+	contexts     []*classContext        // clase y 'this' del código en curso
+	methods      map[Node]*classContext // contexto de cada método, por su función
+	enums        map[*EnumDeclaration]*enumTypes
+	namespaces   map[*ModuleDeclaration]*namespaceTypes
+	typeScopes   map[*TypeReference]*Scope // ámbito desde el que se resuelve cada nombre de tipo calificado
+	typeParams   map[*TypeParameter]*Type
+	instances    map[*Type]map[string]*Type            // instancias de cada tipo genérico, por sus argumentos
+	incomplete   map[*Type][]*Type                     // instancias pendientes de un genérico que aún se está construyendo
+	expanding    int                                   // profundidad de instanciaciones anidadas
+	instantiated int                                   // instancias construidas desde la instanciación más externa
+	patternTypes map[Node]*Type                        // tipo de cada patrón de desestructuración y de cada nombre que declara
+	forEachHeads map[*VariableDeclarator]Node          // bucle 'for...of' o 'for...in' que da valor a cada variable de su cabecera
+	varRepeats   map[*VariableDeclarator]*VariableInfo // variable que vuelve a declarar cada 'var' repetida
+	loops        []LoopReport
+	flow         []*CFG // grafo de flujo de control del programa y de cada función
+	information  []string
+	diagnostics  []Diagnostic
 }
 
+// VariableInfo es la entrada de la tabla de símbolos para una variable
 type VariableInfo struct {
 	Name         string
+	Keyword      string // let, const, var o tipo estilo C
 	Type         string
 	InitialValue string
 	Line         int
 	Column       int
-}
-
-// Mapa estático global para palabras reservadas (máxima eficiencia)
-var reservedWords = map[string]bool{
-	"console": true,
-	"log":     true,
-	"system":  true,
-	"out":     true,
-	"println": true,
-	"print":   true,
-	"length":  true,
+	Loc          Range // rango del nombre en la declaración
+	Node         Node  // declarador, parámetro o función que lo declara
+	Declarator   *VariableDeclarator
+	Scope        *Scope
+	References   int
+	Exported     bool
 }
 
 // Strings constantes para tipos de inferencia (evitar creaciones repetidas)
 const (
 	typeNumber   = "number"
 	typeString   = "string"
+	typeBoolean  = "boolean"
 	typeVariable = "variable"
 	typeConstant = "constant"
 	typeUnknown  = "unknown"
+	typeFunction = "function"
+	typeClass    = "class"
+	typeEnum     = "enum"
+	typeModule   = "namespace"
+	typeAny      = "any"
 )
 
-func NewSemantic(tokens []Token) *Semantic {
+func NewSemantic(program *Program) *Semantic {
 	return &Semantic{
-		tokens:      tokens,
-		variables:   make(map[string]VariableInfo, 16), // Pre-allocar con capacidad
-		information: make([]string, 0, 32),             // Pre-allocar
+		program:      program,
+		symbols:      make([]*VariableInfo, 0, 16), // Pre-allocar con capacidad
+		references:   make(map[*Identifier]*VariableInfo, 32),
+		types:        make(map[Expression]*Type, 32),
+		symbolTypes:  make(map[*VariableInfo]*Type, 16),
+		typeNodes:    make(map[TypeNode]*Type, 16),
+		typeTargets:  make(map[*TypeReference]*TypeSymbol, 8),
+		signatures:   make(map[Node]*Type, 8),
+		classes:      make(map[*ClassDeclaration]*classTypes, 4),
+		methods:      make(map[Node]*classContext, 8),
+		enums:        make(map[*EnumDeclaration]*enumTypes, 2),
+		namespaces:   make(map[*ModuleDeclaration]*namespaceTypes, 2),
+		typeScopes:   make(map[*TypeReference]*Scope, 2),
+		typeParams:   make(map[*TypeParameter]*Type, 2),
+		instances:    make(map[*Type]map[string]*Type, 2),
+		incomplete:   make(map[*Type][]*Type, 2),
+		patternTypes: make(map[Node]*Type, 2),
+		forEachHeads: make(map[*VariableDeclarator]Node, 2),
+		varRepeats:   make(map[*VariableDeclarator]*VariableInfo, 2),
+		loops:        make([]LoopReport, 0, 4),
+		information:  make([]string, 0, 32), // Pre-allocar
+		diagnostics:  make([]Diagnostic, 0, 8),
 	}
 }
 
@@ -51,309 +99,241 @@ func (s *Semantic) addInfo(message string) {
 	s.information = append(s.information, message)
 }
 
+// report registra el diagnóstico y deja también su versión de texto en la
+// información semántica para los clientes que solo leen semanticInfo.
+func (s *Semantic) report(d Diagnostic) {
+	s.diagnostics = append(s.diagnostics, d)
+	s.addInfo(d.String())
+}
+
+func (s *Semantic) addError(code string, node Node, message string) {
+	s.report(newDiagnostic(PhaseSemantic, SeverityError, code, node.Span(), message))
+}
+
+func (s *Semantic) addWarning(code string, node Node, message string) {
+	s.report(newDiagnostic(PhaseSemantic, SeverityWarning, code, node.Span(), message))
+}
+
+// addNote registra una observación informativa ligada al rango del nodo
+func (s *Semantic) addNote(code string, node Node, message string) {
+	s.report(newDiagnostic(PhaseSemantic, SeverityInfo, code, node.Span(), message))
+}
+
+// Diagnostics devuelve los errores y advertencias producidos por Analyze
+func (s *Semantic) Diagnostics() []Diagnostic {
+	return s.diagnostics
+}
+
 func (s *Semantic) Analyze() []string {
+	s.bindScopes()
 	s.analyzeVariableDeclarations()
-	s.analyzeForLoop()
+	s.checkConstAssignments()
+	s.checkTypes()
+	s.checkConstEnumUsage()
+	s.checkControlFlow()
+	s.checkDeclarationOrder()
+	s.analyzeLoops()
 	s.checkVariableUsage()
-	s.analyzeInfiniteLoop()
 	s.detectUndeclaredVariables()
-	s.detectMalformedNumbers()
-	s.detectInvalidExpressions()
-	s.analyzeDoWhileLoop()
 	return s.information
 }
 
-func (s *Semantic) detectMalformedNumbers() {
-	for i := range s.tokens {
-		if s.tokens[i].Type == UNKNOWN && len(s.tokens[i].Value) > 0 && 
-		   unicode.IsDigit(rune(s.tokens[i].Value[0])) {
-			s.addInfo("❌ ERROR LÉXICO: Número mal formado '" + s.tokens[i].Value + 
-				"' en línea " + strconv.Itoa(s.tokens[i].Line) + 
-				", columna " + strconv.Itoa(s.tokens[i].Column))
+// forEachReference llama a fn con cada identificador usado como valor,
+// excluyendo los nombres que se declaran y las propiedades accedidas con punto.
+// No entra en funciones anidadas: cada una se resuelve en su propio ámbito.
+func forEachReference(node Node, fn func(*Identifier)) {
+	Inspect(node, func(n Node) bool {
+		if isFunctionNode(n) {
+			return false
 		}
-	}
+		switch n := n.(type) {
+		case *VariableDeclarator:
+			forEachReference(n.Init, fn)
+			return false
+		case *MemberExpression:
+			forEachReference(n.Object, fn)
+			return false
+		case *Property:
+			// La clave de un objeto literal es un nombre, no una referencia,
+			// salvo que sea calculada: '[clave]: valor'
+			if n.Computed {
+				forEachReference(n.Key, fn)
+			}
+			forEachReference(n.Value, fn)
+			return false
+		case TypeNode:
+			return false
+		case *Identifier:
+			fn(n)
+		}
+		return true
+	})
 }
 
-func (s *Semantic) detectInvalidExpressions() {
-	for i := 0; i < len(s.tokens)-1; i++ {
-		current := &s.tokens[i]
-		next := &s.tokens[i+1]
-		
-		if current.Line != next.Line {
+// detectUndeclaredVariables reporta los identificadores que no se resolvieron
+// en ningún ámbito visible. Si existe una variable con ese nombre en otro
+// bloque, el error indica que se usó fuera del bloque que la declara.
+func (s *Semantic) detectUndeclaredVariables() {
+	reported := make(map[string]bool, 16) // Pre-allocar
+
+	for _, id := range s.unresolved {
+		if builtinGlobals[id.Name] != nil || reported[id.Name] {
 			continue
 		}
-		
-		switch {
-		case current.Type == NUMBER && next.Type == IDENTIFIER:
-			s.addInfo("❌ ERROR SINTÁCTICO: Número '" + current.Value + 
-				"' seguido de identificador '" + next.Value + 
-				"' sin operador en línea " + strconv.Itoa(current.Line))
-		case current.Type == IDENTIFIER && next.Type == NUMBER && !s.isReservedWord(current.Value):
-			s.addInfo("❌ ERROR SINTÁCTICO: Identificador '" + current.Value + 
-				"' seguido de número '" + next.Value + 
-				"' sin operador en línea " + strconv.Itoa(current.Line))
-		case current.Type == NUMBER && next.Type == NUMBER:
-			s.addInfo("❌ ERROR SINTÁCTICO: Dos números consecutivos '" + current.Value + 
-				"' '" + next.Value + "' sin operador en línea " + strconv.Itoa(current.Line))
-		case current.Type == IDENTIFIER && next.Type == IDENTIFIER && 
-			!s.isReservedWord(current.Value) && !s.isReservedWord(next.Value):
-			s.addInfo("❌ ERROR SINTÁCTICO: Dos identificadores consecutivos '" + current.Value + 
-				"' '" + next.Value + "' sin operador en línea " + strconv.Itoa(current.Line))
-		}
-	}
-}
+		reported[id.Name] = true
 
-func (s *Semantic) analyzeDoWhileLoop() {
-	doFound := false
-	whileFound := false
-	var conditionVar string
-	
-	for i := 0; i < len(s.tokens); i++ {
-		token := &s.tokens[i]
-		
-		if token.Type == DO {
-			doFound = true
-			s.addInfo("Bucle 'do-while' detectado - Analizando estructura")
-		}
-		
-		if token.Type == WHILE && doFound {
-			whileFound = true
-			s.addInfo("Cláusula 'while' encontrada en bucle do-while")
-			
-			if i+2 < len(s.tokens) && s.tokens[i+1].Type == LPAREN {
-				condPos := i + 2
-				for condPos < len(s.tokens) && s.tokens[condPos].Type != RPAREN {
-					if s.tokens[condPos].Type == IDENTIFIER {
-						conditionVar = s.tokens[condPos].Value
-						break
-					}
-					condPos++
-				}
-				
-				if conditionVar != "" {
-					s.addInfo("Variable en condición do-while: '" + conditionVar + "'")
-					
-					if _, exists := s.variables[conditionVar]; !exists {
-						s.addInfo("❌ ERROR SEMÁNTICO: Variable '" + conditionVar + 
-							"' en condición do-while no está declarada")
-					} else {
-						s.addInfo("✓ Variable '" + conditionVar + 
-							"' en condición do-while está correctamente declarada")
-					}
-				}
+		if info := s.findSymbol(id.Name); info != nil {
+			// Una 'var' vive en toda su función, no solo en el bloque
+			where := "del bloque"
+			if info.Keyword == "var" {
+				where = "de la función"
 			}
+			s.report(newDiagnostic(PhaseSemantic, SeverityError, codeVariableOutOfScope, id.Loc,
+				"Variable '"+id.Name+"' usada fuera "+where+" donde fue declarada").
+				withRelated(info.Loc, "'"+id.Name+"' se declara aquí con '"+info.Keyword+"'"))
+			continue
 		}
-	}
-	
-	if doFound && whileFound {
-		s.addInfo("✓ Estructura do-while completa detectada")
-	} else if doFound && !whileFound {
-		s.addInfo("❌ ERROR SEMÁNTICO: Bucle 'do' sin cláusula 'while' correspondiente")
+		s.addError(codeUndeclaredVariable, id, "Variable '"+id.Name+"' usada sin declarar")
 	}
 }
 
-func (s *Semantic) detectUndeclaredVariables() {
-	usedVariables := make(map[string]int, 16) // Pre-allocar
-	
-	for i := range s.tokens {
-		if s.tokens[i].Type == IDENTIFIER && !s.isReservedWord(s.tokens[i].Value) {
-			if _, exists := usedVariables[s.tokens[i].Value]; !exists {
-				usedVariables[s.tokens[i].Value] = i
-			}
-		}
-	}
-	
-	for varName, tokenIndex := range usedVariables {
-		if _, declared := s.variables[varName]; !declared {
-			s.addInfo("❌ ERROR SEMÁNTICO: Variable '" + varName + 
-				"' usada sin declarar (línea " + strconv.Itoa(s.tokens[tokenIndex].Line) + ")")
+// findSymbol busca una variable por nombre en cualquier ámbito del programa
+func (s *Semantic) findSymbol(name string) *VariableInfo {
+	for _, info := range s.symbols {
+		if info.Name == name {
+			return info
 		}
 	}
-}
-
-// Función optimizada con lookup directo
-func (s *Semantic) isReservedWord(word string) bool {
-	return reservedWords[word]
+	return nil
 }
 
 func (s *Semantic) analyzeVariableDeclarations() {
-	for i := 0; i < len(s.tokens); i++ {
-		token := &s.tokens[i]
-		
-		if (token.Type == KEYWORD || token.Type == TYPE) && 
-		   i+1 < len(s.tokens) && s.tokens[i+1].Type == IDENTIFIER {
-			
-			varName := s.tokens[i+1].Value
-			varType := s.inferType(token.Value)
-			
-			nextPos := i + 2
-			if nextPos < len(s.tokens) && s.tokens[nextPos].Type == COLON {
-				nextPos++
-				if nextPos < len(s.tokens) && 
-				   (s.tokens[nextPos].Type == TYPE || s.tokens[nextPos].Type == IDENTIFIER) {
-					varType = s.tokens[nextPos].Value
-					nextPos++
-				}
-			}
-			
-			var initialValue string
-			if nextPos < len(s.tokens) && s.tokens[nextPos].Type == ASSIGNMENT {
-				nextPos++
-				if nextPos < len(s.tokens) {
-					initialValue = s.tokens[nextPos].Value
-				}
-			}
-			
-			s.variables[varName] = VariableInfo{
-				Name:         varName,
-				Type:         varType,
-				InitialValue: initialValue,
-				Line:         token.Line,
-				Column:       token.Column,
+	for _, info := range s.symbols {
+		switch info.Keyword {
+		case keywordFunction:
+			_, params, _, _ := functionParts(info.Node)
+			s.addInfo("Función '" + info.Name + typeParametersString(functionTypeParams(info.Node)) +
+				"' declarada con " + strconv.Itoa(len(params)) +
+				" parámetro(s) en línea " + strconv.Itoa(info.Line) + " (ámbito " + string(info.Scope.Kind) + ")")
+			continue
+		case keywordClass:
+			class := info.Node.(*ClassDeclaration)
+			s.addInfo("Clase '" + info.Name + typeParametersString(class.TypeParams) + "' declarada con " +
+				strconv.Itoa(len(class.Members)) + " miembro(s) en línea " + strconv.Itoa(info.Line) +
+				" (ámbito " + string(info.Scope.Kind) + ")")
+			continue
+		case keywordEnum:
+			decl := info.Node.(*EnumDeclaration)
+			noun := "Enum"
+			if decl.Const {
+				noun = "Enum constante"
 			}
-			
-			s.addInfo("Variable '" + varName + "' declarada como tipo '" + varType + 
-				"' con valor inicial '" + initialValue + "' en línea " + strconv.Itoa(token.Line))
-			
-			i = nextPos
+			s.addInfo(noun + " '" + info.Name + "' declarado con " + strconv.Itoa(len(decl.Members)) +
+				" miembro(s) en línea " + strconv.Itoa(info.Line) + " (ámbito " + string(info.Scope.Kind) + ")")
+			continue
+		case keywordEnumMember:
+			s.addInfo("Miembro '" + info.Name + "' del enum '" + info.Type + "' con valor " +
+				s.enumMemberValue(info) + " en línea " + strconv.Itoa(info.Line))
+			continue
+		case keywordNamespace:
+			s.addInfo("Namespace '" + info.Name + "' declarado con " +
+				strconv.Itoa(len(s.namespaceExports(info.Node.(*ModuleDeclaration)))) +
+				" miembro(s) exportado(s) en línea " + strconv.Itoa(info.Line) + " (ámbito " + string(info.Scope.Kind) + ")")
+			continue
+		case keywordParam:
+			s.addInfo("Parámetro '" + info.Name + "' de tipo '" + info.Type + "' en línea " +
+				strconv.Itoa(info.Line))
+			continue
 		}
+		s.addInfo("Variable '" + info.Name + "' declarada como tipo '" + info.Type +
+			"' con valor inicial '" + info.InitialValue + "' en línea " + strconv.Itoa(info.Line) +
+			" (ámbito " + string(info.Scope.Kind) + ")")
 	}
 }
 
-func (s *Semantic) analyzeForLoop() {
-	forFound := false
-	var loopVar, conditionVar, incrementVar string
-	var startValue, endValue int
-	var hasCondition bool
-	
-	for i := 0; i < len(s.tokens); i++ {
-		token := &s.tokens[i]
-		
-		if token.Type == FOR {
-			forFound = true
-			s.addInfo("Bucle 'for' detectado - Analizando estructura")
-		}
-		
-		if forFound && token.Type == IDENTIFIER && i > 0 && 
-		   (s.tokens[i-1].Type == KEYWORD || s.tokens[i-1].Type == TYPE) {
-			loopVar = token.Value
-			
-			if i+2 < len(s.tokens) && s.tokens[i+1].Type == ASSIGNMENT {
-				if val, err := strconv.Atoi(s.tokens[i+2].Value); err == nil {
-					startValue = val
-					s.addInfo("Variable de control '" + loopVar + "' inicializada con valor " + 
-						strconv.Itoa(startValue))
-				}
-			}
-		}
-		
-		if forFound && token.Type == COMPARISON && i > 0 && i+1 < len(s.tokens) {
-			hasCondition = true
-			operator := token.Value
-			
-			if s.tokens[i-1].Type == IDENTIFIER && s.tokens[i+1].Type == NUMBER {
-				conditionVar = s.tokens[i-1].Value
-				if val, err := strconv.Atoi(s.tokens[i+1].Value); err == nil {
-					endValue = val
-					s.addInfo("Condición: '" + conditionVar + " " + operator + " " + 
-						strconv.Itoa(endValue) + "' - Variable de control se compara con " + 
-						strconv.Itoa(endValue))
-					
-					s.checkLoopConditionCoherence(operator, startValue, endValue)
-				}
-			}
-		}
-		
-		if forFound && token.Type == INCREMENT {
-			if i > 0 && s.tokens[i-1].Type == IDENTIFIER {
-				incrementVar = s.tokens[i-1].Value
-			} else if i+1 < len(s.tokens) && s.tokens[i+1].Type == IDENTIFIER {
-				incrementVar = s.tokens[i+1].Value
+// checkConstAssignments reporta cualquier escritura sobre una variable
+// 'const': asignación simple o compuesta e incrementos/decrementos, incluidos
+// los de la cláusula de actualización de un for.
+func (s *Semantic) checkConstAssignments() {
+	Inspect(s.program, func(n Node) bool {
+		var target Expression
+		var action string
+
+		switch write := n.(type) {
+		case *AssignmentExpression:
+			target = write.Target
+			if write.Operator == "=" {
+				action = "No se puede reasignar"
+			} else {
+				action = "No se puede modificar con '" + write.Operator + "'"
 			}
-			
-			s.addInfo("Incremento detectado para variable '" + incrementVar + "' (" + token.Value + ")")
-		}
-	}
-	
-	if forFound && loopVar != "" {
-		s.checkLoopVariableConsistency(loopVar, conditionVar, incrementVar)
-	}
-	
-	if forFound && hasCondition {
-		iterations := s.calculateIterations(startValue, endValue)
-		if iterations > 0 {
-			s.addInfo("El bucle ejecutará aproximadamente " + strconv.Itoa(iterations) + " iteraciones")
+		case *UpdateExpression:
+			target = write.Argument
+			action = "No se puede aplicar '" + write.Operator + "' a"
+		case *ForOfStatement:
+			target, _ = write.Left.(Expression)
+			action = "No se puede reasignar en un 'for...of'"
+		case *ForInStatement:
+			target, _ = write.Left.(Expression)
+			action = "No se puede reasignar en un 'for...in'"
+		default:
+			return true
 		}
-	}
-}
 
-func (s *Semantic) checkLoopConditionCoherence(operator string, start, end int) {
-	switch operator {
-	case "<=", "<":
-		if start > end {
-			s.addInfo("⚠️ ADVERTENCIA: La condición del bucle podría nunca ser verdadera (valor inicial mayor que final)")
+		id, ok := target.(*Identifier)
+		if !ok {
+			return true
 		}
-	case ">=", ">":
-		if start < end {
-			s.addInfo("⚠️ ADVERTENCIA: La condición del bucle podría nunca ser verdadera (valor inicial menor que final)")
+		info := s.references[id]
+		if info == nil || info.Keyword != "const" {
+			return true
 		}
-	}
-}
 
-func (s *Semantic) calculateIterations(start, end int) int {
-	if start <= end {
-		return end - start + 1
-	}
-	return 0
+		s.report(newDiagnostic(PhaseSemantic, SeverityError, codeConstReassignment, n.Span(),
+			action+" la constante '"+id.Name+"'").
+			withRelated(info.Loc, "'"+id.Name+"' se declara como 'const' aquí"))
+		return true
+	})
 }
 
+// checkVariableUsage avisa de las variables y funciones que nunca se usan. Los
+// parámetros sin usar no se reportan: suelen venir impuestos por quien llama.
+// Tampoco los miembros de un enum ni lo exportado, que se usa desde fuera.
 func (s *Semantic) checkVariableUsage() {
-	usedVars := make(map[string]bool, len(s.variables))
-	
-	for i := range s.tokens {
-		if s.tokens[i].Type == IDENTIFIER {
-			if _, exists := s.variables[s.tokens[i].Value]; exists {
-				usedVars[s.tokens[i].Value] = true
-			}
+	for _, info := range s.symbols {
+		if info.Keyword == keywordParam || info.Keyword == keywordEnumMember {
+			continue
 		}
-	}
-	
-	for varName := range s.variables {
-		if !usedVars[varName] {
-			s.addInfo("⚠️ Variable '" + varName + "' declarada pero no utilizada")
-		} else {
-			s.addInfo("✓ Variable '" + varName + "' declarada y utilizada correctamente")
+		noun, ending := "Variable", "a"
+		switch info.Keyword {
+		case keywordFunction:
+			noun = "Función"
+		case keywordClass:
+			noun = "Clase"
+		case keywordEnum:
+			noun, ending = "Enum", "o"
+		case keywordNamespace:
+			noun, ending = "Namespace", "o"
 		}
-	}
-}
-
-func (s *Semantic) analyzeInfiniteLoop() {
-	hasIncrement := false
-	hasValidCondition := false
-	
-	for i := range s.tokens {
-		switch s.tokens[i].Type {
-		case INCREMENT:
-			hasIncrement = true
-		case COMPARISON:
-			hasValidCondition = true
+		switch {
+		case info.References > 0:
+			s.addInfo("✓ " + noun + " '" + info.Name + "' declarad" + ending + " y utilizad" + ending + " correctamente")
+		case !info.Exported:
+			s.report(newDiagnostic(PhaseSemantic, SeverityWarning, codeUnusedVariable, info.Loc,
+				noun+" '"+info.Name+"' declarad"+ending+" pero no utilizad"+ending))
 		}
 	}
-	
-	if !hasIncrement && hasValidCondition {
-		s.addInfo("⚠️ POSIBLE BUCLE INFINITO: No se detectó incremento en la variable de control")
-	} else if hasIncrement && hasValidCondition {
-		s.addInfo("✓ Estructura de bucle válida: tiene condición e incremento")
-	}
 }
 
 // Función optimizada con switch
 func (s *Semantic) inferType(declaration string) string {
 	switch declaration {
-	case "int":
+	case "int", "number":
 		return typeNumber
 	case "string":
 		return typeString
+	case "boolean":
+		return typeBoolean
 	case "let":
 		return typeVariable
 	case "const":
@@ -364,29 +344,3 @@ func (s *Semantic) inferType(declaration string) string {
 		return typeUnknown
 	}
 }
-
-func (s *Semantic) checkLoopVariableConsistency(loopVar, conditionVar, incrementVar string) {
-	if conditionVar != "" && conditionVar != loopVar {
-		s.addInfo("❌ ERROR SEMÁNTICO: Variable en condición '" + conditionVar + 
-			"' no coincide con variable de control '" + loopVar + "'")
-	} else if conditionVar == loopVar {
-		s.addInfo("✓ Variable de condición '" + conditionVar + 
-			"' coincide correctamente con variable de control")
-	}
-	
-	if incrementVar != "" && incrementVar != loopVar {
-		s.addInfo("❌ ERROR SEMÁNTICO: Variable en incremento '" + incrementVar + 
-			"' no coincide con variable de control '" + loopVar + "'")
-	} else if incrementVar == loopVar {
-		s.addInfo("✓ Variable de incremento '" + incrementVar + 
-			"' coincide correctamente con variable de control")
-	}
-	
-	if conditionVar == "" {
-		s.addInfo("⚠️ ADVERTENCIA: No se detectó variable en la condición del bucle")
-	}
-	
-	if incrementVar == "" {
-		s.addInfo("⚠️ ADVERTENCIA: No se detectó variable en el incremento del bucle")
-	}
-}
\ No newline at end of file
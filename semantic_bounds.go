@@ -0,0 +1,291 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// loopBound es el número de vueltas de un bucle con variable de control que
+// empieza en un valor, avanza un paso constante y se compara con un límite.
+type loopBound struct {
+	count   int    // vueltas cuando inicio y límite son constantes; -1 si no
+	formula string // vueltas en función de los valores simbólicos
+	never   bool   // la condición se cumple siempre una vez que se entra
+	certain bool   // con never, la condición se cumple ya al empezar
+	reason  string // por qué no termina
+}
+
+// boundIterations calcula las vueltas de 'for (v = start; v operator bound;
+// v += step)'. Con inicio y límite constantes el resultado es exacto; si
+// alguno es simbólico ('v < n') se da una fórmula. Si el paso aleja la
+// variable del límite, o con '!=' salta por encima de él, el bucle no
+// termina.
+func boundIterations(start, bound Expression, operator string, step int) loopBound {
+	first, constStart := numericConstant(start)
+	limit, constLimit := numericConstant(bound)
+	constant := constStart && constLimit
+	result := loopBound{count: -1}
+
+	if operator == "!=" || operator == "!==" {
+		return notEqualIterations(start, bound, step)
+	}
+
+	// Sentido en el que debe avanzar la variable para llegar al límite
+	var toward int
+	switch operator {
+	case "<", "<=":
+		toward = 1
+	case ">", ">=":
+		toward = -1
+	default:
+		return result
+	}
+	strict := operator == "<" || operator == ">"
+
+	if step == 0 || (step > 0) != (toward > 0) {
+		if constant && !compareNumbers(first, operator, limit) {
+			result.count = 0
+			return result
+		}
+		result.never, result.certain = true, constant
+		if step == 0 {
+			result.reason = "no cambia en cada vuelta"
+		} else if step > 0 {
+			result.reason = "crece en cada vuelta"
+		} else {
+			result.reason = "decrece en cada vuelta"
+		}
+		return result
+	}
+
+	size := step
+	if size < 0 {
+		size = -size
+	}
+	if constant {
+		distance := (limit - first) * float64(toward)
+		switch {
+		case strict && distance > 0:
+			result.count = int(math.Ceil(distance / float64(size)))
+		case !strict && distance >= 0:
+			result.count = int(math.Floor(distance/float64(size))) + 1
+		default:
+			result.count = 0
+		}
+		return result
+	}
+
+	// Con '<=' o '>=' el propio límite cuenta como una vuelta más
+	plus := 0
+	if !strict && size == 1 {
+		plus = 1
+	}
+	distance := differenceString(bound, start, plus)
+	if toward < 0 {
+		distance = differenceString(start, bound, plus)
+	}
+	if size > 1 && strings.Contains(distance, " ") {
+		distance = "(" + distance + ")"
+	}
+	switch {
+	case size == 1:
+		result.formula = "max(0, " + distance + ")"
+	case strict:
+		result.formula = "max(0, ⌈" + distance + " / " + strconv.Itoa(size) + "⌉)"
+	default:
+		result.formula = "max(0, ⌊" + distance + " / " + strconv.Itoa(size) + "⌋ + 1)"
+	}
+	return result
+}
+
+// notEqualIterations calcula las vueltas con la condición 'v != límite': el
+// bucle termina solo si la variable llega a valer exactamente el límite.
+func notEqualIterations(start, bound Expression, step int) loopBound {
+	first, constStart := numericConstant(start)
+	limit, constLimit := numericConstant(bound)
+	result := loopBound{count: -1}
+
+	if constStart && constLimit {
+		distance := limit - first
+		switch {
+		case distance == 0:
+			result.count = 0
+		case step != 0 && (distance > 0) == (step > 0) && math.Mod(distance, float64(step)) == 0:
+			result.count = int(distance / float64(step))
+		case step == 0:
+			result.never, result.reason = true, "no cambia en cada vuelta"
+		case (distance > 0) != (step > 0):
+			result.never, result.reason = true, "se aleja del límite en cada vuelta"
+		default:
+			result.never, result.reason = true, "avanza de "+strconv.Itoa(step)+" en "+strconv.Itoa(step)+
+				" desde "+formatNumber(first)+" sin llegar a valer "+formatNumber(limit)
+		}
+		result.certain = result.never
+		return result
+	}
+
+	switch step {
+	case 0:
+		result.never, result.reason = true, "no cambia en cada vuelta"
+	case 1:
+		result.formula = differenceString(bound, start, 0)
+	case -1:
+		result.formula = differenceString(start, bound, 0)
+	default:
+		distance := differenceString(bound, start, 0)
+		if strings.Contains(distance, " ") {
+			distance = "(" + distance + ")"
+		}
+		result.formula = distance + " / " + strconv.Itoa(step)
+	}
+	return result
+}
+
+// compareNumbers evalúa 'a operator b' con un operador relacional
+func compareNumbers(a float64, operator string, b float64) bool {
+	switch operator {
+	case "<":
+		return a < b
+	case "<=":
+		return a <= b
+	case ">":
+		return a > b
+	case ">=":
+		return a >= b
+	case "!=", "!==":
+		return a != b
+	default:
+		return false
+	}
+}
+
+// differenceString escribe 'a - b + plus' sumando las constantes cuando b es
+// un literal: 'n' para 'n - 1 + 1' y 'n + 1' para 'n - 0 + 1'.
+func differenceString(a, b Expression, plus int) string {
+	if value, ok := integerLiteral(b); ok {
+		switch value -= plus; {
+		case value == 0:
+			return exprString(a)
+		case value < 0:
+			return exprString(a) + " + " + strconv.Itoa(-value)
+		default:
+			return exprString(a) + " - " + strconv.Itoa(value)
+		}
+	}
+	right := exprString(b)
+	switch b.(type) {
+	case *Identifier, *MemberExpression, *CallExpression, *IndexExpression:
+	default:
+		right = "(" + right + ")"
+	}
+	text := exprString(a) + " - " + right
+	if plus != 0 {
+		text += " + " + strconv.Itoa(plus)
+	}
+	return text
+}
+
+// reportLoopBound informa de las vueltas de un 'for' o avisa de que no
+// termina. Con un 'break' o 'return' en el cuerpo, o con la comparación
+// unida a otras con '&&', el número calculado es solo un máximo y un paso
+// que se aleja del límite no basta para asegurar que no termina.
+func (s *Semantic) reportLoopBound(loop *ForStatement, cond *BinaryExpression, bound loopBound, atMost bool,
+	report *LoopReport) {
+	if bound.never {
+		if atMost {
+			return
+		}
+		report.NeverTerminates = true
+		report.PossiblyInfinite = true
+		if bound.certain {
+			s.addWarning(codeLoopNeverTerminates, loop, "El bucle nunca termina: '"+report.Variable+"' "+
+				bound.reason+" y la condición '"+exprString(cond)+"' se cumple siempre")
+		} else {
+			s.addWarning(codeLoopNeverTerminates, loop, "El bucle nunca termina si llega a entrar: '"+
+				report.Variable+"' "+bound.reason+" y la condición '"+exprString(cond)+"' seguirá cumpliéndose")
+		}
+		return
+	}
+
+	report.AtMost = atMost
+	switch {
+	case bound.count >= 0 && atMost:
+		report.Iterations = bound.count
+		s.addNote(codeLoopAnalysis, loop, "El bucle ejecutará como máximo "+strconv.Itoa(bound.count)+" iteraciones")
+	case bound.count >= 0:
+		report.Iterations = bound.count
+		s.addNote(codeLoopAnalysis, loop, "El bucle ejecutará exactamente "+strconv.Itoa(bound.count)+" iteraciones")
+	case bound.formula != "" && atMost:
+		report.Formula = bound.formula
+		s.addNote(codeLoopAnalysis, loop, "Número de iteraciones como máximo: "+bound.formula)
+	case bound.formula != "":
+		report.Formula = bound.formula
+		s.addNote(codeLoopAnalysis, loop, "Número de iteraciones: "+bound.formula)
+	}
+}
+
+// boundedByComparison indica cómo limita cond las vueltas del bucle: exact si
+// es toda la condición, atMost si va unida a otras con '&&' (el bucle puede
+// acabar antes). Unida con '||' u otro operador no limita nada.
+func boundedByComparison(test Expression, cond *BinaryExpression) (bounded, atMost bool) {
+	for test != Expression(cond) {
+		logical, ok := test.(*LogicalExpression)
+		if !ok || logical.Operator != "&&" {
+			return false, false
+		}
+		atMost = true
+		if containsNode(logical.Left, cond) {
+			test = logical.Left
+		} else {
+			test = logical.Right
+		}
+	}
+	return true, atMost
+}
+
+// containsNode indica si target está dentro de root
+func containsNode(root Node, target Node) bool {
+	found := false
+	Inspect(root, func(n Node) bool {
+		if n == target {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+// boundChanges indica si el cuerpo o la actualización del bucle escriben en
+// alguna variable del límite: con 'i < n' y 'n++' en el cuerpo, el límite se
+// aleja en cada vuelta y el número de iteraciones no se conoce.
+func boundChanges(loop *ForStatement, bound Expression) bool {
+	changed := false
+	Inspect(bound, func(n Node) bool {
+		if id, ok := n.(*Identifier); ok {
+			changed = writesVariable(loop.Body, id.Name) || writesVariable(loop.Update, id.Name)
+		}
+		return !changed
+	})
+	return changed
+}
+
+// writesVariable indica si el nodo asigna o incrementa la variable name, con
+// lo que el paso de la cláusula de actualización no es el único cambio.
+func writesVariable(node Node, name string) bool {
+	found := false
+	Inspect(node, func(n Node) bool {
+		var target Expression
+		switch n := n.(type) {
+		case *UpdateExpression:
+			target = n.Argument
+		case *AssignmentExpression:
+			target = n.Target
+		}
+		if id, ok := target.(*Identifier); ok && id.Name == name {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
@@ -0,0 +1,423 @@
+package main
+
+// classTypes son los dos tipos que declara una clase: el de sus instancias y
+// el de la propia clase como valor, con la firma de 'new' y los miembros
+// estáticos.
+type classTypes struct {
+	instance      *Type
+	constructor   *Type
+	base          *ClassDeclaration // clase de la que hereda, si se pudo resolver
+	baseInstance  *Type             // instancia de la base con los argumentos de tipo de 'extends'
+	baseConstruct *Signature        // constructor de la base, al que llama 'super(...)'
+}
+
+// classContext describe el código que se está comprobando: la clase en cuyo
+// cuerpo está, que decide el acceso a los miembros privados y protegidos, el
+// tipo de 'this' y si es el constructor, único lugar donde se pueden asignar
+// los campos readonly.
+type classContext struct {
+	class       *ClassDeclaration
+	this        *Type
+	constructor bool
+}
+
+func (s *Semantic) currentContext() *classContext {
+	if len(s.contexts) == 0 {
+		return nil
+	}
+	return s.contexts[len(s.contexts)-1]
+}
+
+// enterContext activa el contexto y devuelve la función que lo desactiva
+func (s *Semantic) enterContext(ctx *classContext) func() {
+	s.contexts = append(s.contexts, ctx)
+	return func() { s.contexts = s.contexts[:len(s.contexts)-1] }
+}
+
+// enterFunction activa el contexto de una función: el de su clase si es un
+// método. Una arrow function conserva el 'this' de fuera; cualquier otra
+// función lo pierde, aunque sigue dentro del cuerpo de la clase.
+func (s *Semantic) enterFunction(fn Node) func() {
+	ctx, ok := s.methods[fn]
+	if !ok {
+		if _, isArrow := fn.(*ArrowFunction); isArrow {
+			return func() {}
+		}
+		ctx = &classContext{this: anyType}
+		if outer := s.currentContext(); outer != nil {
+			ctx.class = outer.class
+		}
+	}
+	return s.enterContext(ctx)
+}
+
+// classTypesOf construye los tipos de la clase. Todos los miembros se
+// registran antes de calcular su tipo, así un método puede usar otro declarado
+// más abajo; mientras tanto valen 'any'.
+func (s *Semantic) classTypesOf(class *ClassDeclaration) *classTypes {
+	if ct, ok := s.classes[class]; ok {
+		return ct
+	}
+	name := ""
+	if class.Name != nil {
+		name = class.Name.Name
+	}
+	ct := &classTypes{
+		instance:    &Type{Kind: KindObject, Name: name},
+		constructor: &Type{Kind: KindObject, Name: "typeof " + name},
+	}
+	s.classes[class] = ct
+	typeParams := s.typeParametersOf(class.TypeParams)
+	ct.instance.TypeParams = typeParams
+	ct.constructor.Construct = &Signature{TypeParams: typeParams, Return: ct.instance}
+	s.startGeneric(ct.instance)
+	defer s.completeGeneric(ct.instance)
+
+	// Primero lo heredado, para que los miembros propios lo sustituyan
+	if ct.base = s.baseClass(class); ct.base != nil {
+		base := s.classTypesOf(ct.base)
+		ct.baseInstance, ct.baseConstruct = base.instance, base.constructor.Construct
+		if params := base.instance.TypeParams; params != nil {
+			args := s.checkTypeArguments(class.SuperClass, "La clase genérica '"+ct.base.Name.Name+
+				typeParamsString(params)+"'", params, class.SuperTypeArguments)
+			ct.baseInstance = s.instantiate(base.instance, args)
+			ct.baseConstruct = s.substituteSignature(ct.baseConstruct, typeMapping(params, args))
+		} else if len(class.SuperTypeArguments) > 0 {
+			s.addError(codeTypeArgumentCount, class.SuperTypeArguments[0], "La clase '"+ct.base.Name.Name+
+				"' no es genérica y no admite argumentos de tipo")
+		}
+		for _, prop := range ct.baseInstance.Properties {
+			ct.instance.setProperty(prop)
+		}
+		for _, prop := range base.constructor.Properties {
+			ct.constructor.setProperty(prop)
+		}
+		inherited := *ct.baseConstruct
+		inherited.TypeParams = typeParams
+		inherited.Return = ct.instance
+		ct.constructor.Construct = &inherited
+	}
+
+	instanceContext := &classContext{class: class, this: ct.instance}
+	staticContext := &classContext{class: class, this: ct.constructor}
+	declare := func(owner *Type, name, access string, optional, readonly bool) *PropertyType {
+		if access == "public" {
+			access = ""
+		}
+		prop := &PropertyType{Name: name, Type: anyType, Optional: optional, Readonly: readonly, Access: access,
+			Class: ct.instance}
+		owner.setProperty(prop)
+		return prop
+	}
+
+	var pending []func()
+	for _, member := range class.Members {
+		switch m := member.(type) {
+		case *PropertyDefinition:
+			owner, ctx := ct.instance, instanceContext
+			if m.Static {
+				owner, ctx = ct.constructor, staticContext
+			}
+			prop, field := declare(owner, m.Name.Name, m.Access, m.Optional, m.Readonly), m
+			pending = append(pending, func() {
+				defer s.enterContext(ctx)()
+				prop.Type = s.fieldType(field)
+			})
+		case *MethodDefinition:
+			fn := m.Function
+			if m.isConstructor() {
+				s.methods[fn] = &classContext{class: class, this: ct.instance, constructor: true}
+				for _, p := range fn.Params {
+					if !p.isProperty() {
+						continue
+					}
+					prop, param := declare(ct.instance, p.Name.Name, p.Access, p.Optional, p.Readonly), p
+					pending = append(pending, func() { prop.Type = s.inferredParameterType(param) })
+				}
+				pending = append(pending, func() {
+					sig := s.parameterSignature(fn.Params)
+					sig.TypeParams = typeParams
+					sig.Return = ct.instance
+					ct.constructor.Construct = sig
+				})
+				continue
+			}
+
+			owner, ctx := ct.instance, instanceContext
+			if m.Static {
+				owner, ctx = ct.constructor, staticContext
+			}
+			s.methods[fn] = ctx
+			prop := declare(owner, m.Name.Name, m.Access, false, false)
+			pending = append(pending, func() { prop.Type = s.functionTypeOf(fn) })
+		}
+	}
+	for _, resolve := range pending {
+		resolve()
+	}
+	return ct
+}
+
+// fieldType es el tipo anotado del campo o, si no tiene, el de su valor inicial
+func (s *Semantic) fieldType(field *PropertyDefinition) *Type {
+	if field.TypeAnnotation != nil {
+		return s.resolveTypeNode(field.TypeAnnotation)
+	}
+	if field.Value != nil {
+		return s.typeOf(field.Value)
+	}
+	return anyType
+}
+
+// baseClass resuelve la clase que aparece tras 'extends'
+func (s *Semantic) baseClass(class *ClassDeclaration) *ClassDeclaration {
+	if class.SuperClass == nil {
+		return nil
+	}
+	info := s.references[class.SuperClass]
+	if info == nil {
+		// Sin declarar: ya lo reporta detectUndeclaredVariables
+		return nil
+	}
+	base, ok := info.Node.(*ClassDeclaration)
+	switch {
+	case !ok:
+		s.addError(codeTypeMismatch, class.SuperClass, "'"+info.Name+"' no es una clase: solo se puede extender una clase")
+		return nil
+	case base == class:
+		s.addError(codeTypeMismatch, class.SuperClass, "La clase '"+info.Name+"' no puede extenderse a sí misma")
+		return nil
+	}
+	return base
+}
+
+// isSubclass indica si la clase es la que declara el tipo de instancia dado o
+// hereda de ella.
+func (s *Semantic) isSubclass(class *ClassDeclaration, instance *Type) bool {
+	visited := make(map[*ClassDeclaration]bool, 2)
+	for class != nil && !visited[class] {
+		visited[class] = true
+		ct := s.classTypesOf(class)
+		if ct.instance == instance {
+			return true
+		}
+		class = ct.base
+	}
+	return false
+}
+
+// checkClass comprueba los valores iniciales de los campos y el cuerpo de cada
+// método, que el constructor de una clase derivada llame a 'super()' y que la
+// clase tenga todo lo que exigen las interfaces que implementa.
+func (s *Semantic) checkClass(class *ClassDeclaration) {
+	ct := s.classTypesOf(class)
+	name := ct.instance.Name
+	if ct.base != nil {
+		s.addInfo("Clase '" + name + "' hereda de '" + ct.base.Name.Name + "'")
+	}
+
+	var constructor *MethodDefinition
+	for _, member := range class.Members {
+		switch m := member.(type) {
+		case *PropertyDefinition:
+			s.checkField(class, ct, m)
+		case *MethodDefinition:
+			if m.isConstructor() {
+				constructor = m
+			}
+			s.checkFunction(m.Function)
+		}
+	}
+
+	if ct.base != nil && constructor != nil && !callsSuper(constructor.Function.Body) {
+		s.addError(codeMissingSuperCall, constructor.Name, "El constructor de '"+name+
+			"' debe llamar a 'super()' porque la clase extiende '"+ct.base.Name.Name+"'")
+	}
+	for _, ref := range class.Implements {
+		s.checkImplements(ct.instance, ref)
+	}
+}
+
+func (s *Semantic) checkField(class *ClassDeclaration, ct *classTypes, field *PropertyDefinition) {
+	ctx := &classContext{class: class, this: ct.instance}
+	if field.Static {
+		ctx.this = ct.constructor
+	}
+	defer s.enterContext(ctx)()
+
+	s.typeOf(field.Value)
+	if field.TypeAnnotation == nil || field.Value == nil {
+		return
+	}
+	declared := s.resolveTypeNode(field.TypeAnnotation)
+	if ok, reason := s.valueAssignable(field.Value, declared); !ok {
+		s.addError(codeTypeMismatch, field.Value, mismatchMessage("No se puede asignar un valor de tipo '"+
+			s.displayType(field.Value).String()+"' a la propiedad '"+field.Name.Name+"' de tipo '"+
+			declared.String()+"'", reason))
+	}
+}
+
+// callsSuper indica si el cuerpo del constructor llama a 'super(...)'
+func callsSuper(body *BlockStatement) bool {
+	found := false
+	Inspect(blockNode(body), func(n Node) bool {
+		if found || isFunctionNode(n) {
+			return false
+		}
+		if call, ok := n.(*CallExpression); ok {
+			_, found = call.Callee.(*SuperExpression)
+		}
+		return !found
+	})
+	return found
+}
+
+// checkImplements reporta los miembros que la interfaz exige y la clase no
+// tiene, o tiene con otro tipo o sin ser públicos.
+func (s *Semantic) checkImplements(instance *Type, ref *TypeReference) {
+	iface := s.resolveTypeNode(ref)
+	if iface.isAny() {
+		return
+	}
+	if !iface.hasProperties() {
+		s.addError(codeTypeMismatch, ref, "Una clase solo puede implementar tipos objeto, no '"+iface.String()+"'")
+		return
+	}
+
+	complete := true
+	for _, expected := range propertiesOf(iface) {
+		actual := instance.property(expected.Name)
+		switch {
+		case actual == nil && expected.Optional:
+			continue
+		case actual == nil:
+			s.addError(codeMissingMember, ref, "La clase '"+instance.Name+"' no implementa '"+expected.Name+
+				"' de la interfaz '"+iface.String()+"'")
+		case actual.Access != "":
+			s.addError(codeInaccessibleMember, ref, "'"+expected.Name+"' es "+accessName(actual.Access)+
+				" en la clase '"+instance.Name+"' pero la interfaz '"+iface.String()+"' lo exige público")
+		case !isAssignable(actual.Type, expected.Type):
+			s.addError(codeTypeMismatch, ref, "'"+expected.Name+"' es de tipo '"+actual.Type.String()+
+				"' en la clase '"+instance.Name+"' pero la interfaz '"+iface.String()+"' espera '"+
+				expected.Type.String()+"'")
+		default:
+			continue
+		}
+		complete = false
+	}
+	if complete {
+		s.addInfo("✓ Clase '" + instance.Name + "' implementa correctamente la interfaz '" + iface.String() + "'")
+	}
+}
+
+// checkAccess reporta el uso de un miembro privado fuera de su clase, o de uno
+// protegido fuera de su clase y sus subclases.
+func (s *Semantic) checkAccess(prop *PropertyType, member *MemberExpression) {
+	if prop.Access == "" || prop.Class == nil {
+		return
+	}
+	var inside *ClassDeclaration
+	if ctx := s.currentContext(); ctx != nil {
+		inside = ctx.class
+	}
+
+	switch prop.Access {
+	case "private":
+		if inside != nil && s.classTypesOf(inside).instance == prop.Class {
+			return
+		}
+		s.addError(codeInaccessibleMember, member.Property, "El miembro '"+prop.Name+
+			"' es privado y solo se puede usar dentro de la clase '"+prop.Class.Name+"'")
+	case "protected":
+		if s.isSubclass(inside, prop.Class) {
+			return
+		}
+		s.addError(codeInaccessibleMember, member.Property, "El miembro '"+prop.Name+
+			"' es protegido y solo se puede usar dentro de la clase '"+prop.Class.Name+"' y sus subclases")
+	}
+}
+
+func accessName(access string) string {
+	if access == "private" {
+		return "privado"
+	}
+	return "protegido"
+}
+
+// checkWritable reporta la escritura sobre una propiedad de solo lectura. El
+// constructor de la clase que la declara sí puede inicializarla con 'this'.
+func (s *Semantic) checkWritable(target Expression, write Node) {
+	member, ok := target.(*MemberExpression)
+	if !ok || member.Property == nil {
+		return
+	}
+	object := s.typeOf(member.Object)
+	if !object.hasProperties() {
+		return
+	}
+	prop := object.property(member.Property.Name)
+	if prop == nil || !prop.Readonly {
+		return
+	}
+	if ctx := s.currentContext(); ctx != nil && ctx.constructor && s.classTypesOf(ctx.class).instance == prop.Class {
+		if _, isThis := member.Object.(*ThisExpression); isThis {
+			return
+		}
+	}
+	s.addError(codeReadonlyAssignment, write, "No se puede asignar a '"+exprString(target)+
+		"' porque es una propiedad de solo lectura")
+}
+
+// thisType es el tipo de 'this' en el punto actual: la instancia en un método,
+// la clase en un método estático y 'any' fuera de una clase.
+func (s *Semantic) thisType() *Type {
+	if ctx := s.currentContext(); ctx != nil && ctx.this != nil {
+		return ctx.this
+	}
+	return anyType
+}
+
+// superType es el tipo de 'super': la instancia de la clase base
+func (s *Semantic) superType(expr *SuperExpression) *Type {
+	if ct := s.currentDerived(); ct != nil {
+		return ct.baseInstance
+	}
+	s.addError(codeInvalidSuper, expr, "'super' solo se puede usar dentro de una clase que extiende otra")
+	return anyType
+}
+
+// currentDerived devuelve los tipos de la clase cuyo cuerpo se comprueba si
+// extiende otra; nil si no.
+func (s *Semantic) currentDerived() *classTypes {
+	ctx := s.currentContext()
+	if ctx == nil || ctx.class == nil {
+		return nil
+	}
+	if ct := s.classTypesOf(ctx.class); ct.base != nil {
+		return ct
+	}
+	return nil
+}
+
+// newType comprueba 'new Clase(args)' contra el constructor de la clase y
+// devuelve el tipo de la instancia. En una clase genérica los argumentos de
+// tipo se dan explícitamente, 'new Caja<number>(1)', o se deducen.
+func (s *Semantic) newType(expr *NewExpression) *Type {
+	callee := apparentType(s.typeOf(expr.Callee))
+	for _, arg := range expr.Arguments {
+		s.typeOf(arg)
+	}
+	if callee.isAny() {
+		return anyType
+	}
+
+	name := exprString(expr.Callee)
+	if callee.Construct == nil {
+		s.addError(codeNotCallable, expr.Callee, "'"+name+"' es de tipo '"+callee.String()+
+			"' y no se puede usar con 'new'")
+		return anyType
+	}
+	sig := s.instantiateSignature(expr, name, callee.Construct, expr.TypeArguments, expr.Arguments)
+	s.checkArguments(expr, name, sig, expr.Arguments)
+	return sig.Return
+}
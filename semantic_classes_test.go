@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestClasses(t *testing.T) {
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name: "miembro privado desde fuera",
+			code: "class A { private s = 1; } let a = new A(); a.s;",
+			want: []string{"inaccessible-member@1:47"},
+		},
+		{
+			name:    "miembro protegido desde una subclase",
+			code:    "class A { protected s = 1; } class B extends A { m() { return this.s; } }",
+			notWant: []string{"inaccessible-member"},
+		},
+		{
+			name: "asignar un campo readonly en un método",
+			code: "class A { readonly r = 1; m() { this.r = 2; } }",
+			want: []string{"readonly-assignment"},
+		},
+		{
+			name:    "asignar un campo readonly en el constructor",
+			code:    "class A { readonly r: number; constructor() { this.r = 2; } }",
+			notWant: []string{"readonly-assignment"},
+		},
+		{
+			name: "miembro de la interfaz sin implementar",
+			code: "interface I { m(): void; n: number } class C implements I { m() {} }",
+			want: []string{"missing-member"},
+		},
+		{
+			name: "constructor sin super()",
+			code: "class A { constructor(x: number) {} } class B extends A { constructor() { } }",
+			want: []string{"missing-super-call"},
+		},
+		{
+			name:    "constructor con super()",
+			code:    "class A { constructor(x: number) {} } class B extends A { constructor() { super(1); } }",
+			notWant: []string{"missing-super-call"},
+		},
+		{
+			name: "super fuera de una subclase",
+			code: "class A {} function f() { super.x; }",
+			want: []string{"invalid-super"},
+		},
+		{
+			name:    "método de la clase base con super",
+			code:    "class A { m(): number { return 1; } } class B extends A { n() { return super.m(); } }",
+			notWant: []string{"invalid-super", "unknown-property"},
+		},
+		{
+			name:    "miembro estático",
+			code:    "class A { static count = 0; } A.count = 1;",
+			notWant: []string{"unknown-property"},
+		},
+		{
+			name: "propiedad de this que no existe",
+			code: "class A { x = 1; m() { return this.y; } }",
+			want: []string{"unknown-property"},
+		},
+	})
+}
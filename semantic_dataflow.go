@@ -0,0 +1,255 @@
+package main
+
+import "strconv"
+
+// checkDeclarationOrder reporta los usos de un 'let', un 'const', una clase o
+// un enum antes de su declaración: hasta entonces están en la zona muerta
+// temporal y acceder a ellos lanza un error. Un uso dentro de una función
+// anidada no cuenta, porque se ejecuta cuando se la llama.
+func (s *Semantic) checkDeclarationOrder() {
+	var walk func(n Node, fn Node)
+	walk = func(n Node, fn Node) {
+		if isFunctionNode(n) {
+			fn = n
+		}
+		if id, ok := n.(*Identifier); ok {
+			if info := s.references[id]; info != nil && scopeOwner(info.Scope) == fn {
+				s.checkUseBeforeDeclaration(id, info)
+			}
+		}
+		for _, child := range n.Children() {
+			walk(child, fn)
+		}
+	}
+	walk(s.program, s.program)
+}
+
+func (s *Semantic) checkUseBeforeDeclaration(id *Identifier, info *VariableInfo) {
+	// Una variable está en la zona muerta hasta el final de su declarador, así
+	// que 'let x = x + 1' también la usa antes de tiempo. Una clase o un enum
+	// ya existen dentro de su propio cuerpo.
+	end := info.Node.Span().End.Offset
+	var message string
+	switch info.Keyword {
+	case "var", keywordFunction, keywordParam, keywordNamespace, keywordEnumMember:
+		// Se elevan: existen desde el principio de su ámbito
+		return
+	case keywordClass, keywordEnum:
+		end = info.Node.Span().Start.Offset
+		noun := "la clase"
+		if info.Keyword == keywordEnum {
+			noun = "el enum"
+		}
+		message = "No se puede usar " + noun + " '" + info.Name + "' antes de su declaración en la línea " +
+			strconv.Itoa(info.Line)
+	default:
+		message = "No se puede usar '" + info.Name + "' antes de su declaración con '" + info.Keyword +
+			"' en la línea " + strconv.Itoa(info.Line) + ": hasta ahí está en la zona muerta temporal"
+	}
+	if id.Loc.Start.Offset >= end {
+		return
+	}
+	s.report(newDiagnostic(PhaseSemantic, SeverityError, codeUsedBeforeDeclaration, id.Loc, message).
+		withRelated(info.Loc, "'"+info.Name+"' se declara aquí"))
+}
+
+// scopeOwner devuelve la función o el programa en cuyo cuerpo se ejecuta el
+// código del ámbito.
+func scopeOwner(scope *Scope) Node {
+	for ; scope != nil; scope = scope.Parent {
+		if scope.Kind == ScopeFunction || scope.Kind == ScopeGlobal {
+			return scope.Node
+		}
+	}
+	return nil
+}
+
+// checkDefiniteAssignment recorre el grafo de flujo de una función buscando
+// lecturas de variables que pueden no tener valor todavía: un 'let x: number'
+// sin valor inicial, o un 'var' que se usa antes de la línea que le da valor.
+// Una variable está asignada al entrar en un bloque solo si lo está al salir
+// de todos sus predecesores alcanzables.
+func (s *Semantic) checkDefiniteAssignment(graph *CFG) {
+	tracked := s.unassignedVariables(graph)
+	if len(tracked) == 0 {
+		return
+	}
+
+	predecessors := make([][]int, len(graph.Blocks))
+	for _, edge := range graph.Edges {
+		predecessors[edge.To] = append(predecessors[edge.To], edge.From)
+	}
+	// Se parte de que todo está asignado salvo a la entrada y se va quitando
+	// hasta que nada cambia
+	assigned := make([][]bool, len(graph.Blocks))
+	for i := range assigned {
+		assigned[i] = make([]bool, len(tracked))
+		for j := range assigned[i] {
+			assigned[i][j] = i != graph.Entry
+		}
+	}
+	blockInput := func(i int) []bool {
+		state := make([]bool, len(tracked))
+		for j := range state {
+			state[j] = true
+		}
+		for _, p := range predecessors[i] {
+			if graph.Blocks[p].Reachable {
+				for j := range state {
+					state[j] = state[j] && assigned[p][j]
+				}
+			}
+		}
+		return state
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for i, block := range graph.Blocks {
+			if i == graph.Entry || !block.Reachable {
+				continue
+			}
+			state := blockInput(i)
+			for _, node := range block.Nodes {
+				s.assignmentEvents(node, tracked, func(index int, _ *Identifier, write bool) {
+					state[index] = state[index] || write
+				})
+			}
+			for j := range state {
+				if state[j] != assigned[i][j] {
+					assigned[i], changed = state, true
+					break
+				}
+			}
+		}
+	}
+
+	reported := make([]bool, len(tracked))
+	for i, block := range graph.Blocks {
+		if i == graph.Entry || !block.Reachable {
+			continue
+		}
+		state := blockInput(i)
+		for _, node := range block.Nodes {
+			s.assignmentEvents(node, tracked, func(index int, id *Identifier, write bool) {
+				if write {
+					state[index] = true
+				} else if !state[index] && !reported[index] {
+					reported[index] = true
+					s.reportUnassigned(id, tracked[index])
+				}
+			})
+		}
+	}
+}
+
+// unassignedVariables devuelve las variables del grafo que pueden leerse sin
+// valor: las que no son 'const', se declaran sin valor inicial (o con 'var',
+// que se eleva sin él) y cuyo tipo no admite undefined. Un 'let x;' sin tipo
+// es 'any' y no se comprueba.
+func (s *Semantic) unassignedVariables(graph *CFG) []*VariableInfo {
+	var tracked []*VariableInfo
+	for _, info := range s.symbols {
+		declarator := info.Declarator
+		if declarator == nil || declarator.Name == nil || info.Keyword == "const" ||
+			s.forEachHeads[declarator] != nil || scopeOwner(info.Scope) != graph.Node {
+			continue
+		}
+		if declarator.Init != nil && info.Keyword != "var" {
+			continue
+		}
+		if !acceptsUndefined(s.symbolType(info)) {
+			tracked = append(tracked, info)
+		}
+	}
+	return tracked
+}
+
+// assignmentEvents llama a visit con cada lectura y escritura de las
+// variables vigiladas, en el orden en que se ejecutan: en 'x = x + 1' la
+// lectura va antes que la escritura. No entra en funciones ni clases, cuyo
+// código se ejecuta más tarde.
+func (s *Semantic) assignmentEvents(node Node, tracked []*VariableInfo, visit func(int, *Identifier, bool)) {
+	event := func(id *Identifier, info *VariableInfo, write bool) {
+		for i, candidate := range tracked {
+			if candidate == info {
+				visit(i, id, write)
+				return
+			}
+		}
+	}
+	var walk func(n Node)
+	write := func(target Expression) {
+		if id, ok := target.(*Identifier); ok {
+			event(id, s.references[id], true)
+			return
+		}
+		if _, ok := target.(*MemberExpression); ok {
+			walk(target)
+			return
+		}
+		// Desestructuración: '[a, b] = valores'
+		forEachReference(target, func(id *Identifier) {
+			event(id, s.references[id], true)
+		})
+	}
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case nil:
+		case *FunctionDeclaration, *FunctionExpression, *ArrowFunction, *ClassDeclaration, *ModuleDeclaration:
+		case *VariableDeclaration:
+			for _, declarator := range n.Declarations {
+				if declarator.Init == nil {
+					continue
+				}
+				walk(declarator.Init)
+				if info := s.varRepeats[declarator]; info != nil {
+					event(declarator.Name, info, true)
+				}
+				for _, info := range tracked {
+					if info.Declarator == declarator {
+						event(declarator.Name, info, true)
+					}
+				}
+			}
+		case *AssignmentExpression:
+			if n.Operator != "=" {
+				walk(n.Target)
+			}
+			walk(n.Value)
+			write(n.Target)
+		case *UpdateExpression:
+			walk(n.Argument)
+			write(n.Argument)
+		case *Identifier:
+			event(n, s.references[n], false)
+		default:
+			for _, child := range n.Children() {
+				walk(child)
+			}
+		}
+	}
+	walk(node)
+}
+
+// reportUnassigned reporta la primera lectura sin valor de una variable. Un
+// 'var' leído antes de su declaración existe, pero aún vale undefined: es
+// código válido y se reporta como advertencia, no como error.
+func (s *Semantic) reportUnassigned(id *Identifier, info *VariableInfo) {
+	severity := SeverityError
+	if info.Keyword == "var" {
+		severity = SeverityWarning
+	}
+	message := "Variable '" + info.Name + "' usada antes de asignarle un valor"
+	related := "'" + info.Name + "' se declara sin valor inicial aquí"
+	if info.Declarator.Init != nil {
+		related = "'" + info.Name + "' recibe su valor aquí, pero no en todos los caminos"
+	}
+	if info.Keyword == "var" && id.Loc.Start.Offset < info.Loc.Start.Offset {
+		message += ": la declaración con 'var' de la línea " + strconv.Itoa(info.Line) +
+			" se eleva al principio de su ámbito, pero aquí aún vale undefined"
+		related = "'" + info.Name + "' se declara aquí"
+	}
+	s.report(newDiagnostic(PhaseSemantic, severity, codeUsedBeforeAssigned, id.Loc, message).
+		withRelated(info.Loc, related))
+}
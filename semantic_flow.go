@@ -0,0 +1,169 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ControlFlow devuelve el grafo de flujo de control del programa y de cada
+// función, construido durante Analyze.
+func (s *Semantic) ControlFlow() []*CFG {
+	return s.flow
+}
+
+// checkControlFlow construye los grafos de flujo de control y, con ellos,
+// busca el código al que nunca llega la ejecución, las funciones que pueden
+// terminar sin devolver el valor que anuncian y las variables que se leen
+// antes de tener valor.
+func (s *Semantic) checkControlFlow() {
+	s.flow = BuildControlFlow(s.program)
+	blocks := make(map[Node]*BasicBlock, 32)
+	for _, graph := range s.flow {
+		for _, block := range graph.Blocks {
+			for _, node := range block.Nodes {
+				blocks[node] = block
+			}
+		}
+	}
+
+	s.checkUnreachableCode(blocks)
+	for _, graph := range s.flow {
+		if isFunctionNode(graph.Node) {
+			s.checkMissingReturn(graph)
+		}
+		s.checkDefiniteAssignment(graph)
+	}
+}
+
+// checkUnreachableCode recorre cada lista de sentencias y reporta, una sola
+// vez por lista, desde la primera sentencia inalcanzable hasta el final.
+func (s *Semantic) checkUnreachableCode(blocks map[Node]*BasicBlock) {
+	skipped := make(map[Node]bool)
+	Inspect(s.program, func(n Node) bool {
+		if skipped[n] {
+			return false
+		}
+		list := statementList(n)
+		for i, stmt := range list {
+			if !isUnreachable(stmt, blocks) {
+				continue
+			}
+			message := "Código inalcanzable"
+			if i > 0 {
+				message += unreachableCause(list[i-1])
+			}
+			span := Range{Start: stmt.Span().Start, End: list[len(list)-1].Span().End}
+			s.report(newDiagnostic(PhaseSemantic, SeverityWarning, codeUnreachableCode, span, message))
+			for _, rest := range list[i:] {
+				skipped[rest] = true
+			}
+			break
+		}
+		return true
+	})
+}
+
+// statementList devuelve las sentencias que el nodo ejecuta en secuencia
+func statementList(n Node) []Statement {
+	switch n := n.(type) {
+	case *Program:
+		return n.Body
+	case *BlockStatement:
+		return n.Body
+	case *SwitchCase:
+		return n.Consequent
+	default:
+		return nil
+	}
+}
+
+// isUnreachable indica si ninguna parte de la sentencia puede ejecutarse. Las
+// funciones y los tipos declarados se elevan y no cuentan como código.
+func isUnreachable(stmt Statement, blocks map[Node]*BasicBlock) bool {
+	switch stmt.(type) {
+	case *FunctionDeclaration, *InterfaceDeclaration, *TypeAliasDeclaration:
+		return false
+	}
+	found, reachable := false, false
+	Inspect(stmt, func(n Node) bool {
+		if block, ok := blocks[n]; ok {
+			found = true
+			reachable = reachable || block.Reachable
+		}
+		return !reachable && (n == Node(stmt) || !isFunctionNode(n))
+	})
+	return found && !reachable
+}
+
+// unreachableCause explica por qué no se llega a lo que sigue a previous
+func unreachableCause(previous Statement) string {
+	line := strconv.Itoa(previous.Span().Start.Line)
+	switch previous := previous.(type) {
+	case *ReturnStatement:
+		return " después de 'return'"
+	case *BreakStatement:
+		return " después de 'break'"
+	case *ContinueStatement:
+		return " después de 'continue'"
+	case *WhileStatement, *DoWhileStatement, *ForStatement:
+		return ": el bucle de la línea " + line + " no termina nunca (no tiene 'break' que lo haga salir)"
+	case *IfStatement:
+		if previous.Alternate != nil {
+			return ": todas las ramas del 'if' de la línea " + line + " salen con 'return', 'break' o 'continue'"
+		}
+	case *SwitchStatement:
+		return ": todos los casos del 'switch' de la línea " + line + " salen con 'return' o 'continue'"
+	}
+	return ""
+}
+
+// checkMissingReturn reporta las funciones con un tipo de retorno anotado que
+// no admite 'undefined' y que pueden llegar al final del cuerpo sin 'return'.
+func (s *Semantic) checkMissingReturn(graph *CFG) {
+	name, _, returnRef, _ := functionParts(graph.Node)
+	if returnRef == nil || acceptsUndefined(s.resolveTypeNode(returnRef)) {
+		return
+	}
+
+	for _, edge := range graph.Edges {
+		from := graph.Blocks[edge.From]
+		if edge.To != graph.Exit || edge.Kind == "return" || !from.Reachable {
+			continue
+		}
+		function := "La función '" + graph.Name + "'"
+		switch {
+		case graph.Kind == "method":
+			function = "El método '" + graph.Name + "'"
+		case name == nil && strings.HasPrefix(graph.Name, "anónima"):
+			function = "La función"
+		}
+		d := newDiagnostic(PhaseSemantic, SeverityError, codeMissingReturn, returnRef.Span(),
+			function+" declara que devuelve '"+typeNodeString(returnRef)+"' pero puede terminar sin 'return'")
+		if len(from.Nodes) > 0 {
+			last := from.Nodes[len(from.Nodes)-1]
+			d = d.withRelated(last.Span(), "La ejecución puede llegar al final de la función después de aquí")
+		}
+		s.report(d)
+		return
+	}
+}
+
+// acceptsUndefined indica si el tipo admite que no haya valor: void,
+// undefined, any o una unión que incluya alguno. Una función con ese tipo de
+// retorno puede terminar sin 'return' y una variable, leerse sin asignar.
+func acceptsUndefined(t *Type) bool {
+	if t == nil {
+		return true
+	}
+	switch t.Kind {
+	case KindVoid, KindUndefined, KindAny:
+		return true
+	case KindUnion:
+		for _, member := range t.Types {
+			if acceptsUndefined(member) {
+				return true
+			}
+		}
+	}
+	return false
+}
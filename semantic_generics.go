@@ -0,0 +1,525 @@
+package main
+
+import "strconv"
+
+// maxInstantiationDepth y maxInstantiations cortan las instanciaciones que no
+// terminan nunca. 'interface Arbol<T> { hijos: Arbol<T[]> }' se anida sin fin
+// e 'interface G<T> { a: G<T[]>; b: G<G<T>> }' además se duplica en cada
+// nivel, así que limitar solo la profundidad no basta. El total se cuenta
+// desde la instanciación más externa, para que un tipo así no agote el
+// límite de los demás; pasado el límite, la instancia se trata como 'any'.
+const (
+	maxInstantiationDepth = 50
+	maxInstantiations     = 1000
+)
+
+// typeParameterType devuelve el tipo de un parámetro de tipo. Se guarda antes
+// de resolver su restricción para admitir 'T extends Comparable<T>'.
+func (s *Semantic) typeParameterType(param *TypeParameter) *Type {
+	if t, ok := s.typeParams[param]; ok {
+		return t
+	}
+	t := &Type{Kind: KindTypeParam, Name: param.Name.Name}
+	s.typeParams[param] = t
+
+	if param.Constraint != nil {
+		constraint := s.resolveTypeNode(param.Constraint)
+		if dependsOn(constraint, t) {
+			s.addError(codeTypeConstraint, param.Constraint, "El parámetro de tipo '"+t.Name+
+				"' no puede depender de sí mismo en su restricción")
+		} else {
+			t.Constraint = constraint
+		}
+	}
+	if param.Default != nil {
+		t.Default = s.resolveTypeNode(param.Default)
+		if t.Constraint != nil {
+			if ok, reason := assignable(t.Default, t.Constraint); !ok {
+				s.addError(codeTypeConstraint, param.Default, mismatchMessage("El tipo por defecto '"+
+					t.Default.String()+"' no cumple la restricción '"+t.Constraint.String()+
+					"' del parámetro de tipo '"+t.Name+"'", reason))
+			}
+		}
+	}
+	return t
+}
+
+// dependsOn indica si la restricción lleva, de parámetro en parámetro, hasta
+// el propio parámetro: 'T extends U, U extends T'.
+func dependsOn(constraint, param *Type) bool {
+	for t := constraint; t != nil && t.Kind == KindTypeParam; t = t.Constraint {
+		if t == param {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Semantic) typeParametersOf(params []*TypeParameter) []*Type {
+	if len(params) == 0 {
+		return nil
+	}
+	types := make([]*Type, len(params))
+	for i, param := range params {
+		types[i] = s.typeParameterType(param)
+	}
+	return types
+}
+
+// symbolTypeParams devuelve los parámetros de tipo de una interfaz, un alias
+// o una clase genéricos.
+func (s *Semantic) symbolTypeParams(sym *TypeSymbol) []*Type {
+	switch decl := sym.Decls[0].(type) {
+	case *InterfaceDeclaration:
+		return s.typeParametersOf(decl.TypeParams)
+	case *TypeAliasDeclaration:
+		return s.typeParametersOf(decl.TypeParams)
+	case *ClassDeclaration:
+		return s.typeParametersOf(decl.TypeParams)
+	default:
+		return nil
+	}
+}
+
+// instantiateReference resuelve el nombre de un tipo del programa con sus
+// argumentos de tipo: 'Caja<number>'. Un tipo genérico exige sus argumentos
+// y uno que no lo es no admite ninguno.
+func (s *Semantic) instantiateReference(ref *TypeReference, sym *TypeSymbol) *Type {
+	t := s.namedType(sym)
+	params := s.symbolTypeParams(sym)
+	if len(params) == 0 {
+		if len(ref.TypeArguments) > 0 {
+			s.addError(codeTypeArgumentCount, ref, "El tipo '"+sym.Name+"' no es genérico y no admite argumentos de tipo")
+		}
+		return t
+	}
+
+	args := s.checkTypeArguments(ref, "El tipo genérico '"+sym.Name+typeParamsString(params)+"'", params,
+		ref.TypeArguments)
+	if t.TypeParams != nil {
+		return s.instantiate(t, args)
+	}
+	// Alias de un tipo que no es estructurado: 'type Id<T> = T'
+	return s.substitute(t, typeMapping(params, args))
+}
+
+// checkTypeArguments resuelve los argumentos de tipo explícitos de un tipo,
+// una función o una clase genéricos: comprueba cuántos son y que cumplan las
+// restricciones, y completa los que faltan con su valor por defecto.
+func (s *Semantic) checkTypeArguments(node Node, owner string, params []*Type, argNodes []TypeNode) []*Type {
+	required := 0
+	for i, param := range params {
+		if param.Default == nil {
+			required = i + 1
+		}
+	}
+	if len(argNodes) < required || len(argNodes) > len(params) {
+		expected := strconv.Itoa(len(params))
+		if required < len(params) {
+			expected = "entre " + strconv.Itoa(required) + " y " + expected
+		}
+		s.addError(codeTypeArgumentCount, node, owner+" espera "+expected+" argumento(s) de tipo pero recibe "+
+			strconv.Itoa(len(argNodes)))
+	}
+
+	args := make([]*Type, len(params))
+	mapping := make(map[*Type]*Type, len(params))
+	for i, param := range params {
+		switch {
+		case i < len(argNodes):
+			args[i] = s.resolveTypeNode(argNodes[i])
+			mapping[param] = args[i]
+			s.checkConstraint(argNodes[i], param, args[i], mapping, "")
+		case param.Default != nil:
+			args[i] = s.substitute(param.Default, mapping)
+		default:
+			args[i] = anyType
+		}
+		mapping[param] = args[i]
+	}
+	for _, node := range argNodes[min(len(argNodes), len(params)):] {
+		s.resolveTypeNode(node)
+	}
+	return args
+}
+
+// checkConstraint reporta el argumento de tipo que no cumple la restricción
+// de su parámetro. La restricción puede nombrar otros parámetros, que se
+// sustituyen por sus argumentos.
+func (s *Semantic) checkConstraint(node Node, param, arg *Type, mapping map[*Type]*Type, origin string) {
+	if param.Constraint == nil {
+		return
+	}
+	constraint := s.substitute(param.Constraint, mapping)
+	if ok, reason := assignable(arg, constraint); !ok {
+		s.addError(codeTypeConstraint, node, mismatchMessage("El tipo '"+arg.String()+"'"+origin+
+			" no cumple la restricción '"+constraint.String()+"' del parámetro de tipo '"+param.Name+"'", reason))
+	}
+}
+
+// instantiateSignature sustituye los parámetros de tipo de una firma genérica
+// por los argumentos de tipo explícitos de la llamada o, si no los hay, por
+// los que se deducen de sus argumentos.
+func (s *Semantic) instantiateSignature(call Node, name string, sig *Signature, typeArgs []TypeNode,
+	args []Expression) *Signature {
+	if len(sig.TypeParams) == 0 {
+		if len(typeArgs) > 0 {
+			s.addError(codeTypeArgumentCount, typeArgs[0], "'"+name+"' no es genérica y no admite argumentos de tipo")
+		}
+		return sig
+	}
+
+	var types []*Type
+	if len(typeArgs) > 0 {
+		types = s.checkTypeArguments(call, "'"+name+"'", sig.TypeParams, typeArgs)
+	} else {
+		types = s.inferTypeArguments(sig, args)
+	}
+	instance := *s.substituteSignature(sig, typeMapping(sig.TypeParams, types))
+	instance.TypeParams = nil
+	return &instance
+}
+
+// inferTypeArguments deduce los argumentos de tipo de una llamada a una
+// función genérica comparando el tipo de cada argumento con el de su
+// parámetro. Los que no se pueden deducir toman su valor por defecto, su
+// restricción o 'any'.
+func (s *Semantic) inferTypeArguments(sig *Signature, args []Expression) []*Type {
+	inf := &inference{
+		params:  make(map[*Type]bool, len(sig.TypeParams)),
+		types:   make(map[*Type]*Type, len(sig.TypeParams)),
+		sources: make(map[*Type]Expression, len(sig.TypeParams)),
+	}
+	for _, param := range sig.TypeParams {
+		inf.params[param] = true
+	}
+	if spread := spreadIndex(args); spread >= 0 {
+		args = args[:spread]
+	}
+	for i, arg := range args {
+		expected := sig.Rest
+		if i < len(sig.Params) {
+			expected = &sig.Params[i]
+		}
+		if expected == nil {
+			break
+		}
+		inf.infer(expected.Type, s.typeOf(arg), arg)
+	}
+
+	types := make([]*Type, len(sig.TypeParams))
+	mapping := make(map[*Type]*Type, len(sig.TypeParams))
+	for i, param := range sig.TypeParams {
+		t := inf.types[param]
+		switch {
+		case t != nil:
+			mapping[param] = t
+			s.checkConstraint(inf.sources[param], param, t, mapping, " deducido del argumento")
+		case param.Default != nil:
+			t = s.substitute(param.Default, mapping)
+		case param.Constraint != nil:
+			t = s.substitute(param.Constraint, mapping)
+		default:
+			t = anyType
+		}
+		types[i] = t
+		mapping[param] = t
+	}
+	return types
+}
+
+// inference acumula los argumentos de tipo que se deducen en una llamada y el
+// argumento del que sale cada uno. Gana el primero que se encuentra.
+type inference struct {
+	params  map[*Type]bool
+	types   map[*Type]*Type
+	sources map[*Type]Expression
+	depth   int
+}
+
+// infer recorre a la vez el tipo esperado y el del argumento buscando los
+// parámetros de tipo: en 'T[]' frente a 'number[]', T es 'number'.
+func (inf *inference) infer(expected, actual *Type, source Expression) {
+	if expected == nil || actual.isAny() || inf.depth > maxInstantiationDepth {
+		return
+	}
+	inf.depth++
+	defer func() { inf.depth-- }()
+
+	switch {
+	case inf.params[expected]:
+		if inf.types[expected] != nil {
+			return
+		}
+		if actual.Literal != "" {
+			actual = widen(actual)
+		}
+		inf.types[expected] = actual
+		inf.sources[expected] = source
+	case expected.Origin != nil && expected.Origin == actual.Origin:
+		for i, arg := range expected.Arguments {
+			if i < len(actual.Arguments) {
+				inf.infer(arg, actual.Arguments[i], source)
+			}
+		}
+	case expected.Kind == KindArray && actual.Kind == KindArray:
+		inf.infer(expected.Element, actual.Element, source)
+	case expected.Kind == KindUnion:
+		inf.inferUnion(expected, actual, source)
+	case expected.Signature != nil && actual.Signature != nil:
+		for i, param := range expected.Signature.Params {
+			if i < len(actual.Signature.Params) {
+				inf.infer(param.Type, actual.Signature.Params[i].Type, source)
+			}
+		}
+		inf.infer(expected.Signature.Return, actual.Signature.Return, source)
+	case expected.Kind == KindObject && actual.hasProperties():
+		for _, prop := range expected.Properties {
+			if found := actual.property(prop.Name); found != nil {
+				inf.infer(prop.Type, found.Type, source)
+			}
+		}
+	}
+}
+
+// inferUnion deduce el parámetro de una unión como 'T | null': es la parte
+// del argumento que no encaja en los demás miembros.
+func (inf *inference) inferUnion(expected, actual *Type, source Expression) {
+	var param *Type
+	others := make([]*Type, 0, len(expected.Types))
+	for _, member := range expected.Types {
+		if inf.params[member] && param == nil {
+			param = member
+		} else {
+			others = append(others, member)
+		}
+	}
+	if param == nil {
+		return
+	}
+
+	candidates := []*Type{actual}
+	if actual.Kind == KindUnion {
+		candidates = actual.Types
+	}
+	rest := make([]*Type, 0, len(candidates))
+	for _, candidate := range candidates {
+		if len(others) == 0 || !isAssignable(candidate, unionOf(others...)) {
+			rest = append(rest, candidate)
+		}
+	}
+	if len(rest) > 0 {
+		inf.infer(param, unionOf(rest...), source)
+	}
+}
+
+// instantiate devuelve la instancia del tipo genérico con los argumentos
+// dados, 'Caja<number>'. Cada combinación se construye una sola vez, lo que
+// admite tipos recursivos como 'interface Nodo<T> { siguiente?: Nodo<T> }'.
+func (s *Semantic) instantiate(generic *Type, args []*Type) *Type {
+	if sameTypes(generic.TypeParams, args) {
+		return generic
+	}
+	key := joinTypes(args, ", ")
+	cache := s.instances[generic]
+	if cache == nil {
+		cache = make(map[string]*Type, 2)
+		s.instances[generic] = cache
+	}
+	if t := cache[key]; t != nil {
+		return t
+	}
+	if s.expanding == 0 {
+		s.instantiated = 0
+	}
+	if s.expanding > maxInstantiationDepth || s.instantiated >= maxInstantiations {
+		return anyType
+	}
+	s.instantiated++
+	s.expanding++
+	defer func() { s.expanding-- }()
+
+	name := generic.Name + "<" + key + ">"
+	if generic.Kind != KindObject {
+		// Alias genérico de una unión, un array o una función: 'type Quizas<T> = T | null'
+		t := s.substituteStructure(generic, typeMapping(generic.TypeParams, args))
+		if t != generic && t.Name == "" && isStructured(t) {
+			named := *t
+			named.Name, named.TypeParams, named.Origin, named.Arguments = name, nil, generic, args
+			t = &named
+		}
+		cache[key] = t
+		return t
+	}
+
+	t := &Type{Kind: KindObject, Name: name, Origin: generic, Arguments: args}
+	cache[key] = t
+	if pending, ok := s.incomplete[generic]; ok {
+		// El genérico aún se está construyendo: sus miembros se copiarán al terminar
+		s.incomplete[generic] = append(pending, t)
+		return t
+	}
+	s.fillInstance(t)
+	return t
+}
+
+// fillInstance copia en la instancia los miembros de su genérico con los
+// argumentos de tipo ya sustituidos.
+func (s *Semantic) fillInstance(t *Type) {
+	mapping := typeMapping(t.Origin.TypeParams, t.Arguments)
+	for _, prop := range t.Origin.Properties {
+		member := *prop
+		member.Type = s.substitute(prop.Type, mapping)
+		t.Properties = append(t.Properties, &member)
+	}
+}
+
+// startGeneric marca el genérico como en construcción; las instancias que se
+// pidan mientras tanto se completan en completeGeneric.
+func (s *Semantic) startGeneric(generic *Type) {
+	if generic.TypeParams != nil {
+		s.incomplete[generic] = nil
+	}
+}
+
+func (s *Semantic) completeGeneric(generic *Type) {
+	pending, ok := s.incomplete[generic]
+	if !ok {
+		return
+	}
+	delete(s.incomplete, generic)
+	for _, t := range pending {
+		s.fillInstance(t)
+	}
+}
+
+// substitute reemplaza en el tipo los parámetros de tipo por sus argumentos y
+// devuelve el mismo tipo si no menciona ninguno. Los tipos con nombre que no
+// son genéricos no pueden mencionarlos y se dejan tal cual.
+func (s *Semantic) substitute(t *Type, mapping map[*Type]*Type) *Type {
+	if t == nil || len(mapping) == 0 {
+		return t
+	}
+	if arg, ok := mapping[t]; ok {
+		return arg
+	}
+	switch {
+	case t.Origin != nil:
+		if args := s.substituteList(t.Arguments, mapping); args != nil {
+			return s.instantiate(t.Origin, args)
+		}
+		return t
+	case t.TypeParams != nil:
+		// El genérico nombrado dentro de su propia declaración
+		if args := s.substituteList(t.TypeParams, mapping); args != nil {
+			return s.instantiate(t, args)
+		}
+		return t
+	case t.Name != "":
+		return t
+	}
+	return s.substituteStructure(t, mapping)
+}
+
+// substituteStructure sustituye dentro de los componentes del tipo, sin mirar
+// su nombre.
+func (s *Semantic) substituteStructure(t *Type, mapping map[*Type]*Type) *Type {
+	switch t.Kind {
+	case KindArray:
+		if element := s.substitute(t.Element, mapping); element != t.Element {
+			return arrayOf(element)
+		}
+	case KindUnion:
+		if types := s.substituteList(t.Types, mapping); types != nil {
+			return unionOf(types...)
+		}
+	case KindIntersection:
+		if types := s.substituteList(t.Types, mapping); types != nil {
+			return &Type{Kind: KindIntersection, Types: types}
+		}
+	case KindFunction:
+		if t.Signature == nil {
+			return t
+		}
+		if sig := s.substituteSignature(t.Signature, mapping); sig != t.Signature {
+			return &Type{Kind: KindFunction, Signature: sig}
+		}
+	case KindObject:
+		changed := false
+		props := make([]*PropertyType, len(t.Properties))
+		for i, prop := range t.Properties {
+			props[i] = prop
+			if sub := s.substitute(prop.Type, mapping); sub != prop.Type {
+				member := *prop
+				member.Type = sub
+				props[i] = &member
+				changed = true
+			}
+		}
+		if changed {
+			return &Type{Kind: KindObject, Properties: props}
+		}
+	}
+	return t
+}
+
+// substituteList sustituye en cada tipo de la lista. Devuelve nil si ninguno
+// cambia, para que quien llama conserve el tipo original.
+func (s *Semantic) substituteList(types []*Type, mapping map[*Type]*Type) []*Type {
+	var result []*Type
+	for i, t := range types {
+		sub := s.substitute(t, mapping)
+		if sub != t && result == nil {
+			result = make([]*Type, len(types))
+			copy(result, types[:i])
+		}
+		if result != nil {
+			result[i] = sub
+		}
+	}
+	return result
+}
+
+// substituteSignature sustituye en los parámetros y el retorno de la firma;
+// los parámetros de tipo propios de la firma se conservan.
+func (s *Semantic) substituteSignature(sig *Signature, mapping map[*Type]*Type) *Signature {
+	result := *sig
+	changed := false
+	result.Params = make([]SignatureParam, len(sig.Params))
+	for i, p := range sig.Params {
+		result.Params[i] = param(p.Name, s.substitute(p.Type, mapping))
+		changed = changed || result.Params[i].Type != p.Type
+	}
+	if sig.Rest != nil {
+		rest := param(sig.Rest.Name, s.substitute(sig.Rest.Type, mapping))
+		result.Rest = &rest
+		changed = changed || rest.Type != sig.Rest.Type
+	}
+	result.Return = s.substitute(sig.Return, mapping)
+	if !changed && result.Return == sig.Return {
+		return sig
+	}
+	return &result
+}
+
+func typeMapping(params, args []*Type) map[*Type]*Type {
+	mapping := make(map[*Type]*Type, len(params))
+	for i, param := range params {
+		if i < len(args) {
+			mapping[param] = args[i]
+		}
+	}
+	return mapping
+}
+
+func sameTypes(a, b []*Type) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGenerics(t *testing.T) {
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name: "retorno deducido del argumento",
+			code: `function id<T>(x: T): T { return x; } let n: number = id("a");`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "retorno deducido compatible",
+			code:    "function id<T>(x: T): T { return x; } let n: number = id(1);",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "argumento de tipo explícito",
+			code: "function id<T>(x: T): T { return x; } let n = id<string>(1);",
+			want: []string{"type-mismatch@1:58"},
+		},
+		{
+			name: "deducción desde un array",
+			code: "function primero<T>(xs: T[]): T { return xs[0]; } let s: string = primero([1, 2]);",
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "interfaz instanciada",
+			code: `interface Caja<T> { v: T } let c: Caja<number> = { v: "a" };`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "faltan argumentos de tipo",
+			code: "interface Caja<T> { v: T } let c: Caja = { v: 1 };",
+			want: []string{"type-argument-count"},
+		},
+		{
+			name: "sobran argumentos de tipo",
+			code: "interface Caja<T> { v: T } let c: Caja<number, string> = { v: 1 };",
+			want: []string{"type-argument-count"},
+		},
+		{
+			name: "valor por defecto de un parámetro de tipo",
+			code: `type Par<A, B = A> = { a: A; b: B }; let p: Par<number> = { a: 1, b: "x" };`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "método de una clase genérica",
+			code: `class Pila<T> { items: T[] = []; push(x: T) { this.items.push(x); } } ` +
+				`let p = new Pila<number>(); p.push("a");`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "restricción de una función",
+			code: `function f<T extends number>(x: T) {} f("a");`,
+			want: []string{"type-constraint"},
+		},
+		{
+			name: "restricción de una interfaz",
+			code: "interface Caja<T extends string> { v: T } let c: Caja<number>;",
+			want: []string{"type-constraint"},
+		},
+		{
+			name:    "restricción con length y strings o arrays",
+			code:    `function len<T extends { length: number }>(x: T) { return x.length; } len("abc"); len([1]);`,
+			notWant: []string{"type-constraint"},
+		},
+		{
+			name: "restricción con length y un número",
+			code: "function len<T extends { length: number }>(x: T) { return x.length; } len(5);",
+			want: []string{"type-constraint"},
+		},
+	})
+}
+
+// Un genérico cuyos miembros piden instancias cada vez mayores de sí mismo
+// no debe colgar el análisis: las instancias se cortan al llegar al límite.
+func TestRunawayGenericInstantiation(t *testing.T) {
+	for _, code := range []string{
+		"interface G<T> { a: G<T[]>; b: G<G<T>> } let g: G<number>;",
+		"interface G<T> { a: G<T[]>; b: G<T[]>; c: G<T[]> } let g: G<number>; let y: G<string> = g;",
+		"type G<T> = { a: G<T[]>; b: G<G<T>>; c: G<G<G<T>>> }; let g: G<number>; let y: G<string> = g;",
+	} {
+		done := make(chan struct{})
+		go func() {
+			analyzeSource(code)
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("%q: el análisis no terminó en 5 segundos", code)
+		}
+	}
+}
@@ -0,0 +1,627 @@
+package main
+
+import (
+	"math"
+	"strconv"
+)
+
+// LoopReport resume el análisis de un bucle concreto para que los clientes
+// puedan señalarlo en el código sin interpretar los mensajes.
+type LoopReport struct {
+	Kind             string   `json:"kind"` // for, for...of, for...in, while o do-while
+	Start            Position `json:"start"`
+	End              Position `json:"end"`
+	Depth            int      `json:"depth"`  // 1 si no está dentro de otro bucle
+	Parent           int      `json:"parent"` // índice del bucle que lo contiene o -1
+	Variable         string   `json:"variable,omitempty"`
+	Condition        string   `json:"condition,omitempty"`
+	Step             int      `json:"step,omitempty"`    // avance de la variable de control en cada vuelta
+	Iterations       int      `json:"iterations"`        // -1 si no se puede calcular
+	TotalIterations  int      `json:"totalIterations"`   // ejecuciones del cuerpo contando los bucles exteriores; -1 si no se conocen
+	Formula          string   `json:"formula,omitempty"` // vueltas en función de valores no constantes
+	AtMost           bool     `json:"atMost,omitempty"`  // las vueltas son un máximo: el bucle puede salir antes
+	PossiblyInfinite bool     `json:"possiblyInfinite"`  // no se ve cómo puede terminar
+	NeverTerminates  bool     `json:"neverTerminates"`   // la variable de control nunca deja de cumplir la condición
+}
+
+// Loops devuelve el informe de cada bucle, en orden de aparición
+func (s *Semantic) Loops() []LoopReport {
+	return s.loops
+}
+
+// analyzeLoops analiza cada bucle por separado, en orden de aparición. Una
+// función declarada dentro de un bucle no cuenta como anidada en él: su
+// cuerpo se ejecuta cuando se la llama, no en cada vuelta.
+func (s *Semantic) analyzeLoops() {
+	var walk func(n Node, parent int)
+	walk = func(n Node, parent int) {
+		if isFunctionNode(n) {
+			parent = -1
+		}
+		if isLoopNode(n) {
+			parent = s.analyzeLoop(n, parent)
+		}
+		for _, child := range n.Children() {
+			walk(child, parent)
+		}
+	}
+	walk(s.program, -1)
+}
+
+// isLoopNode indica si el nodo es un bucle de cualquier tipo
+func isLoopNode(n Node) bool {
+	switch n.(type) {
+	case *ForStatement, *ForInStatement, *ForOfStatement, *WhileStatement, *DoWhileStatement:
+		return true
+	default:
+		return false
+	}
+}
+
+// analyzeLoop analiza un bucle y guarda su informe. Devuelve el índice del
+// informe, que es el padre de los bucles de su cuerpo.
+func (s *Semantic) analyzeLoop(loop Node, parent int) int {
+	span := loop.Span()
+	report := LoopReport{Start: span.Start, End: span.End, Depth: 1, Parent: parent, Iterations: -1}
+	if parent >= 0 {
+		report.Depth = s.loops[parent].Depth + 1
+	}
+
+	switch n := loop.(type) {
+	case *ForStatement:
+		report.Kind = "for"
+		s.analyzeForStatement(n, &report)
+	case *ForOfStatement:
+		report.Kind = "for...of"
+		s.analyzeForEachStatement(n, n.Left, n.Right, &report)
+	case *ForInStatement:
+		report.Kind = "for...in"
+		s.analyzeForEachStatement(n, n.Left, n.Right, &report)
+	case *WhileStatement:
+		report.Kind = "while"
+		s.analyzeWhileStatement(n, &report)
+	case *DoWhileStatement:
+		report.Kind = "do-while"
+		s.analyzeDoWhileStatement(n, &report)
+	}
+	s.checkInfiniteLoop(loop, &report)
+	s.reportNesting(loop, &report)
+
+	s.loops = append(s.loops, report)
+	return len(s.loops) - 1
+}
+
+// reportNesting calcula cuántas veces se ejecuta el cuerpo del bucle en total,
+// multiplicando sus vueltas por las de cada bucle que lo contiene, e informa
+// del nivel de los bucles anidados.
+func (s *Semantic) reportNesting(loop Node, report *LoopReport) {
+	report.TotalIterations = report.Iterations
+	if report.Parent < 0 {
+		return
+	}
+	outer := s.loops[report.Parent]
+	report.TotalIterations = multiplyIterations(outer.TotalIterations, report.Iterations)
+
+	message := "Bucle anidado en nivel " + strconv.Itoa(report.Depth) + ", dentro del bucle '" + outer.Kind +
+		"' de la línea " + strconv.Itoa(outer.Start.Line)
+	if report.TotalIterations >= 0 {
+		factors := strconv.Itoa(report.Iterations)
+		atMost := report.AtMost
+		for i := report.Parent; i >= 0; i = s.loops[i].Parent {
+			factors = strconv.Itoa(s.loops[i].Iterations) + " × " + factors
+			atMost = atMost || s.loops[i].AtMost
+		}
+		times := strconv.Itoa(report.TotalIterations)
+		if atMost {
+			times = "como máximo " + times
+		}
+		message += ": su cuerpo se ejecuta " + times + " veces en total (" + factors + ")"
+	}
+	s.addNote(codeLoopAnalysis, loop, message)
+}
+
+// multiplyIterations multiplica dos números de vueltas; -1 (desconocido) o un
+// producto que no cabe en un int32 dan -1.
+func multiplyIterations(a, b int) int {
+	if a < 0 || b < 0 {
+		return -1
+	}
+	if b != 0 && a > math.MaxInt32/b {
+		return -1
+	}
+	return a * b
+}
+
+// analyzeForStatement describe un 'for' y, si su variable de control avanza
+// un paso constante hacia un límite, calcula sus vueltas: exactas si el
+// inicio y el límite son literales y como fórmula si alguno es simbólico.
+func (s *Semantic) analyzeForStatement(loop *ForStatement, report *LoopReport) {
+	var loopVar, conditionVar, incrementVar string
+	var start Expression
+	var stepKnown bool
+
+	s.addNote(codeLoopAnalysis, loop, "Bucle 'for' detectado - Analizando estructura")
+
+	// Con varias variables ('let i = 0, j = n') la de control es la que se
+	// compara en la condición
+	names, values := forInitializers(loop.Init)
+	if i := controlVariable(loop.Test, names); i >= 0 {
+		loopVar = names[i].Name
+		report.Variable = loopVar
+		start = values[i]
+		if val, ok := numericConstant(start); ok {
+			s.addNote(codeLoopAnalysis, loop, "Variable de control '"+loopVar+"' inicializada con valor "+
+				formatNumber(val))
+		}
+	}
+
+	cond, written := findComparison(loop.Test, loopVar)
+	if cond != nil {
+		report.Condition = exprString(written)
+		if left, ok := cond.Left.(*Identifier); ok {
+			conditionVar = left.Name
+		}
+		if conditionVar != "" {
+			bound := exprString(cond.Right)
+			if val, ok := numericConstant(cond.Right); ok {
+				bound = formatNumber(val)
+			}
+			s.addNote(codeLoopAnalysis, loop, "Condición: '"+conditionVar+" "+cond.Operator+" "+bound+
+				"' - Variable de control se compara con "+bound)
+		}
+		first, constStart := numericConstant(start)
+		if limit, ok := numericConstant(cond.Right); ok && constStart && conditionVar == loopVar {
+			s.checkLoopConditionCoherence(cond, first, limit)
+		}
+	}
+
+	for _, update := range sequenceParts(loop.Update) {
+		var target Expression
+		var operator string
+		switch update := update.(type) {
+		case *UpdateExpression:
+			target, operator = update.Argument, update.Operator
+		case *AssignmentExpression:
+			target, operator = update.Target, update.Operator
+		default:
+			continue
+		}
+		name := ""
+		if id, ok := target.(*Identifier); ok {
+			name = id.Name
+		}
+		if incrementVar == "" || name == loopVar {
+			incrementVar = name
+		}
+		if step, ok := loopStep(update, loopVar); ok {
+			report.Step = step
+			stepKnown = true
+		}
+		s.addNote(codeLoopAnalysis, loop, "Incremento detectado para variable '"+name+"' ("+operator+")")
+	}
+
+	if loopVar != "" {
+		s.checkLoopVariableConsistency(loop, loopVar, conditionVar, incrementVar)
+	}
+
+	// Las vueltas solo se conocen si la variable cambia únicamente en la
+	// actualización y la comparación limita la condición entera
+	if cond == nil || start == nil || !stepKnown || conditionVar != loopVar || writesVariable(loop.Body, loopVar) {
+		return
+	}
+	bounded, atMost := boundedByComparison(loop.Test, written)
+	if !bounded || writesVariable(cond.Right, loopVar) || boundChanges(loop, cond.Right) {
+		return
+	}
+	s.reportLoopBound(loop, written, boundIterations(start, cond.Right, cond.Operator, report.Step),
+		atMost || exitsLoop(loop.Body), report)
+}
+
+// analyzeForEachStatement describe un 'for...of' o un 'for...in'. Si recorre
+// un array u objeto literal, el número de vueltas es exacto.
+func (s *Semantic) analyzeForEachStatement(loop Node, left Node, right Expression, report *LoopReport) {
+	switch left := left.(type) {
+	case *VariableDeclaration:
+		if len(left.Declarations) > 0 {
+			report.Variable = left.Declarations[0].displayName()
+		}
+	case Expression:
+		report.Variable = exprString(left)
+	}
+	what := "los elementos"
+	if report.Kind == "for...in" {
+		what = "las propiedades"
+	}
+	s.addNote(codeLoopAnalysis, loop, "Bucle '"+report.Kind+"' detectado - Recorre "+what+" de '"+
+		exprString(right)+"' con la variable '"+report.Variable+"'")
+
+	switch right := right.(type) {
+	case *ArrayLiteral:
+		if report.Kind == "for...of" && spreadIndex(right.Elements) < 0 {
+			report.Iterations = len(right.Elements)
+		}
+	case *ObjectLiteral:
+		if report.Kind == "for...in" {
+			report.Iterations = len(right.Properties)
+			for _, member := range right.Properties {
+				if _, ok := member.(*SpreadElement); ok {
+					report.Iterations = -1
+					break
+				}
+			}
+		}
+	}
+	if report.Iterations >= 0 {
+		s.addNote(codeLoopAnalysis, loop, "El bucle ejecutará exactamente "+strconv.Itoa(report.Iterations)+
+			" iteraciones")
+	}
+}
+
+// analyzeWhileStatement describe un 'while'. El parser ya separa el 'while'
+// que cierra un do-while del que abre un bucle nuevo.
+func (s *Semantic) analyzeWhileStatement(loop *WhileStatement, report *LoopReport) {
+	if loop.Test == nil {
+		return
+	}
+	s.addNote(codeLoopAnalysis, loop, "Bucle 'while' detectado - Analizando estructura")
+	s.reportConditionVariable(loop, loop.Test, report)
+}
+
+func (s *Semantic) analyzeDoWhileStatement(loop *DoWhileStatement, report *LoopReport) {
+	s.addNote(codeLoopAnalysis, loop, "Bucle 'do-while' detectado - Analizando estructura")
+	if loop.Test == nil {
+		s.addError(codeDoWithoutWhile, loop, "Bucle 'do' sin cláusula 'while' correspondiente")
+		return
+	}
+	s.addNote(codeLoopAnalysis, loop, "Cláusula 'while' encontrada en bucle do-while")
+	s.reportConditionVariable(loop, loop.Test, report)
+	s.addNote(codeLoopAnalysis, loop, "✓ Estructura do-while completa detectada")
+}
+
+// reportConditionVariable informa de la primera variable de la condición de
+// un bucle; si no está declarada, detectUndeclaredVariables ya lo reportó.
+func (s *Semantic) reportConditionVariable(loop Node, test Expression, report *LoopReport) {
+	report.Condition = exprString(test)
+	var conditionVar *Identifier
+	forEachReference(test, func(id *Identifier) {
+		if conditionVar == nil {
+			conditionVar = id
+		}
+	})
+	if conditionVar == nil {
+		return
+	}
+
+	report.Variable = conditionVar.Name
+	s.addNote(codeLoopAnalysis, loop, "Variable en condición "+report.Kind+": '"+conditionVar.Name+"'")
+	if s.references[conditionVar] != nil {
+		s.addNote(codeLoopAnalysis, loop, "✓ Variable '"+conditionVar.Name+"' en condición "+report.Kind+
+			" está correctamente declarada")
+	}
+}
+
+// checkInfiniteLoop avisa de un bucle con condición pero sin ninguna
+// actualización de variables ni salida ('break' o 'return'), que puede no
+// terminar nunca. Un 'for' sin condición equivale a 'for (; true; )': sin
+// salida no termina, actualice lo que actualice. Un 'for...of' o 'for...in'
+// siempre termina.
+func (s *Semantic) checkInfiniteLoop(loop Node, report *LoopReport) {
+	var hasValidCondition, hasIncrement bool
+	var body Statement
+
+	switch n := loop.(type) {
+	case *ForStatement:
+		if n.Test == nil {
+			if n.Body != nil && !exitsLoop(n.Body) {
+				report.PossiblyInfinite = true
+				report.NeverTerminates = true
+				s.addWarning(codeLoopNeverTerminates, loop,
+					"El bucle nunca termina: no tiene condición ni 'break' o 'return' que lo haga salir")
+			}
+			return
+		}
+		hasValidCondition = true
+		hasIncrement = n.Update != nil || containsUpdate(n.Body)
+		body = n.Body
+	case *DoWhileStatement:
+		hasValidCondition = n.Test != nil
+		hasIncrement = containsUpdate(n.Body)
+		body = n.Body
+	case *WhileStatement:
+		hasValidCondition = n.Test != nil
+		hasIncrement = containsUpdate(n.Body)
+		body = n.Body
+	default:
+		return
+	}
+
+	if !hasIncrement && hasValidCondition && !exitsLoop(body) {
+		report.PossiblyInfinite = true
+		s.addWarning(codePossibleInfiniteLoop, loop,
+			"Posible bucle infinito: no se detectó incremento en la variable de control")
+	} else if hasIncrement && hasValidCondition && !report.NeverTerminates {
+		s.addNote(codeLoopAnalysis, loop, "✓ Estructura de bucle válida: tiene condición e incremento")
+	}
+}
+
+// loopStep devuelve cuánto cambia la variable name con una actualización:
+// 'i++' es 1, 'i -= 2' es -2 e 'i = i + 3' es 3. ok es false si la
+// actualización no es de name o su paso no es un entero constante.
+func loopStep(update Expression, name string) (int, bool) {
+	switch u := update.(type) {
+	case *UpdateExpression:
+		if id, ok := u.Argument.(*Identifier); ok && id.Name == name {
+			if u.Operator == "++" {
+				return 1, true
+			}
+			return -1, true
+		}
+	case *AssignmentExpression:
+		if id, ok := u.Target.(*Identifier); !ok || id.Name != name {
+			return 0, false
+		}
+		operator, amount := u.Operator, u.Value
+		if operator == "=" {
+			// 'i = i + 3' o 'i = i - 3'
+			binary, ok := u.Value.(*BinaryExpression)
+			if !ok {
+				return 0, false
+			}
+			if id, ok := binary.Left.(*Identifier); !ok || id.Name != name {
+				return 0, false
+			}
+			operator, amount = binary.Operator+"=", binary.Right
+		}
+		value, ok := integerLiteral(amount)
+		switch {
+		case !ok:
+		case operator == "+=":
+			return value, true
+		case operator == "-=":
+			return -value, true
+		}
+	}
+	return 0, false
+}
+
+// forInitializers devuelve, en orden, las variables que inicializa la primera
+// cláusula de un for y sus valores: 'let i = 0, j = n' o 'i = 0, j = n'.
+func forInitializers(init Node) ([]*Identifier, []Expression) {
+	var names []*Identifier
+	var values []Expression
+	switch init := init.(type) {
+	case *VariableDeclaration:
+		for _, declarator := range init.Declarations {
+			if declarator.Name != nil {
+				names = append(names, declarator.Name)
+				values = append(values, declarator.Init)
+			}
+		}
+	case Expression:
+		for _, part := range sequenceParts(init) {
+			if assign, ok := part.(*AssignmentExpression); ok {
+				if id, ok := assign.Target.(*Identifier); ok {
+					names = append(names, id)
+					values = append(values, assign.Value)
+				}
+			}
+		}
+	}
+	return names, values
+}
+
+// controlVariable elige entre las variables inicializadas la que se compara
+// en la condición, o la primera si ninguna lo hace. Devuelve -1 si no hay.
+// Gana la que está escrita a la izquierda: en 'q > p' la de control es 'q'.
+func controlVariable(test Expression, names []*Identifier) int {
+	for _, mirrored := range []bool{false, true} {
+		for i, name := range names {
+			cond, written := findComparison(test, name.Name)
+			if cond == nil || (cond != written) != mirrored {
+				continue
+			}
+			if left, ok := cond.Left.(*Identifier); ok && left.Name == name.Name {
+				return i
+			}
+		}
+	}
+	if len(names) == 0 {
+		return -1
+	}
+	return 0
+}
+
+// sequenceParts devuelve las expresiones de 'a, b, c' o la propia expresión
+// si no es una secuencia.
+func sequenceParts(expr Expression) []Expression {
+	switch e := expr.(type) {
+	case nil:
+		return nil
+	case *SequenceExpression:
+		return e.Expressions
+	default:
+		return []Expression{e}
+	}
+}
+
+// integerLiteral devuelve el valor de un literal numérico entero, incluidos
+// los hexadecimales, binarios, con separadores o negados ('-1'); los
+// decimales y los valores fuera de rango no cuentan como paso del bucle.
+func integerLiteral(expr Expression) (int, bool) {
+	value, ok := numericConstant(expr)
+	if !ok || value != math.Trunc(value) {
+		return 0, false
+	}
+	return int(value), true
+}
+
+// numericConstant devuelve el valor de un literal numérico, entero o
+// decimal, posiblemente negado ('-0.5'); los valores fuera de rango no
+// cuentan como inicio ni límite del bucle.
+func numericConstant(expr Expression) (float64, bool) {
+	sign := 1.0
+	if unary, ok := expr.(*UnaryExpression); ok && unary.Operator == "-" {
+		sign = -1
+		expr = unary.Argument
+	}
+	lit, ok := expr.(*NumericLiteral)
+	if !ok {
+		return 0, false
+	}
+	value, ok := numericValue(lit.Raw)
+	if !ok || math.IsNaN(value) || math.Abs(value) > math.MaxInt32 {
+		return 0, false
+	}
+	return sign * value, true
+}
+
+// formatNumber escribe un valor numérico sin decimales de sobra: '3', '0.5'
+func formatNumber(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// findComparison localiza dentro de la condición la comparación que controla
+// el bucle: la primera en la que la variable de control (o cualquier variable
+// si no se conoce) es uno de los lados, aunque esté combinada con && o ||.
+// cond la devuelve con la variable a la izquierda, dando la vuelta a las
+// escritas al revés ('10 > i' pasa a ser 'i < 10'); written es la
+// comparación tal como aparece en el código.
+func findComparison(test Expression, loopVar string) (cond, written *BinaryExpression) {
+	isControl := func(e Expression) bool {
+		id, ok := e.(*Identifier)
+		return ok && (loopVar == "" || id.Name == loopVar)
+	}
+	Inspect(test, func(n Node) bool {
+		if written != nil {
+			return false
+		}
+		bin, ok := n.(*BinaryExpression)
+		if !ok {
+			return true
+		}
+		prec := operatorPrecedence(bin.Operator)
+		if prec != precRelational && prec != precEquality {
+			return true
+		}
+		switch {
+		case isControl(bin.Left):
+			cond, written = bin, bin
+		case isControl(bin.Right):
+			if operator, ok := mirroredOperator(bin.Operator); ok {
+				cond = &BinaryExpression{baseNode: bin.baseNode, Operator: operator, Left: bin.Right, Right: bin.Left}
+				written = bin
+			}
+		}
+		return written == nil
+	})
+	if written == nil && loopVar != "" {
+		return findComparison(test, "")
+	}
+	return cond, written
+}
+
+// mirroredOperator devuelve el operador que compara lo mismo con los lados
+// intercambiados: '<' para '>', '<=' para '>='. Los de igualdad no cambian.
+func mirroredOperator(operator string) (string, bool) {
+	switch operator {
+	case "<":
+		return ">", true
+	case ">":
+		return "<", true
+	case "<=":
+		return ">=", true
+	case ">=":
+		return "<=", true
+	case "==", "===", "!=", "!==":
+		return operator, true
+	default:
+		return "", false
+	}
+}
+
+// checkLoopConditionCoherence avisa si la condición ya es falsa con el valor
+// inicial de la variable de control.
+func (s *Semantic) checkLoopConditionCoherence(cond *BinaryExpression, start, end float64) {
+	switch cond.Operator {
+	case "<=", "<":
+		if start > end {
+			s.addWarning(codeLoopConditionNeverTrue, cond,
+				"La condición del bucle podría nunca ser verdadera (valor inicial mayor que final)")
+		}
+	case ">=", ">":
+		if start < end {
+			s.addWarning(codeLoopConditionNeverTrue, cond,
+				"La condición del bucle podría nunca ser verdadera (valor inicial menor que final)")
+		}
+	}
+}
+
+// exitsLoop indica si el cuerpo contiene un 'return' o un 'break' que sale
+// de este bucle (no de un bucle o switch anidado).
+func exitsLoop(body Node) bool {
+	found := false
+	Inspect(body, func(n Node) bool {
+		switch n.(type) {
+		case *BreakStatement, *ReturnStatement:
+			found = true
+		case *ForStatement, *ForInStatement, *ForOfStatement, *DoWhileStatement, *WhileStatement, *SwitchStatement:
+			// Un 'break' ahí dentro sale del anidado; solo cuenta un 'return'
+			Inspect(n, func(inner Node) bool {
+				if _, ok := inner.(*ReturnStatement); ok {
+					found = true
+				}
+				return !found
+			})
+			return false
+		}
+		return !found
+	})
+	return found
+}
+
+// containsUpdate indica si el nodo contiene un incremento o una asignación
+func containsUpdate(node Node) bool {
+	found := false
+	Inspect(node, func(n Node) bool {
+		switch n.(type) {
+		case *UpdateExpression, *AssignmentExpression:
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func (s *Semantic) checkLoopVariableConsistency(loop *ForStatement, loopVar, conditionVar, incrementVar string) {
+	var testNode, updateNode Node = loop, loop
+	if loop.Test != nil {
+		testNode = loop.Test
+	}
+	if loop.Update != nil {
+		updateNode = loop.Update
+	}
+
+	if conditionVar != "" && conditionVar != loopVar {
+		s.addError(codeLoopVariableMismatch, testNode, "Variable en condición '"+conditionVar+
+			"' no coincide con variable de control '"+loopVar+"'")
+	} else if conditionVar == loopVar {
+		s.addNote(codeLoopAnalysis, loop, "✓ Variable de condición '"+conditionVar+
+			"' coincide correctamente con variable de control")
+	}
+
+	if incrementVar != "" && incrementVar != loopVar {
+		s.addError(codeLoopVariableMismatch, updateNode, "Variable en incremento '"+incrementVar+
+			"' no coincide con variable de control '"+loopVar+"'")
+	} else if incrementVar == loopVar {
+		s.addNote(codeLoopAnalysis, loop, "✓ Variable de incremento '"+incrementVar+
+			"' coincide correctamente con variable de control")
+	}
+
+	if conditionVar == "" {
+		s.addWarning(codeLoopMissingCondition, testNode, "No se detectó variable en la condición del bucle")
+	}
+
+	if incrementVar == "" {
+		s.addWarning(codeLoopMissingUpdate, updateNode, "No se detectó variable en el incremento del bucle")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import "testing"
+
+// Un 'for' sin condición se trata como 'for (; true; )': solo termina si su
+// cuerpo sale con 'break' o 'return'.
+func TestForWithoutCondition(t *testing.T) {
+	for _, c := range []struct {
+		code     string
+		infinite bool
+	}{
+		{"for (;;) {}", true},
+		{"for (let i = 0;; i++) {}", true},
+		{"for (;;) { break; }", false},
+		{"function f() { for (;;) { return 1; } }", false},
+		{"for (;;) { for (;;) { break; } }", true},
+	} {
+		result := analyzeSource(c.code)
+		if len(result.Loops) == 0 {
+			t.Errorf("%q: no hay informe del bucle", c.code)
+			continue
+		}
+		report := result.Loops[0]
+		if report.PossiblyInfinite != c.infinite || report.NeverTerminates != c.infinite {
+			t.Errorf("%q: possiblyInfinite=%v neverTerminates=%v, se esperaba %v",
+				c.code, report.PossiblyInfinite, report.NeverTerminates, c.infinite)
+		}
+		if hasDiagnostic(result.Diagnostics, codeLoopNeverTerminates) != c.infinite {
+			t.Errorf("%q: aviso %q inesperado o ausente en %v", c.code, codeLoopNeverTerminates, result.Diagnostics)
+		}
+	}
+}
+
+// Con inicio y límite literales las vueltas se cuentan aunque no sean enteros
+func TestDecimalLoopBounds(t *testing.T) {
+	for _, c := range []struct {
+		code       string
+		iterations int
+	}{
+		{"for (let i = 0.5; i < 3; i++) {}", 3},
+		{"for (let i = 0; i <= 2.5; i++) {}", 3},
+		{"for (let i = 3.5; i > 0; i -= 2) {}", 2},
+		{"for (let i = 5.5; i < 3; i++) {}", 0},
+		{"for (let i = 0.5; i != 2.5; i++) {}", 2},
+	} {
+		result := analyzeSource(c.code)
+		if len(result.Loops) == 0 {
+			t.Errorf("%q: no hay informe del bucle", c.code)
+			continue
+		}
+		if got := result.Loops[0].Iterations; got != c.iterations {
+			t.Errorf("%q: %d vueltas, se esperaban %d (fórmula %q)", c.code, got, c.iterations, result.Loops[0].Formula)
+		}
+	}
+}
+
+// Una condición escrita al revés ('10 > i') controla el bucle igual que
+// 'i < 10'; el informe la conserva tal como está escrita.
+func TestMirroredLoopCondition(t *testing.T) {
+	for _, c := range []struct {
+		code       string
+		variable   string
+		iterations int
+	}{
+		{"for (let i = 0; 10 > i; i++) {}", "i", 10},
+		{"for (let i = 10; 0 <= i; i--) {}", "i", 11},
+		{"for (let i = 0; 10 < i; i++) {}", "i", 0},
+		{"for (let i = 0; 6 !== i; i += 2) {}", "i", 3},
+		{"for (let p = 0, q = 5; q > p; q--) {}", "q", -1},
+	} {
+		result := analyzeSource(c.code)
+		if hasDiagnostic(result.Diagnostics, codeLoopMissingCondition) {
+			t.Errorf("%q: aviso %q inesperado", c.code, codeLoopMissingCondition)
+		}
+		if len(result.Loops) == 0 {
+			t.Errorf("%q: no hay informe del bucle", c.code)
+			continue
+		}
+		report := result.Loops[0]
+		if report.Iterations != c.iterations {
+			t.Errorf("%q: %d vueltas, se esperaban %d", c.code, report.Iterations, c.iterations)
+		}
+		if report.Variable != c.variable {
+			t.Errorf("%q: variable de control %q, se esperaba %q", c.code, report.Variable, c.variable)
+		}
+	}
+}
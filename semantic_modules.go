@@ -0,0 +1,409 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// enumTypes son los tipos que introduce un enum: el del objeto con sus
+// miembros ('typeof Color') y el de sus valores ('Color'), que es la unión de
+// los tipos de cada miembro ('Color.Rojo').
+type enumTypes struct {
+	object  *Type
+	enum    *Type
+	members map[*EnumMember]*Type
+}
+
+// namespaceTypes guarda el ámbito del cuerpo de un namespace y, una vez
+// calculado, el tipo del objeto con sus miembros exportados.
+type namespaceTypes struct {
+	scope  *Scope
+	object *Type
+}
+
+// enumTypesOf calcula el valor de cada miembro del enum. Sin inicializador un
+// miembro vale uno más que el anterior, y el primero 0; después de un miembro
+// de cadena o calculado el inicializador es obligatorio.
+func (s *Semantic) enumTypesOf(enum *EnumDeclaration) *enumTypes {
+	if t, ok := s.enums[enum]; ok {
+		return t
+	}
+	name := enum.Name.Name
+	t := &enumTypes{
+		object:  &Type{Kind: KindObject, Name: "typeof " + name},
+		enum:    &Type{Kind: KindUnion, Name: name},
+		members: make(map[*EnumMember]*Type, len(enum.Members)),
+	}
+	// Se guarda antes de calcular los valores, que pueden usar miembros anteriores
+	s.enums[enum] = t
+
+	var next float64
+	autoIncrement := true
+	for _, member := range enum.Members {
+		var value *Type
+		switch {
+		case member.Initializer != nil:
+			value = s.enumInitializer(enum, member)
+		case autoIncrement:
+			value = numberLiteral(next)
+		default:
+			s.addError(codeInvalidEnumInitializer, member, "El miembro '"+member.Name.Name+
+				"' necesita un valor porque el anterior no es un número constante")
+			value = numberType
+		}
+		autoIncrement = value.Kind == KindNumber && value.Literal != ""
+		if autoIncrement {
+			current, _ := strconv.ParseFloat(value.Literal, 64)
+			next = current + 1
+		}
+
+		memberType := &Type{Kind: value.Kind, Literal: value.Literal, Name: name + "." + member.Name.Name}
+		t.members[member] = memberType
+		t.enum.Types = append(t.enum.Types, memberType)
+		t.object.setProperty(&PropertyType{Name: member.Name.Name, Type: memberType, Readonly: true})
+	}
+	return t
+}
+
+// enumInitializer evalúa el valor explícito de un miembro. Las expresiones
+// constantes se calculan; cualquier otra solo se admite si es numérica y el
+// enum no es 'const'.
+func (s *Semantic) enumInitializer(enum *EnumDeclaration, member *EnumMember) *Type {
+	if value := s.constantValue(member.Initializer); value != nil {
+		if value.Kind == KindNumber {
+			if n, _ := strconv.ParseFloat(value.Literal, 64); math.IsNaN(n) || math.IsInf(n, 0) {
+				s.addError(codeInvalidEnumInitializer, member.Initializer, "El valor del miembro '"+
+					member.Name.Name+"' no es un número finito")
+				return numberType
+			}
+		}
+		return value
+	}
+
+	t := widen(s.typeOf(member.Initializer))
+	switch {
+	case enum.Const:
+		s.addError(codeInvalidEnumInitializer, member.Initializer, "Los miembros de un 'const enum' deben tener "+
+			"un valor constante, y '"+exprString(member.Initializer)+"' no lo es")
+	case t == stringType:
+		s.addError(codeInvalidEnumInitializer, member.Initializer, "El miembro '"+member.Name.Name+
+			"' solo puede tener como valor una cadena literal, no una calculada")
+	case !t.isAny() && t != numberType:
+		s.addError(codeInvalidEnumInitializer, member.Initializer, "El valor del miembro '"+member.Name.Name+
+			"' debe ser un número o una cadena, no '"+t.String()+"'")
+	}
+	return numberType
+}
+
+// constantValue calcula el valor de una expresión constante de un enum:
+// literales, miembros ya definidos (por su nombre o como 'Color.Rojo') y
+// operadores entre ellos. Devuelve nil si la expresión no es constante.
+func (s *Semantic) constantValue(expr Expression) *Type {
+	switch e := expr.(type) {
+	case *NumericLiteral, *StringLiteral:
+		if t := literalType(e); t != nil && t.Kind != KindBigInt {
+			return t
+		}
+	case *TemplateLiteral:
+		if len(e.Expressions) == 0 && len(e.Quasis) == 1 {
+			return &Type{Kind: KindString, Literal: "\"" + e.Quasis[0] + "\""}
+		}
+	case *Identifier:
+		info := s.references[e]
+		if info == nil {
+			return nil
+		}
+		member, ok := info.Node.(*EnumMember)
+		if !ok {
+			return nil
+		}
+		t := s.enumTypesOf(info.Scope.Node.(*EnumDeclaration)).members[member]
+		if t == nil {
+			s.addError(codeInvalidEnumInitializer, e, "El miembro '"+e.Name+"' se usa antes de su declaración")
+			return nil
+		}
+		return constantOf(t)
+	case *MemberExpression:
+		if s.enumOf(s.typeOf(e.Object)) != nil {
+			return constantOf(s.typeOf(e))
+		}
+	case *UnaryExpression:
+		value := s.constantValue(e.Argument)
+		if value == nil || value.Kind != KindNumber {
+			return nil
+		}
+		n, _ := strconv.ParseFloat(value.Literal, 64)
+		switch e.Operator {
+		case "-":
+			return numberLiteral(-n)
+		case "+":
+			return value
+		case "~":
+			return numberLiteral(float64(^toInt32(n)))
+		}
+	case *BinaryExpression:
+		left, right := s.constantValue(e.Left), s.constantValue(e.Right)
+		if left == nil || right == nil {
+			return nil
+		}
+		if left.Kind == KindString || right.Kind == KindString {
+			if e.Operator != "+" {
+				return nil
+			}
+			return &Type{Kind: KindString, Literal: "\"" + constantText(left) + constantText(right) + "\""}
+		}
+		a, _ := strconv.ParseFloat(left.Literal, 64)
+		b, _ := strconv.ParseFloat(right.Literal, 64)
+		if result, ok := constantArithmetic(e.Operator, a, b); ok {
+			return numberLiteral(result)
+		}
+	}
+	return nil
+}
+
+// constantOf devuelve el valor de un miembro de enum sin el nombre del
+// miembro, o nil si su valor no es constante.
+func constantOf(t *Type) *Type {
+	if t.Literal == "" {
+		return nil
+	}
+	return &Type{Kind: t.Kind, Literal: t.Literal}
+}
+
+// constantText es el texto de un valor constante al concatenarlo a una cadena
+func constantText(t *Type) string {
+	if t.Kind == KindString {
+		return stringContent(t.Literal)
+	}
+	return t.Literal
+}
+
+// constantArithmetic aplica un operador numérico con la semántica de
+// JavaScript: los operadores de bits trabajan con enteros de 32 bits.
+func constantArithmetic(operator string, a, b float64) (float64, bool) {
+	switch operator {
+	case "+":
+		return a + b, true
+	case "-":
+		return a - b, true
+	case "*":
+		return a * b, true
+	case "/":
+		return a / b, true
+	case "%":
+		return math.Mod(a, b), true
+	case "**":
+		return math.Pow(a, b), true
+	case "&":
+		return float64(toInt32(a) & toInt32(b)), true
+	case "|":
+		return float64(toInt32(a) | toInt32(b)), true
+	case "^":
+		return float64(toInt32(a) ^ toInt32(b)), true
+	case "<<":
+		return float64(toInt32(a) << (uint32(toInt32(b)) & 31)), true
+	case ">>":
+		return float64(toInt32(a) >> (uint32(toInt32(b)) & 31)), true
+	case ">>>":
+		return float64(uint32(toInt32(a)) >> (uint32(toInt32(b)) & 31)), true
+	default:
+		return 0, false
+	}
+}
+
+func toInt32(n float64) int32 {
+	if math.IsNaN(n) || math.IsInf(n, 0) {
+		return 0
+	}
+	return int32(int64(n))
+}
+
+// enumMemberValue es el valor de un miembro de enum tal como se muestra en el
+// informe: el literal o 'calculado' si solo se conoce al ejecutar.
+func (s *Semantic) enumMemberValue(info *VariableInfo) string {
+	t := s.enumTypesOf(info.Scope.Node.(*EnumDeclaration)).members[info.Node.(*EnumMember)]
+	if t == nil || t.Literal == "" {
+		return "calculado"
+	}
+	return t.Literal
+}
+
+// enumOf devuelve el enum cuyo objeto es t, o nil si t no es un enum
+func (s *Semantic) enumOf(t *Type) *EnumDeclaration {
+	if t.Kind != KindObject {
+		return nil
+	}
+	for enum, types := range s.enums {
+		if types.object == t {
+			return enum
+		}
+	}
+	return nil
+}
+
+// namespaceType construye el tipo del objeto de un namespace con sus miembros
+// exportados. Desde fuera solo se pueden reasignar sus 'let' y 'var'.
+func (s *Semantic) namespaceType(module *ModuleDeclaration) *Type {
+	ns := s.namespaces[module]
+	if ns == nil {
+		return anyType
+	}
+	if ns.object != nil {
+		return ns.object
+	}
+	ns.object = &Type{Kind: KindObject, Name: "typeof " + module.Name.Name}
+	for _, stmt := range module.Body.Body {
+		export, ok := stmt.(*ExportDeclaration)
+		if !ok {
+			continue
+		}
+		decl, isVariable := export.Declaration.(*VariableDeclaration)
+		values, _ := declaredNames(export.Declaration)
+		for _, name := range values {
+			info := ns.scope.LookupLocal(name.Name)
+			if info == nil {
+				continue
+			}
+			ns.object.setProperty(&PropertyType{
+				Name:     name.Name,
+				Type:     s.symbolType(info),
+				Readonly: !isVariable || decl.Keyword == "const",
+			})
+		}
+	}
+	return ns.object
+}
+
+// namespaceExports devuelve los nombres que exporta el namespace, sin repetir
+// los que son a la vez valor y tipo.
+func (s *Semantic) namespaceExports(module *ModuleDeclaration) []string {
+	if module.Body == nil {
+		return nil
+	}
+	var names []string
+	seen := make(map[string]bool, 4)
+	for _, stmt := range module.Body.Body {
+		export, ok := stmt.(*ExportDeclaration)
+		if !ok {
+			continue
+		}
+		values, types := declaredNames(export.Declaration)
+		for _, name := range append(values, types...) {
+			if !seen[name.Name] {
+				seen[name.Name] = true
+				names = append(names, name.Name)
+			}
+		}
+	}
+	return names
+}
+
+// reportMissingMember explica por qué falta un miembro de un enum o de un
+// namespace. Devuelve false si el objeto no es ninguno de los dos y hay que
+// reportarlo como una propiedad desconocida.
+func (s *Semantic) reportMissingMember(object *Type, member *MemberExpression) bool {
+	name := member.Property.Name
+	if enum := s.enumOf(object); enum != nil {
+		s.addError(codeUnknownEnumMember, member.Property, "El enum '"+enum.Name.Name+"' no tiene un miembro '"+
+			name+"'")
+		return true
+	}
+	for module, ns := range s.namespaces {
+		if ns.object != object {
+			continue
+		}
+		info := ns.scope.LookupLocal(name)
+		if info == nil {
+			return false
+		}
+		s.reportNotExported(member.Property, name, module, info.Loc)
+		return true
+	}
+	return false
+}
+
+func (s *Semantic) reportNotExported(node Node, name string, module *ModuleDeclaration, declared Range) {
+	s.report(newDiagnostic(PhaseSemantic, SeverityError, codeNotExported, node.Span(),
+		"'"+name+"' no se exporta desde el namespace '"+module.Name.Name+"'").
+		withRelated(declared, "'"+name+"' se declara aquí sin 'export'"))
+}
+
+// qualifiedType resuelve un nombre de tipo calificado: 'Geo.Punto' es un tipo
+// exportado por el namespace 'Geo' y 'Color.Rojo' el de un miembro del enum.
+func (s *Semantic) qualifiedType(ref *TypeReference, scope *Scope) *Type {
+	parts := strings.Split(ref.Name, ".")
+	info := scope.Lookup(parts[0])
+	for i := 1; i < len(parts) && info != nil; i++ {
+		name, last := parts[i], i == len(parts)-1
+		switch decl := info.Node.(type) {
+		case *EnumDeclaration:
+			info = nil
+			if !last {
+				break
+			}
+			for _, member := range decl.Members {
+				if member.Name.Name == name {
+					return s.enumTypesOf(decl).members[member]
+				}
+			}
+			s.addError(codeUnknownEnumMember, ref, "El enum '"+decl.Name.Name+"' no tiene un miembro '"+name+"'")
+			return anyType
+		case *ModuleDeclaration:
+			ns := s.namespaces[decl]
+			if ns == nil {
+				return anyType
+			}
+			if !last {
+				info = ns.scope.LookupLocal(name)
+				if info != nil && !info.Exported {
+					s.reportNotExported(ref, name, decl, info.Loc)
+					return anyType
+				}
+				break
+			}
+			info = nil
+			if sym := ns.scope.types[name]; sym != nil {
+				if !sym.Exported {
+					s.reportNotExported(ref, name, decl, sym.Decls[0].Span())
+					return anyType
+				}
+				return s.namedType(sym)
+			}
+		default:
+			info = nil
+		}
+	}
+	s.addError(codeUnknownType, ref, "Tipo '"+ref.Name+"' desconocido")
+	return anyType
+}
+
+// checkConstEnumUsage reporta los usos de un 'const enum' como valor: sus
+// miembros se sustituyen al compilar y el objeto no existe en ejecución, así
+// que solo se puede acceder a ellos por nombre.
+func (s *Semantic) checkConstEnumUsage() {
+	allowed := make(map[*Identifier]bool, 8)
+	Inspect(s.program, func(n Node) bool {
+		switch n := n.(type) {
+		case *MemberExpression:
+			if id, ok := n.Object.(*Identifier); ok {
+				allowed[id] = true
+			}
+		case *IndexExpression:
+			id, ok := n.Object.(*Identifier)
+			if _, isName := n.Index.(*StringLiteral); ok && isName {
+				allowed[id] = true
+			}
+		case *Identifier:
+			info := s.references[n]
+			if info == nil || allowed[n] {
+				break
+			}
+			if enum, ok := info.Node.(*EnumDeclaration); ok && enum.Const {
+				s.addError(codeConstEnumValue, n, "El 'const enum' '"+n.Name+
+					"' solo se puede usar para acceder a sus miembros por nombre")
+			}
+		}
+		return true
+	})
+}
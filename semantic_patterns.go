@@ -0,0 +1,258 @@
+package main
+
+// patternNames devuelve, en orden, los nombres que declara un destino de
+// desestructuración, incluidos los de patrones anidados y los del resto.
+func patternNames(pattern Pattern) []*Identifier {
+	var names []*Identifier
+	var collect func(Pattern)
+	collect = func(pattern Pattern) {
+		switch p := pattern.(type) {
+		case *Identifier:
+			names = append(names, p)
+		case *ArrayPattern:
+			for _, element := range p.Elements {
+				if element != nil {
+					collect(element.Target)
+				}
+			}
+			collect(p.Rest)
+		case *ObjectPattern:
+			for _, prop := range p.Properties {
+				collect(prop.Target)
+			}
+			if p.Rest != nil {
+				names = append(names, p.Rest)
+			}
+		}
+	}
+	collect(pattern)
+	return names
+}
+
+// declaratorNames devuelve las variables que declara un declarador: su nombre
+// o todos los de su patrón.
+func declaratorNames(declarator *VariableDeclarator) []*Identifier {
+	if declarator.Name != nil {
+		return []*Identifier{declarator.Name}
+	}
+	return patternNames(declarator.Pattern)
+}
+
+// parameterNames devuelve las variables que declara un parámetro
+func parameterNames(param *Parameter) []*Identifier {
+	if param.Name != nil {
+		return []*Identifier{param.Name}
+	}
+	return patternNames(param.Pattern)
+}
+
+// bindPattern resuelve los valores por defecto y las claves calculadas de un
+// patrón, que se evalúan en el ámbito de la declaración.
+func (s *Semantic) bindPattern(pattern Pattern, scope *Scope) {
+	switch p := pattern.(type) {
+	case *ArrayPattern:
+		for _, element := range p.Elements {
+			if element != nil {
+				s.bindExpression(element.Default, scope)
+				s.bindPattern(element.Target, scope)
+			}
+		}
+		s.bindPattern(p.Rest, scope)
+	case *ObjectPattern:
+		for _, prop := range p.Properties {
+			if prop.Computed {
+				s.bindExpression(prop.Key, scope)
+			}
+			s.bindExpression(prop.Default, scope)
+			s.bindPattern(prop.Target, scope)
+		}
+	}
+}
+
+// destructuredType devuelve el tipo de una variable declarada dentro de un
+// patrón, que sale del tipo del valor desestructurado.
+func (s *Semantic) destructuredType(info *VariableInfo) *Type {
+	var pattern Pattern
+	switch node := info.Node.(type) {
+	case *VariableDeclarator:
+		pattern = node.Pattern
+	case *Parameter:
+		pattern = node.Pattern
+	}
+	s.destructure(info.Node, info.Keyword)
+	for _, name := range patternNames(pattern) {
+		if name.Name == info.Name {
+			if t, ok := s.patternTypes[name]; ok {
+				return t
+			}
+		}
+	}
+	return anyType
+}
+
+// destructure calcula una sola vez el tipo de cada parte del patrón de un
+// declarador o de un parámetro a partir del tipo del valor: el anotado o, si
+// no lo hay, el del valor inicial o por defecto.
+func (s *Semantic) destructure(owner Node, keyword string) {
+	var pattern Pattern
+	var source func() *Type
+	switch node := owner.(type) {
+	case *VariableDeclarator:
+		pattern = node.Pattern
+		source = func() *Type {
+			if t := s.annotationType(keyword, node); t != nil {
+				return t
+			}
+			return s.initializerType(node)
+		}
+	case *Parameter:
+		pattern = node.Pattern
+		source = func() *Type { return s.inferredParameterType(node) }
+	}
+	if pattern == nil {
+		return
+	}
+	if _, done := s.patternTypes[pattern]; done {
+		return
+	}
+	// Marca provisional para cortar ciclos como 'let { a } = a'
+	s.patternTypes[pattern] = anyType
+	s.checkPattern(pattern, source())
+}
+
+// checkPattern reparte el tipo source entre las partes del patrón y reporta
+// lo que no se puede desestructurar: un valor no iterable en un patrón de
+// array o una propiedad que el objeto no tiene.
+func (s *Semantic) checkPattern(pattern Pattern, source *Type) {
+	s.patternTypes[pattern] = source
+	switch p := pattern.(type) {
+	case *ArrayPattern:
+		element, ok := iteratedType(source)
+		if !ok {
+			s.addError(codeTypeMismatch, p, "No se puede desestructurar como array un valor de tipo '"+
+				source.String()+"'")
+		}
+		for _, el := range p.Elements {
+			if el != nil {
+				s.checkPattern(el.Target, s.defaultedType(el.Target, element, el.Default))
+			}
+		}
+		if p.Rest != nil {
+			s.checkPattern(p.Rest, arrayOf(element))
+		}
+	case *ObjectPattern:
+		if source.Kind == KindNull || source.Kind == KindUndefined {
+			s.addError(codeTypeMismatch, p, "No se puede desestructurar un valor de tipo '"+source.String()+"'")
+			source = anyType
+		}
+		taken := make(map[string]bool, len(p.Properties))
+		for _, prop := range p.Properties {
+			name, known := memberKey(prop.Key, prop.Computed)
+			taken[name] = known
+			t := s.destructuredProperty(source, prop, name, known)
+			s.checkPattern(prop.Target, s.defaultedType(prop.Target, t, prop.Default))
+		}
+		if p.Rest != nil {
+			s.checkPattern(p.Rest, restProperties(source, taken))
+		}
+	}
+}
+
+// destructuredProperty devuelve el tipo de la propiedad que extrae una entrada
+// de un patrón de objeto. Con una clave calculada que no es un literal no se
+// sabe qué propiedad se lee.
+func (s *Semantic) destructuredProperty(source *Type, prop *PatternProperty, name string, known bool) *Type {
+	if prop.Computed {
+		s.typeOf(prop.Key)
+	}
+	switch {
+	case source.isAny() || !known:
+		return anyType
+	case source.hasProperties():
+		if member := source.property(name); member != nil {
+			return member.Type
+		}
+		s.addError(codeUnknownProperty, prop.Key, "La propiedad '"+name+"' no existe en el tipo '"+
+			source.String()+"'")
+		return anyType
+	case apparentMembers(source) != nil:
+		if member := apparentMembers(source).property(name); member != nil {
+			return member.Type
+		}
+		return anyType
+	default:
+		return anyType
+	}
+}
+
+// defaultedType comprueba el valor por defecto de una parte del patrón contra
+// el tipo que le corresponde. Si ese tipo no se conoce, manda el del valor.
+func (s *Semantic) defaultedType(target Pattern, t *Type, value Expression) *Type {
+	if value == nil {
+		return t
+	}
+	if t.isAny() {
+		return widen(s.typeOf(value))
+	}
+	if ok, reason := s.valueAssignable(value, t); !ok {
+		s.addError(codeTypeMismatch, value, mismatchMessage("El valor por defecto de tipo '"+
+			s.displayType(value).String()+"' no se puede asignar a '"+patternString(target)+"' de tipo '"+
+			t.String()+"'", reason))
+	}
+	return t
+}
+
+// memberKey devuelve el nombre de una clave de objeto. Una clave calculada
+// solo tiene nombre conocido si es un literal: '["a"]' o '[0]'.
+func memberKey(key Expression, computed bool) (string, bool) {
+	if computed {
+		switch key.(type) {
+		case *StringLiteral, *NumericLiteral:
+		default:
+			return "", false
+		}
+	}
+	return propertyName(key), true
+}
+
+// restProperties es el tipo de '...resto' en un patrón de objeto: las
+// propiedades del origen que no se extrajeron antes.
+func restProperties(source *Type, taken map[string]bool) *Type {
+	if !source.hasProperties() {
+		return anyType
+	}
+	rest := &Type{Kind: KindObject}
+	for _, prop := range propertiesOf(source) {
+		if !taken[prop.Name] {
+			rest.setProperty(prop)
+		}
+	}
+	return rest
+}
+
+// iteratedType devuelve el tipo de los elementos que se obtienen al recorrer
+// un valor, como hacen un patrón de array o '...valor'. ok es false si el
+// valor no se puede recorrer.
+func iteratedType(t *Type) (*Type, bool) {
+	t = apparentType(t)
+	switch {
+	case t.isAny():
+		return anyType, true
+	case t.Kind == KindArray:
+		return t.Element, true
+	case widen(t) == stringType:
+		return stringType, true
+	case t.Kind == KindUnion:
+		elements := make([]*Type, 0, len(t.Types))
+		for _, member := range t.Types {
+			element, ok := iteratedType(member)
+			if !ok {
+				return anyType, false
+			}
+			elements = append(elements, element)
+		}
+		return unionOf(elements...), true
+	default:
+		return anyType, false
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// diagnosticCase describe lo que se espera del análisis de un fragmento. Cada
+// entrada de want y notWant es un código de diagnóstico, opcionalmente con la
+// posición en que debe (o no debe) aparecer: 'redeclared-variable@1:18'.
+type diagnosticCase struct {
+	name    string
+	code    string
+	want    []string
+	notWant []string
+}
+
+func runDiagnosticCases(t *testing.T, cases []diagnosticCase) {
+	t.Helper()
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			diagnostics := analyzeSource(c.code).Diagnostics
+			for _, want := range c.want {
+				if !matchesDiagnostic(diagnostics, want) {
+					t.Errorf("falta %q en:\n%s", want, strings.Join(diagnosticStrings(diagnostics), "\n"))
+				}
+			}
+			for _, unwanted := range c.notWant {
+				if matchesDiagnostic(diagnostics, unwanted) {
+					t.Errorf("sobra %q en:\n%s", unwanted, strings.Join(diagnosticStrings(diagnostics), "\n"))
+				}
+			}
+		})
+	}
+}
+
+func matchesDiagnostic(diagnostics []Diagnostic, want string) bool {
+	code, position, _ := strings.Cut(want, "@")
+	for _, d := range diagnostics {
+		if d.Code != code {
+			continue
+		}
+		if position == "" || position == strconv.Itoa(d.Start.Line)+":"+strconv.Itoa(d.Start.Column) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestRedeclarationOrder(t *testing.T) {
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name:    "var en un bloque después de let",
+			code:    "let b = 1; { var b = 2; }",
+			want:    []string{"redeclared-variable@1:18"},
+			notWant: []string{"redeclared-variable@1:5"},
+		},
+		{
+			name:    "var después de let en el mismo ámbito",
+			code:    "let e = 1; var e = 2;",
+			want:    []string{"redeclared-variable@1:16"},
+			notWant: []string{"redeclared-variable@1:5"},
+		},
+		{
+			name: "let después de var",
+			code: "var d = 1; let d = 2;",
+			want: []string{"redeclared-variable@1:16"},
+		},
+		{
+			name:    "let en un bloque después de una var anidada",
+			code:    "{ { var h = 1; } let h = 2; }",
+			want:    []string{"redeclared-variable@1:22"},
+			notWant: []string{"redeclared-variable@1:9"},
+		},
+	})
+}
@@ -0,0 +1,599 @@
+package main
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// describeTypeDeclarations añade al informe las interfaces y alias declarados
+func (s *Semantic) describeTypeDeclarations() {
+	for _, sym := range s.typeSymbols {
+		t := s.namedType(sym)
+		switch decl := sym.Decls[0].(type) {
+		case *InterfaceDeclaration:
+			s.addInfo("Interfaz '" + sym.Name + typeParametersString(decl.TypeParams) + "' declarada con " +
+				strconv.Itoa(len(t.Properties)) + " propiedad(es) en línea " + strconv.Itoa(decl.Loc.Start.Line))
+		case *TypeAliasDeclaration:
+			s.addInfo("Alias de tipo '" + sym.Name + typeParametersString(decl.TypeParams) + "' declarado como '" +
+				typeNodeString(decl.Type) + "' en línea " + strconv.Itoa(decl.Loc.Start.Line))
+		}
+	}
+}
+
+// resolveTypeNode traduce una anotación al tipo que describe. El resultado se
+// memoriza por nodo, así que cada tipo desconocido se reporta una sola vez.
+func (s *Semantic) resolveTypeNode(node TypeNode) *Type {
+	if node == nil {
+		return anyType
+	}
+	if t, ok := s.typeNodes[node]; ok {
+		return t
+	}
+	t := s.buildType(node)
+	s.typeNodes[node] = t
+	return t
+}
+
+func (s *Semantic) buildType(node TypeNode) *Type {
+	switch n := node.(type) {
+	case *TypeReference:
+		return s.referencedType(n)
+	case *ArrayType:
+		return arrayOf(s.resolveTypeNode(n.Element))
+	case *UnionType:
+		return unionOf(s.resolveTypeList(n.Types)...)
+	case *IntersectionType:
+		return &Type{Kind: KindIntersection, Types: s.resolveTypeList(n.Types)}
+	case *LiteralType:
+		if t := literalType(n.Literal); t != nil {
+			return t
+		}
+		return anyType
+	case *ObjectType:
+		t := &Type{Kind: KindObject}
+		for _, member := range n.Members {
+			t.setProperty(s.memberType(member))
+		}
+		return t
+	case *FunctionType:
+		typeParams := s.typeParametersOf(n.TypeParams)
+		sig := s.parameterSignature(n.Params)
+		sig.TypeParams = typeParams
+		sig.Return = s.resolveTypeNode(n.ReturnType)
+		return &Type{Kind: KindFunction, Signature: sig}
+	default:
+		return anyType
+	}
+}
+
+func (s *Semantic) resolveTypeList(nodes []TypeNode) []*Type {
+	types := make([]*Type, len(nodes))
+	for i, node := range nodes {
+		types[i] = s.resolveTypeNode(node)
+	}
+	return types
+}
+
+// referencedType resuelve un nombre de tipo: primero los tipos del programa,
+// después 'Array<T>' y los tipos predefinidos.
+func (s *Semantic) referencedType(ref *TypeReference) *Type {
+	args := s.resolveTypeList(ref.TypeArguments)
+	if sym := s.typeTargets[ref]; sym != nil {
+		return s.instantiateReference(ref, sym)
+	}
+	if scope := s.typeScopes[ref]; scope != nil {
+		return s.qualifiedType(ref, scope)
+	}
+	if ref.Name == "Array" {
+		if len(args) == 1 {
+			return arrayOf(args[0])
+		}
+		s.addError(codeUnknownType, ref, "El tipo 'Array' necesita un argumento de tipo: 'Array<T>'")
+		return arrayOf(anyType)
+	}
+	if t, ok := typeFromName(ref.Name); ok {
+		return t
+	}
+	s.addError(codeUnknownType, ref, "Tipo '"+ref.Name+"' desconocido")
+	return anyType
+}
+
+// namedType calcula el tipo de una interfaz o un alias. El tipo se guarda
+// antes de resolver sus miembros para admitir tipos recursivos como
+// 'interface Nodo { siguiente?: Nodo }'. Si es genérico, el tipo declarado
+// lleva sus parámetros de tipo y cada uso lo instancia.
+func (s *Semantic) namedType(sym *TypeSymbol) *Type {
+	if sym.Type != nil {
+		return sym.Type
+	}
+	switch decl := sym.Decls[0].(type) {
+	case *TypeParameter:
+		sym.Type = s.typeParameterType(decl)
+		return sym.Type
+	case *ClassDeclaration:
+		sym.Type = s.classTypesOf(decl).instance
+		return sym.Type
+	case *EnumDeclaration:
+		sym.Type = s.enumTypesOf(decl).enum
+		return sym.Type
+	}
+
+	if alias, ok := sym.Decls[0].(*TypeAliasDeclaration); ok {
+		// Mientras se resuelve, el alias es un tipo provisional que se rellena
+		// al terminar; así 'type Nodo = { siguiente?: Nodo }' se refiere a sí
+		// mismo. Solo un alias circular como 'type A = B; type B = A' se
+		// queda con él, y se comporta como 'any'.
+		placeholder := &Type{Kind: KindAny, Name: sym.Name}
+		sym.Type = placeholder
+		typeParams := s.typeParametersOf(alias.TypeParams)
+		t := s.resolveTypeNode(alias.Type)
+		if t.Name == "" && t.Literal == "" && isStructured(t) {
+			t.Name = sym.Name
+			t.TypeParams = typeParams
+			*placeholder = *t
+			return placeholder
+		}
+		sym.Type = t
+		return t
+	}
+
+	t := &Type{Kind: KindObject, Name: sym.Name}
+	sym.Type = t
+	t.TypeParams = s.symbolTypeParams(sym)
+	// Las declaraciones que se fusionan comparten los parámetros de la primera
+	for _, decl := range sym.Decls[1:] {
+		for i, param := range decl.(*InterfaceDeclaration).TypeParams {
+			if i < len(t.TypeParams) {
+				s.typeParams[param] = t.TypeParams[i]
+			}
+		}
+	}
+	s.startGeneric(t)
+	defer s.completeGeneric(t)
+
+	// Primero lo heredado, para que los miembros propios lo sustituyan
+	for _, decl := range sym.Decls {
+		for _, ref := range decl.(*InterfaceDeclaration).Extends {
+			base := s.resolveTypeNode(ref)
+			if !base.hasProperties() {
+				if !base.isAny() {
+					s.addError(codeTypeMismatch, ref, "Una interfaz solo puede extender tipos objeto, no '"+
+						base.String()+"'")
+				}
+				continue
+			}
+			for _, prop := range propertiesOf(base) {
+				t.setProperty(prop)
+			}
+		}
+	}
+	for _, decl := range sym.Decls {
+		for _, member := range decl.(*InterfaceDeclaration).Members {
+			t.setProperty(s.memberType(member))
+		}
+	}
+	return t
+}
+
+func (s *Semantic) memberType(member *PropertySignature) *PropertyType {
+	return &PropertyType{
+		Name:     member.Name.Name,
+		Type:     s.resolveTypeNode(member.TypeAnnotation),
+		Optional: member.Optional,
+		Readonly: member.Readonly,
+	}
+}
+
+// isStructured indica si el tipo se construyó para una anotación concreta y
+// puede tomar el nombre del alias que la declara.
+func isStructured(t *Type) bool {
+	switch t.Kind {
+	case KindObject, KindArray, KindUnion, KindIntersection, KindFunction:
+		return t != functionType
+	default:
+		return false
+	}
+}
+
+func arrayOf(element *Type) *Type {
+	return &Type{Kind: KindArray, Element: element}
+}
+
+// unionOf construye 'A | B' aplanando uniones anidadas y quitando miembros
+// repetidos; con un solo miembro devuelve ese tipo.
+func unionOf(types ...*Type) *Type {
+	members := make([]*Type, 0, len(types))
+	var add func(t *Type)
+	add = func(t *Type) {
+		if t.Kind == KindUnion && t.Name == "" {
+			for _, member := range t.Types {
+				add(member)
+			}
+			return
+		}
+		for _, existing := range members {
+			if existing == t || (t.Literal != "" && existing.Kind == t.Kind && existing.Literal == t.Literal) {
+				return
+			}
+		}
+		members = append(members, t)
+	}
+	for _, t := range types {
+		if t.isAny() {
+			return anyType
+		}
+		add(t)
+	}
+	if len(members) == 1 {
+		return members[0]
+	}
+	return &Type{Kind: KindUnion, Types: members}
+}
+
+// propertiesOf devuelve los miembros de un objeto o de todas las partes de
+// una intersección.
+func propertiesOf(t *Type) []*PropertyType {
+	if t.Kind != KindIntersection {
+		return t.Properties
+	}
+	var props []*PropertyType
+	for _, member := range t.Types {
+		props = append(props, propertiesOf(member)...)
+	}
+	return props
+}
+
+// literalType devuelve el tipo literal de un valor escrito directamente en el
+// código ('"a"', '42', '-1', 'true') o nil si la expresión no es un literal.
+func literalType(expr Expression) *Type {
+	switch e := expr.(type) {
+	case *StringLiteral:
+		return &Type{Kind: KindString, Literal: "\"" + stringContent(e.Raw) + "\""}
+	case *NumericLiteral:
+		if isBigIntLiteral(e.Raw) {
+			return &Type{Kind: KindBigInt, Literal: e.Raw}
+		}
+		if value, ok := numericValue(e.Raw); ok {
+			return numberLiteral(value)
+		}
+	case *BooleanLiteral:
+		return &Type{Kind: KindBoolean, Literal: strconv.FormatBool(e.Value)}
+	case *UnaryExpression:
+		if e.Operator != "-" {
+			return nil
+		}
+		if t := literalType(e.Argument); t != nil && (t.Kind == KindNumber || t.Kind == KindBigInt) {
+			negated := *t
+			if strings.HasPrefix(t.Literal, "-") {
+				negated.Literal = t.Literal[1:]
+			} else {
+				negated.Literal = "-" + t.Literal
+			}
+			return &negated
+		}
+	}
+	return nil
+}
+
+// numberLiteral es el tipo literal de un número, escrito como lo haría
+// JavaScript: sin exponente para los enteros de menos de 22 cifras.
+func numberLiteral(value float64) *Type {
+	format := byte('g')
+	if value == math.Trunc(value) && math.Abs(value) < 1e21 {
+		format = 'f'
+	}
+	return &Type{Kind: KindNumber, Literal: strconv.FormatFloat(value, format, -1, 64)}
+}
+
+// stringContent quita las comillas de un literal de cadena
+func stringContent(raw string) string {
+	if len(raw) < 2 {
+		return raw
+	}
+	return raw[1 : len(raw)-1]
+}
+
+// propertyName devuelve el nombre de la clave de un objeto literal
+func propertyName(key Expression) string {
+	switch k := key.(type) {
+	case *Identifier:
+		return k.Name
+	case *StringLiteral:
+		return stringContent(k.Raw)
+	case *NumericLiteral:
+		return k.Raw
+	default:
+		return ""
+	}
+}
+
+// valueAssignable comprueba si el valor puede guardarse en el tipo destino.
+// A diferencia de isAssignable conoce la expresión: un literal encaja en un
+// tipo literal con el mismo valor y un objeto literal no puede traer
+// propiedades que el destino no declara.
+func (s *Semantic) valueAssignable(value Expression, target *Type) (bool, string) {
+	source := s.typeOf(value)
+	if source.isAny() || target.isAny() {
+		return true, ""
+	}
+	if lit := literalType(value); lit != nil {
+		return assignable(lit, target)
+	}
+
+	switch v := value.(type) {
+	case *ArrayLiteral:
+		if target.Kind == KindUnion {
+			for _, member := range target.Types {
+				if ok, _ := s.valueAssignable(v, member); ok {
+					return true, ""
+				}
+			}
+			return false, ""
+		}
+		if target.Kind == KindArray {
+			return s.arrayLiteralAssignable(v, target)
+		}
+	case *ObjectLiteral:
+		if target.Kind == KindUnion {
+			for _, member := range target.Types {
+				if ok, _ := s.valueAssignable(v, member); ok {
+					return true, ""
+				}
+			}
+			return false, ""
+		}
+		if target.hasProperties() {
+			return s.objectLiteralAssignable(v, target)
+		}
+	case *ConditionalExpression:
+		if ok, reason := s.valueAssignable(v.Consequent, target); !ok {
+			return false, reason
+		}
+		return s.valueAssignable(v.Alternate, target)
+	}
+	return assignable(source, target)
+}
+
+func (s *Semantic) objectLiteralAssignable(obj *ObjectLiteral, target *Type) (bool, string) {
+	present := make(map[string]bool, len(obj.Properties))
+	for _, member := range obj.Properties {
+		prop, ok := member.(*Property)
+		if !ok {
+			// Lo que aporta '...otro' se compara como un objeto cualquiera
+			spread := member.(*SpreadElement)
+			for _, copied := range copiedProperties(s.typeOf(spread)) {
+				present[copied.Name] = true
+				expected := target.property(copied.Name)
+				if expected == nil {
+					continue
+				}
+				if !isAssignable(copied.Type, expected.Type) {
+					return false, "la propiedad '" + copied.Name + "' que aporta '" + exprString(spread) +
+						"' es de tipo '" + copied.Type.String() + "' y se esperaba '" + expected.Type.String() + "'"
+				}
+			}
+			continue
+		}
+		name, known := memberKey(prop.Key, prop.Computed)
+		if !known {
+			continue
+		}
+		present[name] = true
+		expected := target.property(name)
+		if expected == nil {
+			return false, "la propiedad '" + name + "' no existe en el tipo '" + target.String() + "'"
+		}
+		if ok, reason := s.valueAssignable(prop.Value, expected.Type); !ok {
+			if reason != "" {
+				return false, "en la propiedad '" + name + "', " + reason
+			}
+			return false, "la propiedad '" + name + "' es de tipo '" + s.typeOf(prop.Value).String() +
+				"' y se esperaba '" + expected.Type.String() + "'"
+		}
+	}
+	for _, expected := range propertiesOf(target) {
+		if !expected.Optional && !present[expected.Name] {
+			return false, "falta la propiedad '" + expected.Name + "'"
+		}
+	}
+	return true, ""
+}
+
+// arrayLiteralAssignable compara cada elemento del array literal con el tipo
+// de elemento del destino, para que '[1, "a"]' no encaje en 'number[]' y los
+// objetos literales de dentro no traigan propiedades de más.
+func (s *Semantic) arrayLiteralAssignable(array *ArrayLiteral, target *Type) (bool, string) {
+	for i, element := range array.Elements {
+		switch e := element.(type) {
+		case nil:
+			continue
+		case *SpreadElement:
+			t, _ := iteratedType(s.typeOf(e))
+			if ok, _ := assignable(t, target.Element); !ok {
+				return false, "los elementos de tipo '" + t.String() + "' que aporta '" + exprString(e) +
+					"' no se pueden asignar a '" + target.Element.String() + "'"
+			}
+		default:
+			if ok, reason := s.valueAssignable(e, target.Element); !ok {
+				message := "el elemento " + strconv.Itoa(i) + " es de tipo '" + s.displayType(e).String() +
+					"' y se esperaba '" + target.Element.String() + "'"
+				return false, mismatchMessage(message, reason)
+			}
+		}
+	}
+	return true, ""
+}
+
+// arrayLiteralType infiere el tipo de un array literal: un array de la unión
+// de los tipos de sus elementos, o 'any[]' si está vacío.
+func (s *Semantic) arrayLiteralType(array *ArrayLiteral) *Type {
+	elements := make([]*Type, 0, len(array.Elements))
+	seen := make(map[string]bool, len(array.Elements))
+	for _, element := range array.Elements {
+		t := undefinedType
+		switch e := element.(type) {
+		case nil:
+		case *SpreadElement:
+			t = s.spreadElementType(e)
+		default:
+			t = s.typeOf(e)
+		}
+		// Dos objetos literales con la misma forma cuentan una sola vez
+		if !seen[t.String()] {
+			seen[t.String()] = true
+			elements = append(elements, t)
+		}
+	}
+	if len(elements) == 0 {
+		return arrayOf(anyType)
+	}
+	return arrayOf(unionOf(elements...))
+}
+
+// objectLiteralType infiere el tipo de un objeto literal. '...otro' copia las
+// propiedades de otro objeto y una clave calculada que no es un literal no
+// aporta ninguna con nombre conocido.
+func (s *Semantic) objectLiteralType(obj *ObjectLiteral) *Type {
+	t := &Type{Kind: KindObject}
+	for _, member := range obj.Properties {
+		switch m := member.(type) {
+		case *SpreadElement:
+			for _, prop := range s.spreadProperties(m) {
+				t.setProperty(&PropertyType{Name: prop.Name, Type: prop.Type, Optional: prop.Optional})
+			}
+		case *Property:
+			if m.Computed {
+				s.typeOf(m.Key)
+			}
+			value := s.typeOf(m.Value)
+			if name, known := memberKey(m.Key, m.Computed); known {
+				t.setProperty(&PropertyType{Name: name, Type: value})
+			}
+		}
+	}
+	return t
+}
+
+// spreadElementType devuelve el tipo de los elementos que aporta '...valor' en
+// un array o en los argumentos de una llamada, y reporta los valores que no
+// se pueden recorrer.
+func (s *Semantic) spreadElementType(spread *SpreadElement) *Type {
+	t := s.typeOf(spread)
+	element, ok := iteratedType(t)
+	if !ok {
+		s.addError(codeTypeMismatch, spread, "El operador '...' necesita un array, no un valor de tipo '"+
+			t.String()+"'")
+	}
+	return element
+}
+
+// spreadProperties devuelve las propiedades que '...valor' copia en un objeto
+// literal y reporta los valores que no son objetos. null y undefined se
+// admiten pero no aportan nada.
+func (s *Semantic) spreadProperties(spread *SpreadElement) []*PropertyType {
+	t := apparentType(s.typeOf(spread))
+	switch {
+	case t.hasProperties():
+		return propertiesOf(t)
+	case !t.isAny() && t.Kind != KindNull && t.Kind != KindUndefined && t.Kind != KindArray:
+		s.addError(codeTypeMismatch, spread, "El operador '...' en un objeto necesita un objeto, no un valor de tipo '"+
+			t.String()+"'")
+	}
+	return nil
+}
+
+// copiedProperties devuelve las propiedades que '...valor' copia de un valor
+// del tipo t, sin reportar nada.
+func copiedProperties(t *Type) []*PropertyType {
+	if t = apparentType(t); t.hasProperties() {
+		return propertiesOf(t)
+	}
+	return nil
+}
+
+// spreadIndex devuelve la posición del primer '...valor' de la lista o -1
+func spreadIndex(args []Expression) int {
+	for i, arg := range args {
+		if _, ok := arg.(*SpreadElement); ok {
+			return i
+		}
+	}
+	return -1
+}
+
+// displayType es el tipo con el que se muestra un valor en los mensajes: el
+// literal si el valor está escrito en el código ('"rojo"' y no 'string').
+func (s *Semantic) displayType(value Expression) *Type {
+	if lit := literalType(value); lit != nil {
+		return lit
+	}
+	return s.typeOf(value)
+}
+
+// mismatchMessage completa un mensaje de tipos incompatibles con el motivo
+// concreto, si lo hay.
+func mismatchMessage(message, reason string) string {
+	if reason == "" {
+		return message
+	}
+	return message + ": " + reason
+}
+
+// propertyType devuelve el tipo de 'objeto.propiedad' y reporta las
+// propiedades que el tipo del objeto no declara. En una unión la propiedad
+// debe existir en todos sus miembros (salvo null y undefined).
+func (s *Semantic) propertyType(object *Type, member *MemberExpression) *Type {
+	name := member.Property.Name
+	switch {
+	case object.isAny():
+		return anyType
+	case object.Kind == KindTypeParam:
+		if object.Constraint != nil {
+			return s.propertyType(object.Constraint, member)
+		}
+		s.addError(codeUnknownProperty, member.Property, "La propiedad '"+name+"' no existe en '"+
+			exprString(member.Object)+"' de tipo '"+object.String()+"', que no tiene restricción 'extends'")
+		return anyType
+	case object.hasProperties():
+		if prop := object.property(name); prop != nil {
+			s.checkAccess(prop, member)
+			return prop.Type
+		}
+		if s.reportMissingMember(object, member) {
+			return anyType
+		}
+		s.addError(codeUnknownProperty, member.Property, "La propiedad '"+name+"' no existe en '"+
+			exprString(member.Object)+"' de tipo '"+object.String()+"'")
+		return anyType
+	case object.Kind == KindUnion:
+		types := make([]*Type, 0, len(object.Types))
+		for _, t := range object.Types {
+			if t.Kind == KindNull || t.Kind == KindUndefined {
+				continue
+			}
+			if !t.hasProperties() {
+				return anyType
+			}
+			prop := t.property(name)
+			if prop == nil {
+				s.addError(codeUnknownProperty, member.Property, "La propiedad '"+name+"' no existe en el tipo '"+
+					t.String()+"' de la unión '"+object.String()+"'")
+				return anyType
+			}
+			types = append(types, prop.Type)
+		}
+		if len(types) == 0 {
+			return anyType
+		}
+		return unionOf(types...)
+	case apparentMembers(object) != nil:
+		// De los métodos de strings y arrays solo se conoce 'length'
+		if prop := apparentMembers(object).property(name); prop != nil {
+			return prop.Type
+		}
+		return anyType
+	default:
+		return anyType
+	}
+}
@@ -0,0 +1,199 @@
+package main
+
+import "testing"
+
+func TestObjectTypes(t *testing.T) {
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name:    "propiedad opcional ausente",
+			code:    "interface P { x: number; y?: string } let p: P = { x: 1 };",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "falta una propiedad obligatoria",
+			code: "interface P { x: number } let p: P = { y: 1 };",
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "propiedad de más en un objeto literal",
+			code: "interface P { x: number } let p: P = { x: 1, z: 2 };",
+			want: []string{"type-mismatch@1:38"},
+		},
+		{
+			name: "propiedad de otro tipo",
+			code: `interface P { x: number } let p: P = { x: "a" };`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "propiedad que no existe",
+			code: "interface P { x: number } function f(p: P) { return p.z; }",
+			want: []string{"unknown-property@1:55"},
+		},
+		{
+			name: "propiedad heredada que falta",
+			code: `interface B { a: number } interface D extends B { b: string } let d: D = { b: "x" };`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "interfaz que extiende otra",
+			code:    `interface B { a: number } interface D extends B { b: string } let d: D = { a: 1, b: "x" };`,
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "unión de literales",
+			code: `type Color = "rojo" | "verde"; let c: Color = "azul";`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "miembro de la unión de literales",
+			code:    `type Color = "rojo" | "verde"; let c: Color = "rojo";`,
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name:    "unión con null",
+			code:    "let u: number | null = null;",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "intersección incompleta",
+			code: "type A = { a: number } & { b: string }; let v: A = { a: 1 };",
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "elemento de array de otro tipo",
+			code: `let xs: number[] = [1, "a"];`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "Array<T>",
+			code:    `let ys: Array<string> = ["a"];`,
+			notWant: []string{"type-mismatch", "unknown-type"},
+		},
+		{
+			name: "tipo desconocido",
+			code: "let q: Desconocido = 1;",
+			want: []string{"unknown-type@1:8"},
+		},
+	})
+}
+
+// Comparar dos tipos recursivos distintos no debe recorrerlos sin fin: un
+// desbordamiento de pila no se puede recuperar y tumbaría el servidor.
+func TestRecursiveTypeComparison(t *testing.T) {
+	const interfaces = "interface A { n: A } interface B { n: B } "
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name:    "asignación",
+			code:    interfaces + "function g(a: A) { let b: B = a; return b; }",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name:    "comparación",
+			code:    interfaces + "function g(a: A, b: B) { return a == b; }",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name:    "argumento",
+			code:    interfaces + "function f(x: B) {} function g(a: A) { f(a); }",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name:    "clases",
+			code:    "class A { n: A } class B { n: B } let b: B = new A();",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "propiedad incompatible",
+			code: "interface A { n: A; v: number } interface B { n: B; v: string } " +
+				"function g(a: A) { let b: B = a; return b; }",
+			want: []string{"type-mismatch"},
+		},
+	})
+}
+
+// Un string o un array se comparan con un tipo objeto por los miembros que
+// el analizador les conoce; un número, por ninguno.
+func TestPrimitivesAssignableToObjectTypes(t *testing.T) {
+	runDiagnosticCases(t, []diagnosticCase{
+		{name: "string con length", code: `let x: { length: number } = "abc";`, notWant: []string{"type-mismatch"}},
+		{name: "array con length", code: "let y: { length: number } = [1];", notWant: []string{"type-mismatch"}},
+		{name: "número en objeto vacío", code: "let o: {} = 5;", notWant: []string{"type-mismatch"}},
+		{name: "número sin length", code: "let z: { length: number } = 5;", want: []string{"type-mismatch"}},
+		{name: "null en objeto vacío", code: "let n: {} = null;", want: []string{"type-mismatch"}},
+		{name: "length de otro tipo", code: `let s: { length: string } = "abc";`, want: []string{"type-mismatch"}},
+	})
+}
+
+// Un alias que se nombra a sí mismo se comprueba igual que una interfaz
+// recursiva, sin convertir la referencia interna en 'any'.
+func TestRecursiveTypeAlias(t *testing.T) {
+	const nodo = "type Nodo = { v: number; next?: Nodo }; "
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name: "objeto literal anidado",
+			code: nodo + `let a: Nodo = { v: 1, next: { v: "x" } };`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "objeto literal anidado correcto",
+			code:    nodo + "let a: Nodo = { v: 1, next: { v: 2, next: { v: 3 } } };",
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "propiedad de la referencia interna",
+			code: nodo + "function f(b: Nodo) { let d: string = b.next.v; return d; }",
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "alias genérico",
+			code: `type Lista<T> = { v: T; next?: Lista<T> }; let l: Lista<number> = { v: 1, next: { v: "x" } };`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name: "unión recursiva",
+			code: `type Arbol = number | Arbol[]; let t: Arbol = [1, [2, ["x"]]];`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "alias circular",
+			code:    "type A = B; type B = A; let x: A = 1;",
+			notWant: []string{"type-mismatch"},
+		},
+	})
+}
+
+// Las funciones y los métodos se comparan por sus parámetros y su retorno
+func TestFunctionTypeAssignability(t *testing.T) {
+	runDiagnosticCases(t, []diagnosticCase{
+		{
+			name: "método de interfaz con otra firma",
+			code: "interface I { run(x: number): string } let i: I = { run: (x: string): number => 1 };",
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "método de interfaz con la misma firma",
+			code:    `interface I { run(x: number): string } let i: I = { run: (x: number) => "a" };`,
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "método de clase con otro retorno",
+			code: `class C { m(): string { return ""; } } let c: C = { m: () => 1 };`,
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "menos parámetros de los que se pasan",
+			code:    `let g: (x: number) => string = () => "a";`,
+			notWant: []string{"type-mismatch"},
+		},
+		{
+			name: "más parámetros de los que se pasan",
+			code: "let k: () => void = (x: number) => 5;",
+			want: []string{"type-mismatch"},
+		},
+		{
+			name:    "retorno void",
+			code:    "let h: (x: number) => void = (x: number) => 5;",
+			notWant: []string{"type-mismatch"},
+		},
+	})
+}
@@ -0,0 +1,700 @@
+package main
+
+import "strconv"
+
+// checkTypes infiere el tipo de cada expresión y comprueba las anotaciones
+// contra los valores iniciales, las asignaciones y los operandos de cada
+// operador. Los tipos desconocidos se tratan como 'any' para no encadenar
+// errores a partir de uno solo.
+func (s *Semantic) checkTypes() {
+	s.describeTypeDeclarations()
+	s.checkStatementTypes(s.program)
+
+	for _, info := range s.symbols {
+		if s.declaredType(info) != nil {
+			continue
+		}
+		if t := s.symbolType(info); !t.isAny() {
+			s.addInfo("Tipo inferido para '" + info.Name + "': '" + t.String() + "'")
+		}
+	}
+}
+
+func (s *Semantic) checkStatementTypes(root Node) {
+	Inspect(root, func(n Node) bool {
+		switch n := n.(type) {
+		case *FunctionDeclaration:
+			s.checkFunction(n)
+			return false
+		case *ClassDeclaration:
+			s.checkClass(n)
+			return false
+		case *EnumDeclaration:
+			if n.Name != nil {
+				s.enumTypesOf(n)
+			}
+			return false
+		case *VariableDeclaration:
+			for _, declarator := range n.Declarations {
+				s.checkDeclarator(n, declarator)
+			}
+			return false
+		case *ExpressionStatement:
+			s.typeOf(n.Expression)
+			return false
+		case *ForStatement:
+			if init, ok := n.Init.(Expression); ok {
+				s.typeOf(init)
+			}
+			s.typeOf(n.Test)
+			s.typeOf(n.Update)
+		case *ForOfStatement:
+			s.checkForOf(n)
+		case *ForInStatement:
+			s.checkForIn(n)
+		case *DoWhileStatement:
+			s.typeOf(n.Test)
+		case *WhileStatement:
+			s.typeOf(n.Test)
+		case *IfStatement:
+			s.typeOf(n.Test)
+		case *SwitchStatement:
+			s.checkSwitchCases(n)
+		case *ReturnStatement:
+			s.checkReturnValue(n.Argument)
+		}
+		return true
+	})
+}
+
+// checkFunction comprueba los valores por defecto de los parámetros y cada
+// valor devuelto contra el tipo de retorno anotado, y devuelve el tipo de la
+// función.
+func (s *Semantic) checkFunction(fn Node) *Type {
+	defer s.enterFunction(fn)()
+	_, params, returnRef, body := functionParts(fn)
+	for _, param := range params {
+		declared := s.parameterType(param)
+		if param.Rest && !declared.isAny() && declared.Kind != KindArray {
+			s.addError(codeTypeMismatch, param.TypeAnnotation, "El parámetro rest '..."+param.displayName()+
+				"' debe ser un array, no '"+declared.String()+"'")
+		}
+		s.destructure(param, keywordParam)
+		if param.Default == nil {
+			continue
+		}
+		if declared == nil {
+			continue
+		}
+		if ok, reason := s.valueAssignable(param.Default, declared); !ok {
+			s.addError(codeTypeMismatch, param.Default, mismatchMessage("El valor por defecto de tipo '"+
+				s.displayType(param.Default).String()+"' no se puede asignar al parámetro '"+param.displayName()+"' de tipo '"+
+				declared.String()+"'", reason))
+		}
+	}
+
+	var returnType *Type
+	if returnRef != nil {
+		returnType = s.resolveTypeNode(returnRef)
+	}
+	s.returnTypes = append(s.returnTypes, returnType)
+	defer func() { s.returnTypes = s.returnTypes[:len(s.returnTypes)-1] }()
+
+	switch b := body.(type) {
+	case *BlockStatement:
+		s.checkStatementTypes(b)
+	case Expression:
+		s.checkReturnValue(b)
+	}
+	return s.functionTypeOf(fn)
+}
+
+// functionTypeOf construye la firma de una función a partir de sus parámetros
+// y de su tipo de retorno, anotado o inferido de sus 'return'.
+func (s *Semantic) functionTypeOf(fn Node) *Type {
+	if t, ok := s.signatures[fn]; ok {
+		return t
+	}
+	// Marca provisional, sin firma, para las llamadas recursivas
+	s.signatures[fn] = functionType
+	defer s.enterFunction(fn)()
+
+	_, params, returnRef, body := functionParts(fn)
+	typeParams := s.typeParametersOf(functionTypeParams(fn))
+	sig := s.parameterSignature(params)
+	sig.TypeParams = typeParams
+	switch {
+	case returnRef != nil:
+		sig.Return = s.resolveTypeNode(returnRef)
+	case body == nil:
+		sig.Return = anyType
+	default:
+		sig.Return = s.inferReturnType(body)
+	}
+
+	t := &Type{Kind: KindFunction, Signature: sig}
+	s.signatures[fn] = t
+	return t
+}
+
+// parameterSignature construye la parte de la firma que corresponde a los
+// parámetros; el parámetro rest aporta el tipo de sus elementos.
+func (s *Semantic) parameterSignature(params []*Parameter) *Signature {
+	sig := &Signature{Params: make([]SignatureParam, 0, len(params))}
+	for _, p := range params {
+		t := s.inferredParameterType(p)
+		if p.Rest {
+			element := anyType
+			if t.Kind == KindArray {
+				element = t.Element
+			}
+			rest := param(p.displayName(), element)
+			sig.Rest = &rest
+			continue
+		}
+		sig.Params = append(sig.Params, param(p.displayName(), t))
+		if !p.Optional && p.Default == nil {
+			sig.Required = len(sig.Params)
+		}
+	}
+	return sig
+}
+
+// inferReturnType deduce el tipo que devuelve una función sin anotación:
+// 'void' si ningún 'return' lleva valor, el tipo común si todos coinciden y
+// 'any' en otro caso.
+func (s *Semantic) inferReturnType(body Node) *Type {
+	if expr, ok := body.(Expression); ok {
+		return s.typeOf(expr)
+	}
+
+	var result *Type
+	Inspect(body, func(n Node) bool {
+		if isFunctionNode(n) {
+			return false
+		}
+		ret, ok := n.(*ReturnStatement)
+		if !ok {
+			return true
+		}
+		t := voidType
+		if ret.Argument != nil {
+			t = s.typeOf(ret.Argument)
+		}
+		if result == nil {
+			result = t
+		} else if result != t {
+			result = anyType
+		}
+		return true
+	})
+	if result == nil {
+		return voidType
+	}
+	return result
+}
+
+// callType comprueba una llamada contra la firma de la función: que el valor
+// se pueda llamar, el número de argumentos y el tipo de cada uno. Una función
+// genérica se instancia antes con los argumentos de tipo de la llamada.
+func (s *Semantic) callType(call *CallExpression) *Type {
+	callee := apparentType(s.typeOf(call.Callee))
+	for _, arg := range call.Arguments {
+		s.typeOf(arg)
+	}
+
+	// 'super(...)' llama al constructor de la clase base
+	if _, ok := call.Callee.(*SuperExpression); ok {
+		if ct := s.currentDerived(); ct != nil {
+			s.checkArguments(call, "super", ct.baseConstruct, call.Arguments)
+		}
+		return voidType
+	}
+
+	name := exprString(call.Callee)
+	if !callee.isCallable() {
+		message := "'" + name + "' es de tipo '" + callee.String() + "' y no se puede llamar"
+		if callee.Construct != nil {
+			message += "; para crear una instancia usa 'new " + name + "(...)'"
+		}
+		s.addError(codeNotCallable, call.Callee, message)
+		return anyType
+	}
+	sig := callee.Signature
+	if sig == nil {
+		return anyType
+	}
+
+	sig = s.instantiateSignature(call, name, sig, call.TypeArguments, call.Arguments)
+	s.checkArguments(call, name, sig, call.Arguments)
+	if sig.Return == nil {
+		return anyType
+	}
+	return sig.Return
+}
+
+// checkArguments comprueba el número de argumentos de una llamada (o de un
+// 'new') y el tipo de cada uno contra la firma.
+func (s *Semantic) checkArguments(call Node, name string, sig *Signature, args []Expression) {
+	spread := spreadIndex(args)
+	if spread >= 0 {
+		// Después de '...valor' ya no se sabe qué parámetro recibe cada argumento
+		s.spreadElementType(args[spread].(*SpreadElement))
+		args = args[:spread]
+	} else if len(args) < sig.Required || (sig.Rest == nil && len(args) > len(sig.Params)) {
+		s.addError(codeArgumentCount, call, "'"+name+"' espera "+expectedArguments(sig)+
+			" pero recibe "+strconv.Itoa(len(args)))
+	}
+	for i, arg := range args {
+		expected := sig.Rest
+		if i < len(sig.Params) {
+			expected = &sig.Params[i]
+		}
+		if expected == nil {
+			break
+		}
+		if ok, reason := s.valueAssignable(arg, expected.Type); !ok {
+			s.addError(codeTypeMismatch, arg, mismatchMessage("El argumento de tipo '"+s.displayType(arg).String()+
+				"' no se puede asignar al parámetro '"+expected.Name+"' de tipo '"+expected.Type.String()+"'", reason))
+		}
+	}
+}
+
+// expectedArguments describe cuántos argumentos admite una firma
+func expectedArguments(sig *Signature) string {
+	switch {
+	case sig.Rest != nil:
+		return "al menos " + strconv.Itoa(sig.Required) + " argumento(s)"
+	case sig.Required == len(sig.Params):
+		return strconv.Itoa(sig.Required) + " argumento(s)"
+	default:
+		return "entre " + strconv.Itoa(sig.Required) + " y " + strconv.Itoa(len(sig.Params)) + " argumentos"
+	}
+}
+
+// checkReturnValue compara el valor devuelto con el tipo de retorno anotado
+// de la función en curso.
+func (s *Semantic) checkReturnValue(value Expression) {
+	s.typeOf(value)
+	if value == nil || len(s.returnTypes) == 0 {
+		return
+	}
+	expected := s.returnTypes[len(s.returnTypes)-1]
+	if expected == nil {
+		return
+	}
+	if ok, reason := s.valueAssignable(value, expected); !ok {
+		s.addError(codeTypeMismatch, value, mismatchMessage("No se puede devolver un valor de tipo '"+
+			s.displayType(value).String()+"' en una función que devuelve '"+expected.String()+"'", reason))
+	}
+}
+
+func (s *Semantic) checkDeclarator(decl *VariableDeclaration, declarator *VariableDeclarator) {
+	s.typeOf(declarator.Init)
+	declared := s.annotationType(decl.Keyword, declarator)
+	if declared != nil && declarator.Init != nil {
+		if ok, reason := s.valueAssignable(declarator.Init, declared); !ok {
+			s.addError(codeTypeMismatch, declarator.Init, mismatchMessage("No se puede asignar un valor de tipo '"+
+				s.displayType(declarator.Init).String()+"' a '"+declarator.displayName()+"' de tipo '"+
+				declared.String()+"'", reason))
+		}
+	}
+	s.destructure(declarator, decl.Keyword)
+}
+
+// initializerType devuelve el tipo del valor que recibe un declarador: el de
+// su valor inicial o, en la cabecera de un 'for...of' o 'for...in', el de cada
+// elemento que se recorre.
+func (s *Semantic) initializerType(declarator *VariableDeclarator) *Type {
+	switch loop := s.forEachHeads[declarator].(type) {
+	case *ForOfStatement:
+		element, _ := iteratedType(s.typeOf(loop.Right))
+		return element
+	case *ForInStatement:
+		return stringType
+	}
+	return s.typeOf(declarator.Init)
+}
+
+// checkForOf comprueba que el valor recorrido por un 'for...of' sea iterable
+// y, si la variable ya estaba declarada, que admita cada elemento.
+func (s *Semantic) checkForOf(loop *ForOfStatement) {
+	source := s.typeOf(loop.Right)
+	element, ok := iteratedType(source)
+	if !ok {
+		s.addError(codeTypeMismatch, loop.Right, "El bucle 'for...of' necesita un array o un string, no un valor de tipo '"+
+			source.String()+"'")
+	}
+	if target, ok := loop.Left.(Expression); ok {
+		s.checkForEachTarget(target, element)
+	}
+}
+
+// checkForIn comprueba que un 'for...in' recorra un objeto: los valores
+// primitivos no tienen propiedades que enumerar.
+func (s *Semantic) checkForIn(loop *ForInStatement) {
+	source := s.typeOf(loop.Right)
+	switch widen(source) {
+	case numberType, stringType, booleanType, bigintType, nullType, undefinedType:
+		s.addError(codeTypeMismatch, loop.Right, "El lado derecho de un 'for...in' debe ser un objeto, no un valor de tipo '"+
+			source.String()+"'")
+	}
+	if target, ok := loop.Left.(Expression); ok {
+		s.checkForEachTarget(target, stringType)
+	}
+}
+
+// checkForEachTarget comprueba la variable ya declarada que usa como destino
+// un 'for...of' o 'for...in', que recibe en cada vuelta un valor de tipo element.
+func (s *Semantic) checkForEachTarget(target Expression, element *Type) {
+	declared := s.typeOf(target)
+	s.checkWritable(target, target)
+	if ok, reason := assignable(element, declared); !ok {
+		s.addError(codeTypeMismatch, target, mismatchMessage("No se puede asignar un elemento de tipo '"+
+			element.String()+"' a '"+exprString(target)+"' de tipo '"+declared.String()+"'", reason))
+	}
+}
+
+// checkSwitchCases reporta los 'case' cuyo tipo nunca puede coincidir con el
+// valor del switch, que se compara con '==='.
+func (s *Semantic) checkSwitchCases(stmt *SwitchStatement) {
+	discriminant := s.typeOf(stmt.Discriminant)
+	for _, c := range stmt.Cases {
+		if c.Test == nil {
+			continue
+		}
+		caseType := s.typeOf(c.Test)
+		if lit := literalType(c.Test); lit != nil {
+			caseType = lit
+		}
+		if comparable(caseType, discriminant) {
+			continue
+		}
+		s.addError(codeTypeMismatch, c.Test, "El caso de tipo '"+caseType.String()+
+			"' nunca coincide con el valor del switch de tipo '"+discriminant.String()+"'")
+	}
+}
+
+// annotationType devuelve el tipo declarado explícitamente, ya sea con ': tipo'
+// o con un tipo estilo C ('int x = 5'); nil si la variable no tiene tipo.
+func (s *Semantic) annotationType(keyword string, declarator *VariableDeclarator) *Type {
+	if declarator.TypeAnnotation != nil {
+		return s.resolveTypeNode(declarator.TypeAnnotation)
+	}
+	if t, ok := typeFromName(keyword); ok {
+		return t
+	}
+	return nil
+}
+
+// parameterType devuelve el tipo anotado del parámetro o nil. Un parámetro
+// rest recoge los argumentos sobrantes, así que su tipo debe ser un array.
+func (s *Semantic) parameterType(param *Parameter) *Type {
+	if param.TypeAnnotation == nil {
+		return nil
+	}
+	return s.resolveTypeNode(param.TypeAnnotation)
+}
+
+// inferredParameterType devuelve el tipo anotado del parámetro o, si no tiene,
+// el de su valor por defecto.
+func (s *Semantic) inferredParameterType(param *Parameter) *Type {
+	if t := s.parameterType(param); t != nil {
+		return t
+	}
+	if param.Rest {
+		return arrayOf(anyType)
+	}
+	if param.Default != nil {
+		return s.typeOf(param.Default)
+	}
+	return anyType
+}
+
+// declaredType devuelve el tipo explícito del símbolo (anotación, tipo estilo C
+// o función) o nil si hay que inferirlo de su valor.
+func (s *Semantic) declaredType(info *VariableInfo) *Type {
+	switch node := info.Node.(type) {
+	case *VariableDeclarator:
+		if node.Pattern != nil {
+			// Una variable del patrón tiene tipo declarado si lo tiene el patrón
+			if s.annotationType(info.Keyword, node) == nil {
+				return nil
+			}
+			return s.destructuredType(info)
+		}
+		return s.annotationType(info.Keyword, node)
+	case *Parameter:
+		if node.Pattern != nil {
+			if node.TypeAnnotation == nil {
+				return nil
+			}
+			return s.destructuredType(info)
+		}
+		return s.parameterType(node)
+	case *FunctionDeclaration, *FunctionExpression:
+		return s.functionTypeOf(node)
+	case *ClassDeclaration:
+		return s.classTypesOf(node).constructor
+	case *EnumDeclaration:
+		return s.enumTypesOf(node).object
+	case *EnumMember:
+		return s.enumTypesOf(info.Scope.Node.(*EnumDeclaration)).members[node]
+	case *ModuleDeclaration:
+		return s.namespaceType(node)
+	default:
+		return nil
+	}
+}
+
+// symbolType devuelve el tipo de una variable: el declarado o, si no tiene, el
+// de su valor inicial (o valor por defecto, en un parámetro).
+func (s *Semantic) symbolType(info *VariableInfo) *Type {
+	if t, ok := s.symbolTypes[info]; ok {
+		return t
+	}
+	// Marca provisional para cortar ciclos como 'let x = x + 1'
+	s.symbolTypes[info] = anyType
+
+	t := s.declaredType(info)
+	if t == nil {
+		t = anyType
+		switch node := info.Node.(type) {
+		case *VariableDeclarator:
+			if node.Pattern != nil {
+				t = s.destructuredType(info)
+			} else {
+				t = s.initializerType(node)
+			}
+		case *Parameter:
+			if node.Pattern != nil {
+				t = s.destructuredType(info)
+			} else {
+				t = s.inferredParameterType(node)
+			}
+		}
+	}
+	s.symbolTypes[info] = t
+	return t
+}
+
+// typeOf infiere el tipo de una expresión. El resultado se memoriza, así que
+// cada error de tipos se reporta una sola vez aunque se consulte varias veces.
+func (s *Semantic) typeOf(expr Expression) *Type {
+	if expr == nil {
+		return anyType
+	}
+	if t, ok := s.types[expr]; ok {
+		return t
+	}
+	t := s.inferExpressionType(expr)
+	s.types[expr] = t
+	return t
+}
+
+func (s *Semantic) inferExpressionType(expr Expression) *Type {
+	switch e := expr.(type) {
+	case *NumericLiteral:
+		if isBigIntLiteral(e.Raw) {
+			return bigintType
+		}
+		return numberType
+	case *StringLiteral:
+		return stringType
+	case *BooleanLiteral:
+		return booleanType
+	case *FunctionExpression:
+		return s.checkFunction(e)
+	case *ArrowFunction:
+		return s.checkFunction(e)
+	case *TemplateLiteral:
+		for _, part := range e.Expressions {
+			s.typeOf(part)
+		}
+		return stringType
+	case *Identifier:
+		if info := s.references[e]; info != nil {
+			return s.symbolType(info)
+		}
+		if t := builtinGlobals[e.Name]; t != nil {
+			return t
+		}
+		return anyType
+	case *UnaryExpression:
+		argument := widen(s.typeOf(e.Argument))
+		if e.Operator == "!" {
+			return booleanType
+		}
+		if e.Operator == "-" || e.Operator == "~" {
+			if argument.isNumeric() {
+				return argument
+			}
+			if !argument.isAny() {
+				s.addError(codeInvalidOperands, e, "El operador '"+e.Operator+"' no se puede aplicar a un valor de tipo '"+
+					argument.String()+"'")
+			}
+		}
+		return numberType
+	case *UpdateExpression:
+		argument := widen(s.typeOf(e.Argument))
+		s.checkWritable(e.Argument, e)
+		if argument.isNumeric() {
+			return argument
+		}
+		if !argument.isAny() {
+			s.addError(codeInvalidOperands, e, "El operador '"+e.Operator+
+				"' solo se puede aplicar a números, no a '"+argument.String()+"'")
+		}
+		return numberType
+	case *BinaryExpression:
+		return s.binaryType(e, e.Operator, s.typeOf(e.Left), s.typeOf(e.Right))
+	case *LogicalExpression:
+		left, right := s.typeOf(e.Left), s.typeOf(e.Right)
+		if left == right {
+			return left
+		}
+		return anyType
+	case *ConditionalExpression:
+		s.typeOf(e.Test)
+		return unionOf(s.typeOf(e.Consequent), s.typeOf(e.Alternate))
+	case *AssignmentExpression:
+		return s.assignmentType(e)
+	case *CallExpression:
+		return s.callType(e)
+	case *NewExpression:
+		return s.newType(e)
+	case *ThisExpression:
+		return s.thisType()
+	case *SuperExpression:
+		return s.superType(e)
+	case *MemberExpression:
+		object := s.typeOf(e.Object)
+		if e.Property == nil {
+			return anyType
+		}
+		return s.propertyType(object, e)
+	case *IndexExpression:
+		object := s.typeOf(e.Object)
+		s.typeOf(e.Index)
+		switch {
+		case object.Kind == KindArray:
+			return object.Element
+		case widen(object) == stringType:
+			return stringType
+		case s.enumOf(object) != nil && widen(s.typeOf(e.Index)) == numberType:
+			// Acceso inverso: 'Color[0]' es el nombre del miembro
+			return stringType
+		}
+		if key, ok := e.Index.(*StringLiteral); ok && object.hasProperties() {
+			if prop := object.property(stringContent(key.Raw)); prop != nil {
+				return prop.Type
+			}
+		}
+		return anyType
+	case *ArrayLiteral:
+		return s.arrayLiteralType(e)
+	case *SpreadElement:
+		return s.typeOf(e.Argument)
+	case *ObjectLiteral:
+		return s.objectLiteralType(e)
+	default:
+		return anyType
+	}
+}
+
+// binaryType calcula el tipo de 'left operator right' y reporta las
+// combinaciones que TypeScript rechaza, como 'string < number' o 'true * 2'.
+func (s *Semantic) binaryType(node Node, operator string, left, right *Type) *Type {
+	left, right = widen(apparentType(left)), widen(apparentType(right))
+	switch operator {
+	case "+":
+		if left == stringType || right == stringType {
+			return stringType
+		}
+		if left.isAny() || right.isAny() {
+			return anyType
+		}
+		if result := arithmeticType(left, right); result != nil {
+			return result
+		}
+		s.reportOperands(node, operator, left, right)
+		return anyType
+	case "-", "*", "/", "%", "**", "&", "|", "^", "<<", ">>", ">>>":
+		if result := arithmeticType(left, right); result != nil {
+			return result
+		}
+		s.reportOperands(node, operator, left, right)
+		return numberType
+	case "<", ">", "<=", ">=":
+		if left.isAny() || right.isAny() {
+			return booleanType
+		}
+		if !(left.isNumeric() && right.isNumeric()) && !(left == stringType && right == stringType) {
+			s.addError(codeInvalidOperands, node, "No se pueden comparar con '"+operator+"' valores de tipo '"+
+				left.String()+"' y '"+right.String()+"'")
+		}
+		return booleanType
+	case "==", "!=", "===", "!==":
+		if !comparable(left, right) {
+			s.addError(codeInvalidOperands, node, "La comparación con '"+operator+"' entre '"+left.String()+
+				"' y '"+right.String()+"' no tiene sentido: los tipos no coinciden nunca")
+		}
+		return booleanType
+	default:
+		return anyType
+	}
+}
+
+// arithmeticType devuelve el tipo de una operación aritmética o nil si los
+// operandos no la admiten; 'number' y 'bigint' no se pueden mezclar.
+func arithmeticType(left, right *Type) *Type {
+	switch {
+	case left.isAny() && right.isAny():
+		return numberType
+	case left.isAny() && right.isNumeric():
+		return right
+	case right.isAny() && left.isNumeric():
+		return left
+	case left == right && left.isNumeric():
+		return left
+	default:
+		return nil
+	}
+}
+
+func (s *Semantic) reportOperands(node Node, operator string, left, right *Type) {
+	s.addError(codeInvalidOperands, node, "El operador '"+operator+"' no se puede aplicar a los tipos '"+
+		left.String()+"' y '"+right.String()+"'")
+}
+
+// assignmentType comprueba que el valor asignado sea compatible con el tipo
+// del destino; en las asignaciones compuestas ('+=', '*='...) se valida la
+// operación como si fuera binaria.
+func (s *Semantic) assignmentType(assign *AssignmentExpression) *Type {
+	target := s.typeOf(assign.Target)
+	s.checkWritable(assign.Target, assign)
+	if assign.Value == nil {
+		// 'b *=' sin valor: el parser ya lo reporta
+		return target
+	}
+	value := s.typeOf(assign.Value)
+
+	result := value
+	var ok bool
+	var reason string
+	if assign.Operator == "=" {
+		result = s.displayType(assign.Value)
+		ok, reason = s.valueAssignable(assign.Value, target)
+	} else {
+		operator := assign.Operator[:len(assign.Operator)-1]
+		result = s.binaryType(assign, operator, target, value)
+		ok, reason = assignable(result, target)
+	}
+
+	if !ok {
+		name := exprString(assign.Target)
+		s.addError(codeTypeMismatch, assign.Value, mismatchMessage("No se puede asignar un valor de tipo '"+
+			result.String()+"' a '"+name+"' de tipo '"+target.String()+"'", reason))
+	}
+	return target
+}
@@ -0,0 +1,468 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+type TypeKind string
+
+const (
+	KindAny          TypeKind = "any"
+	KindNumber       TypeKind = "number"
+	KindBigInt       TypeKind = "bigint"
+	KindString       TypeKind = "string"
+	KindBoolean      TypeKind = "boolean"
+	KindVoid         TypeKind = "void"
+	KindNull         TypeKind = "null"
+	KindUndefined    TypeKind = "undefined"
+	KindFunction     TypeKind = "function"
+	KindObject       TypeKind = "object"
+	KindArray        TypeKind = "array"
+	KindUnion        TypeKind = "union"
+	KindIntersection TypeKind = "intersection"
+	KindTypeParam    TypeKind = "type-parameter"
+)
+
+// Type es un tipo del sistema de tipos del analizador. 'any' se usa cuando no
+// se puede inferir nada y es compatible con todo, para no encadenar errores.
+type Type struct {
+	Kind       TypeKind
+	Name       string          // interfaz o alias que lo declara
+	Literal    string          // valor de un tipo literal: '"rojo"', '42', 'true'
+	Element    *Type           // elemento de un array
+	Types      []*Type         // miembros de una unión o intersección
+	Signature  *Signature      // parámetros y retorno, si se conocen
+	Construct  *Signature      // firma de 'new' en el tipo de una clase
+	Properties []*PropertyType // miembros conocidos de un objeto
+	TypeParams []*Type         // parámetros de una interfaz, clase o alias genérico
+	Origin     *Type           // tipo genérico del que este es una instancia
+	Arguments  []*Type         // argumentos de tipo con los que se instanció Origin
+	Constraint *Type           // restricción 'extends' de un parámetro de tipo
+	Default    *Type           // valor por defecto de un parámetro de tipo
+}
+
+// PropertyType es un miembro de un tipo objeto. Los miembros de una clase
+// guardan además su visibilidad y la clase que los declara.
+type PropertyType struct {
+	Name     string
+	Type     *Type
+	Optional bool
+	Readonly bool
+	Access   string // "private", "protected" o "" si es público
+	Class    *Type  // instancia de la clase que lo declara
+}
+
+// Signature describe cómo se llama a una función. Los parámetros a partir de
+// Required son opcionales; Rest, si existe, recoge los argumentos sobrantes.
+// Una función genérica declara sus parámetros de tipo en TypeParams.
+type Signature struct {
+	TypeParams []*Type
+	Params     []SignatureParam
+	Required   int
+	Rest       *SignatureParam
+	Return     *Type
+}
+
+type SignatureParam struct {
+	Name string
+	Type *Type
+}
+
+var (
+	anyType       = &Type{Kind: KindAny}
+	numberType    = &Type{Kind: KindNumber}
+	bigintType    = &Type{Kind: KindBigInt}
+	stringType    = &Type{Kind: KindString}
+	booleanType   = &Type{Kind: KindBoolean}
+	voidType      = &Type{Kind: KindVoid}
+	nullType      = &Type{Kind: KindNull}
+	undefinedType = &Type{Kind: KindUndefined}
+	functionType  = &Type{Kind: KindFunction}
+)
+
+func (t *Type) String() string {
+	switch {
+	case t == nil:
+		return string(KindAny)
+	case t.Name != "":
+		return t.Name
+	case t.Literal != "":
+		return t.Literal
+	case t.Signature != nil:
+		return t.Signature.String()
+	}
+
+	switch t.Kind {
+	case KindArray:
+		element := t.Element.String()
+		if t.Element != nil && t.Element.Name == "" &&
+			(t.Element.Kind == KindUnion || t.Element.Kind == KindIntersection || t.Element.Signature != nil) {
+			element = "(" + element + ")"
+		}
+		return element + "[]"
+	case KindUnion:
+		return joinTypes(t.Types, " | ")
+	case KindIntersection:
+		return joinTypes(t.Types, " & ")
+	case KindObject:
+		if len(t.Properties) == 0 {
+			return "{}"
+		}
+		var sb strings.Builder
+		sb.WriteString("{ ")
+		for i, prop := range t.Properties {
+			if i > 0 {
+				sb.WriteString("; ")
+			}
+			if prop.Readonly {
+				sb.WriteString("readonly ")
+			}
+			sb.WriteString(prop.Name)
+			if prop.Optional {
+				sb.WriteByte('?')
+			}
+			sb.WriteString(": " + prop.Type.String())
+		}
+		sb.WriteString(" }")
+		return sb.String()
+	default:
+		return string(t.Kind)
+	}
+}
+
+func joinTypes(types []*Type, separator string) string {
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = t.String()
+	}
+	return strings.Join(parts, separator)
+}
+
+// String escribe la firma como en TypeScript: '<T>(a: T, b?: string) => void'
+func (sig *Signature) String() string {
+	var sb strings.Builder
+	sb.WriteString(typeParamsString(sig.TypeParams))
+	sb.WriteByte('(')
+	for i, param := range sig.Params {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(param.Name)
+		if i >= sig.Required {
+			sb.WriteByte('?')
+		}
+		sb.WriteString(": " + param.Type.String())
+	}
+	if sig.Rest != nil {
+		if len(sig.Params) > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("..." + sig.Rest.Name + ": " + sig.Rest.Type.String() + "[]")
+	}
+	sb.WriteString(") => " + sig.Return.String())
+	return sb.String()
+}
+
+// typeParamsString escribe '<T, U extends X>' o nada si no hay parámetros
+func typeParamsString(params []*Type) string {
+	if len(params) == 0 {
+		return ""
+	}
+	parts := make([]string, len(params))
+	for i, param := range params {
+		parts[i] = param.Name
+		if param.Constraint != nil {
+			parts[i] += " extends " + param.Constraint.String()
+		}
+	}
+	return "<" + strings.Join(parts, ", ") + ">"
+}
+
+func (t *Type) isAny() bool {
+	return t == nil || t.Kind == KindAny
+}
+
+// apparentType es el tipo con el que se opera sobre un valor: la restricción
+// de un parámetro de tipo en lugar del propio parámetro.
+func apparentType(t *Type) *Type {
+	for t != nil && t.Kind == KindTypeParam && t.Constraint != nil {
+		t = t.Constraint
+	}
+	return t
+}
+
+// isCallable indica si un valor del tipo puede llamarse como función
+func (t *Type) isCallable() bool {
+	return t.isAny() || t.Kind == KindFunction
+}
+
+// typeFromName traduce el nombre de una anotación (o de un tipo estilo C como
+// 'int') al tipo correspondiente.
+func typeFromName(name string) (*Type, bool) {
+	switch name {
+	case "number", "int":
+		return numberType, true
+	case "bigint":
+		return bigintType, true
+	case "string":
+		return stringType, true
+	case "boolean":
+		return booleanType, true
+	case "any", "unknown":
+		return anyType, true
+	case "void":
+		return voidType, true
+	case "null":
+		return nullType, true
+	case "undefined":
+		return undefinedType, true
+	default:
+		return nil, false
+	}
+}
+
+// isNumeric indica si el tipo admite operaciones aritméticas
+func (t *Type) isNumeric() bool {
+	return t == numberType || t == bigintType
+}
+
+// widen convierte un tipo literal en su tipo base ('"a"' pasa a 'string'), y
+// una unión de literales del mismo tipo base también. Los operadores trabajan
+// siempre con tipos ensanchados.
+func widen(t *Type) *Type {
+	if t.isAny() {
+		return t
+	}
+	if t.Literal != "" {
+		base, _ := typeFromName(string(t.Kind))
+		return base
+	}
+	if t.Kind != KindUnion || len(t.Types) == 0 {
+		return t
+	}
+	base := widen(t.Types[0])
+	for _, member := range t.Types[1:] {
+		if widen(member) != base {
+			return t
+		}
+	}
+	return base
+}
+
+// property busca un miembro en un objeto o en cualquier parte de una
+// intersección.
+func (t *Type) property(name string) *PropertyType {
+	switch t.Kind {
+	case KindObject:
+		for _, prop := range t.Properties {
+			if prop.Name == name {
+				return prop
+			}
+		}
+	case KindIntersection:
+		for _, member := range t.Types {
+			if prop := member.property(name); prop != nil {
+				return prop
+			}
+		}
+	}
+	return nil
+}
+
+// setProperty añade el miembro o sustituye al que ya tenga ese nombre
+func (t *Type) setProperty(prop *PropertyType) {
+	for i, existing := range t.Properties {
+		if existing.Name == prop.Name {
+			t.Properties[i] = prop
+			return
+		}
+	}
+	t.Properties = append(t.Properties, prop)
+}
+
+// hasProperties indica si el tipo describe la forma de un objeto
+func (t *Type) hasProperties() bool {
+	if t.isAny() {
+		return false
+	}
+	if t.Kind == KindIntersection {
+		for _, member := range t.Types {
+			if member.hasProperties() {
+				return true
+			}
+		}
+		return false
+	}
+	return t.Kind == KindObject
+}
+
+// isAssignable indica si un valor del tipo source puede guardarse en una
+// variable del tipo target.
+func isAssignable(source, target *Type) bool {
+	ok, _ := assignable(source, target)
+	return ok
+}
+
+// comparable indica si dos valores pueden ser iguales: basta con que uno de
+// los tipos sea asignable al otro.
+func comparable(a, b *Type) bool {
+	return isAssignable(a, b) || isAssignable(b, a)
+}
+
+// assignable compara los tipos estructuralmente, como TypeScript: un objeto
+// encaja si tiene al menos las propiedades obligatorias del destino con tipos
+// compatibles. Si no encaja, el segundo valor explica el motivo cuando hay
+// algo más concreto que decir que los dos tipos.
+func assignable(source, target *Type) (bool, string) {
+	r := &relation{results: make(map[[2]*Type]relationResult)}
+	return r.assignable(source, target)
+}
+
+// relation recuerda las parejas (origen, destino) ya comparadas durante una
+// comprobación. Una pareja que se vuelve a pedir mientras aún se compara se
+// da por buena, como hace TypeScript: así terminan los tipos recursivos como
+// 'interface A { n: A }' frente a 'interface B { n: B }'.
+type relation struct {
+	results map[[2]*Type]relationResult
+}
+
+type relationResult struct {
+	ok     bool
+	reason string
+}
+
+func (r *relation) isAssignable(source, target *Type) bool {
+	ok, _ := r.assignable(source, target)
+	return ok
+}
+
+func (r *relation) assignable(source, target *Type) (bool, string) {
+	if source.isAny() || target.isAny() || source == target {
+		return true, ""
+	}
+	pair := [2]*Type{source, target}
+	if result, ok := r.results[pair]; ok {
+		return result.ok, result.reason
+	}
+	r.results[pair] = relationResult{ok: true}
+	ok, reason := r.compare(source, target)
+	r.results[pair] = relationResult{ok, reason}
+	return ok, reason
+}
+
+func (r *relation) compare(source, target *Type) (bool, string) {
+	switch {
+	case source.Kind == KindUnion:
+		for _, member := range source.Types {
+			if ok, reason := r.assignable(member, target); !ok {
+				return false, reason
+			}
+		}
+		return true, ""
+	case source.Kind == KindTypeParam:
+		// De un parámetro de tipo solo se sabe lo que exige su restricción
+		if target.Kind == KindUnion {
+			for _, member := range target.Types {
+				if member == source {
+					return true, ""
+				}
+			}
+		}
+		if source.Constraint == nil {
+			return false, ""
+		}
+		return r.assignable(source.Constraint, target)
+	case target.Kind == KindUnion:
+		for _, member := range target.Types {
+			if r.isAssignable(source, member) {
+				return true, ""
+			}
+		}
+		return false, ""
+	case target.Kind == KindIntersection:
+		for _, member := range target.Types {
+			if ok, reason := r.assignable(source, member); !ok {
+				return false, reason
+			}
+		}
+		return true, ""
+	case target.Kind == KindObject && source.hasProperties():
+		return r.assignableProperties(source, target)
+	case source.Kind == KindIntersection:
+		for _, member := range source.Types {
+			if r.isAssignable(member, target) {
+				return true, ""
+			}
+		}
+		return false, ""
+	case target.Kind == KindObject && apparentMembers(source) != nil:
+		return r.assignableProperties(apparentMembers(source), target)
+	case source.Kind != target.Kind:
+		return false, ""
+	case target.Literal != "":
+		return source.Literal == target.Literal, ""
+	case target.Kind == KindArray:
+		if ok, _ := r.assignable(source.Element, target.Element); !ok {
+			return false, "los elementos de tipo '" + source.Element.String() +
+				"' no se pueden asignar a '" + target.Element.String() + "'"
+		}
+		return true, ""
+	case source.Signature != nil && target.Signature != nil:
+		return r.assignableSignature(source.Signature, target.Signature)
+	default:
+		return true, ""
+	}
+}
+
+// assignableSignature compara dos funciones: la de origen no puede exigir más
+// argumentos de los que recibe, cada parámetro debe admitir el tipo que se le
+// pasa (o al revés, como hace TypeScript con los métodos) y lo que devuelve
+// debe encajar en el retorno esperado, salvo que este sea void. Las firmas
+// genéricas no se comparan.
+func (r *relation) assignableSignature(source, target *Signature) (bool, string) {
+	if len(source.TypeParams) > 0 || len(target.TypeParams) > 0 {
+		return true, ""
+	}
+	if target.Rest == nil && source.Required > len(target.Params) {
+		return false, "la función necesita " + strconv.Itoa(source.Required) +
+			" argumento(s) y solo recibirá " + strconv.Itoa(len(target.Params))
+	}
+	for i, param := range source.Params {
+		expected := target.Rest
+		if i < len(target.Params) {
+			expected = &target.Params[i]
+		}
+		if expected == nil {
+			break
+		}
+		if !r.isAssignable(expected.Type, param.Type) && !r.isAssignable(param.Type, expected.Type) {
+			return false, "el parámetro '" + param.Name + "' es de tipo '" + param.Type.String() +
+				"' y se esperaba '" + expected.Type.String() + "'"
+		}
+	}
+	if target.Return.isAny() || target.Return.Kind == KindVoid {
+		return true, ""
+	}
+	if !r.isAssignable(source.Return, target.Return) {
+		return false, "devuelve '" + source.Return.String() + "' y se esperaba '" + target.Return.String() + "'"
+	}
+	return true, ""
+}
+
+func (r *relation) assignableProperties(source, target *Type) (bool, string) {
+	for _, expected := range target.Properties {
+		actual := source.property(expected.Name)
+		if actual == nil {
+			if expected.Optional {
+				continue
+			}
+			return false, "falta la propiedad '" + expected.Name + "'"
+		}
+		if !r.isAssignable(actual.Type, expected.Type) {
+			return false, "la propiedad '" + expected.Name + "' es de tipo '" + actual.Type.String() +
+				"' y se esperaba '" + expected.Type.String() + "'"
+		}
+	}
+	return true, ""
+}